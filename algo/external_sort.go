@@ -0,0 +1,242 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"io"
+	"os"
+)
+
+// ExternalSortOption sets an option of ExternalSort.
+type ExternalSortOption func(*externalSorter)
+
+// WithMemoryLimit sets the number of bytes of lines that are sorted in
+// memory at once, which is 64 MiB by default.
+func WithMemoryLimit(bytes int) ExternalSortOption {
+	return func(s *externalSorter) {
+		s.memory = bytes
+	}
+}
+
+// WithTempDir sets the directory of temporary run files, which is
+// os.TempDir by default.
+func WithTempDir(dir string) ExternalSortOption {
+	return func(s *externalSorter) {
+		s.dir = dir
+	}
+}
+
+// WithFanIn sets the maximum number of run files that are merged at
+// once, which is 64 by default and at least 2. More runs are merged
+// in passes.
+func WithFanIn(n int) ExternalSortOption {
+	return func(s *externalSorter) {
+		s.fanIn = n
+	}
+}
+
+// WithLineLess sets the order of lines, which is bytes.Compare by
+// default.
+func WithLineLess(less func(a, b []byte) bool) ExternalSortOption {
+	return func(s *externalSorter) {
+		s.less = less
+	}
+}
+
+type externalSorter struct {
+	memory int
+	dir    string
+	fanIn  int
+	less   func(a, b []byte) bool
+	runs   []string // names of temporary run files
+}
+
+// ExternalSort sorts the lines of r stably into w, where the input can
+// be larger than memory. Lines are sorted in memory in chunks, which
+// are written to temporary run files, and the runs are merged by a
+// k-way merge. Every line of the output ends with a newline.
+func ExternalSort(w io.Writer, r io.Reader, opts ...ExternalSortOption) (err error) {
+	s := &externalSorter{
+		memory: 64 << 20,
+		fanIn:  64,
+		less:   func(a, b []byte) bool { return bytes.Compare(a, b) < 0 },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.memory = max(s.memory, 1)
+	s.fanIn = max(s.fanIn, 2)
+	defer func() {
+		for _, name := range s.runs {
+			os.Remove(name)
+		}
+	}()
+
+	br := bufio.NewReader(r)
+	var (
+		lines [][]byte
+		size  int
+	)
+	for {
+		line, rerr := br.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimSuffix(line, []byte{'\n'})
+			lines = append(lines, line)
+			size += len(line) + 1
+		}
+		if rerr != nil && rerr != io.EOF {
+			return rerr
+		}
+		eof := rerr == io.EOF
+		if size >= s.memory || (eof && len(s.runs) > 0 && len(lines) > 0) {
+			if err := s.writeRun(lines); err != nil {
+				return err
+			}
+			lines, size = nil, 0
+		}
+		if eof {
+			break
+		}
+	}
+	if len(s.runs) == 0 { // fits in memory
+		ParallelMergeSort(lines, s.less)
+		bw := bufio.NewWriter(w)
+		for _, line := range lines {
+			bw.Write(line)
+			bw.WriteByte('\n')
+		}
+		return bw.Flush()
+	}
+
+	// merge runs in passes until they can be merged at once.
+	for len(s.runs) > s.fanIn {
+		var next []string
+		for i := 0; i < len(s.runs); i += s.fanIn {
+			group := s.runs[i:min(i+s.fanIn, len(s.runs))]
+			f, err := os.CreateTemp(s.dir, "extsort-*")
+			if err != nil {
+				return err
+			}
+			next = append(next, f.Name())
+			err = s.merge(f, group)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				s.runs = append(s.runs, next...)
+				return err
+			}
+		}
+		for _, name := range s.runs {
+			os.Remove(name)
+		}
+		s.runs = next
+	}
+	return s.merge(w, s.runs)
+}
+
+// writeRun sorts lines and writes them to a new run file.
+func (s *externalSorter) writeRun(lines [][]byte) error {
+	ParallelMergeSort(lines, s.less)
+	f, err := os.CreateTemp(s.dir, "extsort-*")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, f.Name())
+	bw := bufio.NewWriter(f)
+	for _, line := range lines {
+		bw.Write(line)
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// merge merges sorted run files into w, lines of earlier runs go
+// before equal lines of later runs.
+func (s *externalSorter) merge(w io.Writer, runs []string) error {
+	h := &runHeap{less: s.less}
+	for i, name := range runs {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		rr := &runReader{id: i, r: bufio.NewReader(f)}
+		ok, err := rr.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.runs = append(h.runs, rr)
+		}
+	}
+	heap.Init(h)
+
+	bw := bufio.NewWriter(w)
+	for h.Len() > 0 {
+		rr := h.runs[0]
+		bw.Write(rr.line)
+		bw.WriteByte('\n')
+		ok, err := rr.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return bw.Flush()
+}
+
+// runReader reads lines of a run file.
+type runReader struct {
+	id   int
+	r    *bufio.Reader
+	line []byte
+}
+
+// next reads the next line, and returns false at the end of the run.
+func (rr *runReader) next() (bool, error) {
+	line, err := rr.r.ReadBytes('\n')
+	if err == io.EOF && len(line) == 0 {
+		return false, nil
+	}
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	rr.line = bytes.TrimSuffix(line, []byte{'\n'})
+	return true, nil
+}
+
+// runHeap is a min-heap of runs by their current lines.
+type runHeap struct {
+	runs []*runReader
+	less func(a, b []byte) bool
+}
+
+func (h *runHeap) Len() int { return len(h.runs) }
+func (h *runHeap) Less(i, j int) bool {
+	a, b := h.runs[i], h.runs[j]
+	if h.less(a.line, b.line) {
+		return true
+	}
+	return !h.less(b.line, a.line) && a.id < b.id
+}
+func (h *runHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *runHeap) Push(x any)    { h.runs = append(h.runs, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	x := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return x
+}
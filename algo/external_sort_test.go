@@ -0,0 +1,64 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"changkun.de/x/pkg/algo"
+)
+
+func TestExternalSort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	lines := make([]string, 5000)
+	for i := range lines {
+		lines[i] = fmt.Sprint(r.Intn(1000))
+	}
+	lines[0] = "" // an empty line
+	input := strings.Join(lines, "\n") // without a final newline
+	sort.Strings(lines)
+	want := strings.Join(lines, "\n") + "\n"
+
+	for _, opts := range [][]algo.ExternalSortOption{
+		nil, // in memory
+		{algo.WithMemoryLimit(1000)},
+		{algo.WithMemoryLimit(1000), algo.WithFanIn(3)},
+	} {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		err := algo.ExternalSort(&out, strings.NewReader(input), append(opts, algo.WithTempDir(dir))...)
+		if err != nil {
+			t.Fatalf("external sort failed: %v", err)
+		}
+		if out.String() != want {
+			t.Fatalf("external sort is wrong with %d options", len(opts))
+		}
+		if files, _ := os.ReadDir(dir); len(files) != 0 {
+			t.Fatalf("run files are not removed: %v", files)
+		}
+	}
+}
+
+func TestExternalSortLess(t *testing.T) {
+	// sort by length, which keeps lines of the same length in their
+	// input order.
+	input := "ccc\nb\naa\na\nbb\nc\n"
+	var out bytes.Buffer
+	err := algo.ExternalSort(&out, strings.NewReader(input),
+		algo.WithMemoryLimit(4), algo.WithTempDir(t.TempDir()),
+		algo.WithLineLess(func(a, b []byte) bool { return len(a) < len(b) }))
+	if err != nil {
+		t.Fatalf("external sort failed: %v", err)
+	}
+	if want := "b\na\nc\naa\nbb\nccc\n"; out.String() != want {
+		t.Fatalf("want %q, got %q", want, out.String())
+	}
+}
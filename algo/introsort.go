@@ -0,0 +1,119 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo
+
+import "math/bits"
+
+// IntroSort sorts xs in place by less in O(n log n) time. It is not
+// stable. It runs quicksort with median-of-three pivots, and switches
+// to heapsort if the recursion is too deep, and to insertion sort on
+// short slices.
+// Paper: Musser, David R. (1997). "Introspective Sorting and Selection
+// Algorithms". Software: Practice and Experience 27 (8): 983–993
+func IntroSort[T any](xs []T, less func(a, b T) bool) {
+	introSort(xs, less, 2*bits.Len(uint(len(xs))))
+}
+
+func introSort[T any](xs []T, less func(a, b T) bool, depth int) {
+	for len(xs) > 12 {
+		if depth == 0 {
+			heapSort(xs, less)
+			return
+		}
+		depth--
+		p := hoarePartition(xs, less)
+		// recurse into the shorter side, and loop on the longer one,
+		// which bounds the stack to O(log n).
+		if p < len(xs)-p {
+			introSort(xs[:p], less, depth)
+			xs = xs[p+1:]
+		} else {
+			introSort(xs[p+1:], less, depth)
+			xs = xs[:p]
+		}
+	}
+	insertionSort(xs, less)
+}
+
+// hoarePartition partitions xs around the median of its first, middle
+// and last elements, and returns the final position of the pivot.
+// Elements equal to the pivot are spread on both sides.
+func hoarePartition[T any](xs []T, less func(a, b T) bool) int {
+	n := len(xs)
+	m := medianOfThree(xs, less, 0, n/2, n-1)
+	xs[0], xs[m] = xs[m], xs[0]
+	pivot := xs[0]
+	i, j := 1, n-1
+	for {
+		for i <= j && less(xs[i], pivot) {
+			i++
+		}
+		for i <= j && less(pivot, xs[j]) {
+			j--
+		}
+		if i >= j {
+			break
+		}
+		xs[i], xs[j] = xs[j], xs[i]
+		i++
+		j--
+	}
+	xs[0], xs[j] = xs[j], xs[0]
+	return j
+}
+
+// medianOfThree returns the index of the median of xs[a], xs[b] and
+// xs[c].
+func medianOfThree[T any](xs []T, less func(a, b T) bool, a, b, c int) int {
+	if less(xs[b], xs[a]) {
+		a, b = b, a
+	}
+	if less(xs[c], xs[b]) {
+		b = c
+		if less(xs[b], xs[a]) {
+			b = a
+		}
+	}
+	return b
+}
+
+// insertionSort sorts xs in place stably in O(n^2) time, which is
+// fast for short slices.
+func insertionSort[T any](xs []T, less func(a, b T) bool) {
+	for i := 1; i < len(xs); i++ {
+		for j := i; j > 0 && less(xs[j], xs[j-1]); j-- {
+			xs[j], xs[j-1] = xs[j-1], xs[j]
+		}
+	}
+}
+
+// heapSort sorts xs in place in O(n log n) time.
+func heapSort[T any](xs []T, less func(a, b T) bool) {
+	for i := len(xs)/2 - 1; i >= 0; i-- {
+		siftDown(xs, less, i, len(xs))
+	}
+	for i := len(xs) - 1; i > 0; i-- {
+		xs[0], xs[i] = xs[i], xs[0]
+		siftDown(xs, less, 0, i)
+	}
+}
+
+// siftDown restores the max-heap property of xs[:n] from i.
+func siftDown[T any](xs []T, less func(a, b T) bool, i, n int) {
+	for {
+		c := 2*i + 1
+		if c >= n {
+			return
+		}
+		if c+1 < n && less(xs[c], xs[c+1]) {
+			c++
+		}
+		if !less(xs[i], xs[c]) {
+			return
+		}
+		xs[i], xs[c] = xs[c], xs[i]
+		i = c
+	}
+}
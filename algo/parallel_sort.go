@@ -0,0 +1,108 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo
+
+import (
+	"math/bits"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// parallelThreshold is the length of slices that are not worth to be
+// sorted or merged by another goroutine.
+const parallelThreshold = 1 << 12
+
+// ParallelMergeSort sorts xs stably by less with merge sort, where
+// the halves are sorted and merged concurrently on up to GOMAXPROCS
+// goroutines. It runs in O(n log n) time and uses O(n) extra memory.
+func ParallelMergeSort[T any](xs []T, less func(a, b T) bool) {
+	buf := make([]T, len(xs))
+	depth := bits.Len(uint(runtime.GOMAXPROCS(0)))
+	parallelMergeSort(xs, buf, less, depth)
+}
+
+// parallelMergeSort sorts xs with buf of the same length, it forks
+// goroutines for the next depth levels.
+func parallelMergeSort[T any](xs, buf []T, less func(a, b T) bool, depth int) {
+	if depth == 0 || len(xs) <= parallelThreshold {
+		mergeSort(xs, buf, less)
+		return
+	}
+	mid := len(xs) / 2
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		parallelMergeSort(xs[:mid], buf[:mid], less, depth-1)
+	}()
+	parallelMergeSort(xs[mid:], buf[mid:], less, depth-1)
+	wg.Wait()
+
+	parallelMerge(xs[:mid], xs[mid:], buf, less, depth)
+	copy(xs, buf)
+}
+
+// mergeSort sorts xs stably with buf of the same length.
+func mergeSort[T any](xs, buf []T, less func(a, b T) bool) {
+	if len(xs) <= 20 {
+		insertionSort(xs, less)
+		return
+	}
+	mid := len(xs) / 2
+	mergeSort(xs[:mid], buf[:mid], less)
+	mergeSort(xs[mid:], buf[mid:], less)
+	if !less(xs[mid], xs[mid-1]) {
+		return // already in order
+	}
+	mergeInto(xs[:mid], xs[mid:], buf, less)
+	copy(xs, buf)
+}
+
+// mergeInto merges sorted a and b into out stably, elements of a go
+// before equal elements of b.
+func mergeInto[T any](a, b, out []T, less func(a, b T) bool) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if less(b[j], a[i]) {
+			out[k] = b[j]
+			j++
+		} else {
+			out[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(out[k:], a[i:])
+	copy(out[k:], b[j:])
+}
+
+// parallelMerge merges sorted a and b into out stably. It splits the
+// longer input at its middle, finds the split of the other input by
+// binary search, and merges both parts concurrently.
+func parallelMerge[T any](a, b, out []T, less func(a, b T) bool, depth int) {
+	if depth == 0 || len(a)+len(b) <= parallelThreshold {
+		mergeInto(a, b, out, less)
+		return
+	}
+	var i, j int
+	if len(a) >= len(b) {
+		// elements of b that are equal to a[i] go to the right.
+		i = len(a) / 2
+		j = sort.Search(len(b), func(k int) bool { return !less(b[k], a[i]) })
+	} else {
+		// elements of a that are equal to b[j] go to the left.
+		j = len(b) / 2
+		i = sort.Search(len(a), func(k int) bool { return less(b[j], a[k]) })
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		parallelMerge(a[:i], b[:j], out[:i+j], less, depth-1)
+	}()
+	parallelMerge(a[i:], b[j:], out[i+j:], less, depth-1)
+	wg.Wait()
+}
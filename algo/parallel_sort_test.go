@@ -0,0 +1,27 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"changkun.de/x/pkg/algo"
+)
+
+func TestParallelMergeSortStable(t *testing.T) {
+	type item struct{ key, seq int }
+	xs := make([]item, 200000)
+	for i := range xs {
+		xs[i] = item{rand.Intn(100), i}
+	}
+	algo.ParallelMergeSort(xs, func(a, b item) bool { return a.key < b.key })
+	for i := 1; i < len(xs); i++ {
+		a, b := xs[i-1], xs[i]
+		if a.key > b.key || (a.key == b.key && a.seq > b.seq) {
+			t.Fatalf("not stably sorted at %d: %v, %v", i, a, b)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo
+
+import "math/bits"
+
+// PdqSort sorts xs in place by less with pattern-defeating quicksort.
+// It is not stable. It runs in O(n) time on sorted, reversed and
+// all-equal inputs, and in O(n log n) time in the worst case.
+// Paper: Peters, Orson R. L. (2021). "Pattern-defeating Quicksort".
+// arXiv:2106.05123
+func PdqSort[T any](xs []T, less func(a, b T) bool) {
+	pdqSort(xs, less, 0, len(xs), bits.Len(uint(len(xs))))
+}
+
+type sortedHint int
+
+const (
+	unknownHint sortedHint = iota
+	increasingHint
+	decreasingHint
+)
+
+// pdqSort sorts xs[a:b]. All elements before a are not greater than
+// the elements of xs[a:b], and limit is the number of allowed
+// unbalanced partitions before falling back to heapsort.
+func pdqSort[T any](xs []T, less func(a, b T) bool, a, b, limit int) {
+	const maxInsertion = 12
+
+	wasBalanced, wasPartitioned := true, true
+	for {
+		n := b - a
+		if n <= maxInsertion {
+			insertionSort(xs[a:b], less)
+			return
+		}
+		if limit == 0 {
+			heapSort(xs[a:b], less)
+			return
+		}
+		// an unbalanced partition indicates a bad pattern, shuffle
+		// some elements to break it.
+		if !wasBalanced {
+			breakPatterns(xs, a, b)
+			limit--
+		}
+
+		pivot, hint := choosePivot(xs, less, a, b)
+		if hint == decreasingHint {
+			reverse(xs[a:b])
+			pivot = (b - 1) - (pivot - a)
+			hint = increasingHint
+		}
+		// the slice is likely sorted, try to finish it cheaply.
+		if wasBalanced && wasPartitioned && hint == increasingHint {
+			if partialInsertionSort(xs, less, a, b) {
+				return
+			}
+		}
+		// the pivot equals the predecessor of the slice, which is not
+		// greater than any element, thus all elements equal to the
+		// pivot can be put to the left, and do not need sorting.
+		if a > 0 && !less(xs[a-1], xs[pivot]) {
+			a = partitionEqual(xs, less, a, b, pivot)
+			continue
+		}
+
+		mid, alreadyPartitioned := partition(xs, less, a, b, pivot)
+		wasPartitioned = alreadyPartitioned
+		left, right := mid-a, b-mid
+		if left < right {
+			wasBalanced = left >= n/8
+			pdqSort(xs, less, a, mid, limit)
+			a = mid + 1
+		} else {
+			wasBalanced = right >= n/8
+			pdqSort(xs, less, mid+1, b, limit)
+			b = mid
+		}
+	}
+}
+
+// partition partitions xs[a:b] around xs[pivot], and returns the final
+// position of the pivot, and whether no elements were swapped.
+func partition[T any](xs []T, less func(a, b T) bool, a, b, pivot int) (int, bool) {
+	xs[a], xs[pivot] = xs[pivot], xs[a]
+	i, j := a+1, b-1
+	for i <= j && less(xs[i], xs[a]) {
+		i++
+	}
+	for i <= j && !less(xs[j], xs[a]) {
+		j--
+	}
+	if i > j {
+		xs[j], xs[a] = xs[a], xs[j]
+		return j, true
+	}
+	xs[i], xs[j] = xs[j], xs[i]
+	i++
+	j--
+	for {
+		for i <= j && less(xs[i], xs[a]) {
+			i++
+		}
+		for i <= j && !less(xs[j], xs[a]) {
+			j--
+		}
+		if i > j {
+			break
+		}
+		xs[i], xs[j] = xs[j], xs[i]
+		i++
+		j--
+	}
+	xs[j], xs[a] = xs[a], xs[j]
+	return j, false
+}
+
+// partitionEqual moves the elements of xs[a:b] that are equal to
+// xs[pivot] to the front, assuming no element is less than the pivot,
+// and returns the position after them.
+func partitionEqual[T any](xs []T, less func(a, b T) bool, a, b, pivot int) int {
+	xs[a], xs[pivot] = xs[pivot], xs[a]
+	i, j := a+1, b-1
+	for {
+		for i <= j && !less(xs[a], xs[i]) {
+			i++
+		}
+		for i <= j && less(xs[a], xs[j]) {
+			j--
+		}
+		if i > j {
+			break
+		}
+		xs[i], xs[j] = xs[j], xs[i]
+		i++
+		j--
+	}
+	return i
+}
+
+// partialInsertionSort fixes a few misplaced elements of xs[a:b], and
+// reports whether xs[a:b] is sorted. It gives up after a few steps.
+func partialInsertionSort[T any](xs []T, less func(a, b T) bool, a, b int) bool {
+	const (
+		maxSteps         = 5
+		shortestShifting = 50
+	)
+	i := a + 1
+	for step := 0; step < maxSteps; step++ {
+		for i < b && !less(xs[i], xs[i-1]) {
+			i++
+		}
+		if i == b {
+			return true
+		}
+		if b-a < shortestShifting {
+			return false
+		}
+		xs[i], xs[i-1] = xs[i-1], xs[i]
+		// shift the smaller one to the left, and the greater one to
+		// the right.
+		for j := i - 1; j > a && less(xs[j], xs[j-1]); j-- {
+			xs[j], xs[j-1] = xs[j-1], xs[j]
+		}
+		for j := i + 1; j < b && less(xs[j], xs[j-1]); j++ {
+			xs[j], xs[j-1] = xs[j-1], xs[j]
+		}
+	}
+	return false
+}
+
+// breakPatterns swaps three elements around the middle of xs[a:b]
+// with pseudo-random positions.
+func breakPatterns[T any](xs []T, a, b int) {
+	n := b - a
+	if n < 8 {
+		return
+	}
+	r := uint64(n) // xorshift seeded by the length is deterministic
+	mask := uint64(1)<<bits.Len(uint(n)) - 1
+	idx := a + (n/4)*2 - 1
+	for i := 0; i < 3; i++ {
+		r ^= r << 13
+		r ^= r >> 7
+		r ^= r << 17
+		other := int(r & mask)
+		if other >= n {
+			other -= n
+		}
+		xs[idx-1+i], xs[a+other] = xs[a+other], xs[idx-1+i]
+	}
+}
+
+// choosePivot chooses a pivot of xs[a:b] by the median of three or
+// the ninther on long slices. It also hints the order of the slice by
+// the number of swaps that the medians took.
+func choosePivot[T any](xs []T, less func(a, b T) bool, a, b int) (int, sortedHint) {
+	const (
+		shortestNinther = 50
+		maxSwaps        = 4 * 3
+	)
+	n := b - a
+	swaps := 0
+	i, j, k := a+n/4, a+n/4*2, a+n/4*3
+	if n >= 8 {
+		if n >= shortestNinther {
+			i = medianAdjacent(xs, less, i, &swaps)
+			j = medianAdjacent(xs, less, j, &swaps)
+			k = medianAdjacent(xs, less, k, &swaps)
+		}
+		j = median(xs, less, i, j, k, &swaps)
+	}
+	switch swaps {
+	case 0:
+		return j, increasingHint
+	case maxSwaps:
+		return j, decreasingHint
+	}
+	return j, unknownHint
+}
+
+// median returns the index of the median of xs[a], xs[b] and xs[c],
+// and counts the swaps of sorting the three indices.
+func median[T any](xs []T, less func(a, b T) bool, a, b, c int, swaps *int) int {
+	order := func(a, b int) (int, int) {
+		if less(xs[b], xs[a]) {
+			*swaps++
+			return b, a
+		}
+		return a, b
+	}
+	a, b = order(a, b)
+	b, c = order(b, c)
+	_, b = order(a, b)
+	return b
+}
+
+func medianAdjacent[T any](xs []T, less func(a, b T) bool, a int, swaps *int) int {
+	return median(xs, less, a-1, a, a+1, swaps)
+}
+
+func reverse[T any](xs []T) {
+	for i, j := 0, len(xs)-1; i < j; i, j = i+1, j-1 {
+		xs[i], xs[j] = xs[j], xs[i]
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// Integer is a constraint of integer types.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// RadixSort sorts integers in ascending order by least significant
+// digit radix sort of 8-bit digits. It runs in O(wn) time for w-byte
+// integers, and uses O(n) extra memory. Digits that are the same for
+// all integers are skipped.
+func RadixSort[T Integer](xs []T) {
+	if len(xs) < 2 {
+		return
+	}
+	var zero T
+	width := uint(unsafe.Sizeof(zero)) * 8
+	// flip the sign bit of signed integers, so that negative ones
+	// go before positive ones as unsigned keys.
+	var flip uint64
+	if ^zero < zero {
+		flip = 1 << (width - 1)
+	}
+	key := func(x T) uint64 { return uint64(x) ^ flip }
+
+	buf := make([]T, len(xs))
+	src, dst := xs, buf
+	for shift := uint(0); shift < width; shift += 8 {
+		var count [257]int
+		for _, x := range src {
+			count[(key(x)>>shift)&0xff+1]++
+		}
+		if count[(key(src[0])>>shift)&0xff+1] == len(src) {
+			continue // all the same digit
+		}
+		for i := 1; i < len(count); i++ {
+			count[i] += count[i-1]
+		}
+		for _, x := range src {
+			d := (key(x) >> shift) & 0xff
+			dst[count[d]] = x
+			count[d]++
+		}
+		src, dst = dst, src
+	}
+	if &src[0] != &xs[0] {
+		copy(xs, src)
+	}
+}
+
+// RadixSortStrings sorts strings in lexical order by most significant
+// digit radix sort of bytes. It runs in O(n+D) time where D is the
+// total length of distinguishing prefixes, and uses O(n) extra
+// memory.
+func RadixSortStrings(xs []string) {
+	msdSort(xs, make([]string, len(xs)), 0)
+}
+
+// msdSort sorts xs that share the first d bytes, by the (d+1)-th
+// byte, and then sorts every bucket recursively.
+func msdSort(xs, aux []string, d int) {
+	if len(xs) < 32 {
+		insertionSort(xs, func(a, b string) bool {
+			return strings.Compare(a[d:], b[d:]) < 0
+		})
+		return
+	}
+	// bucket 0 is for strings of length d, and bucket c+1 is for
+	// strings that the (d+1)-th byte is c.
+	var count [258]int
+	for _, s := range xs {
+		count[byteAt(s, d)+1]++
+	}
+	for i := 1; i < len(count); i++ {
+		count[i] += count[i-1]
+	}
+	for _, s := range xs {
+		c := byteAt(s, d)
+		aux[count[c]] = s
+		count[c]++
+	}
+	copy(xs, aux[:len(xs)])
+	// count[c] is the end of bucket c now, strings in bucket 0 are
+	// equal and sorted.
+	for c := 1; c < 257; c++ {
+		if count[c]-count[c-1] > 1 {
+			msdSort(xs[count[c-1]:count[c]], aux, d+1)
+		}
+	}
+}
+
+// byteAt returns the d-th byte of s plus one, or 0 if s is too short.
+func byteAt(s string, d int) int {
+	if d < len(s) {
+		return int(s[d]) + 1
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"changkun.de/x/pkg/algo"
+)
+
+func TestRadixSortWidths(t *testing.T) {
+	i8 := []int8{3, -1, math.MinInt8, math.MaxInt8, 0, -128, 5}
+	algo.RadixSort(i8)
+	if !sort.SliceIsSorted(i8, func(i, j int) bool { return i8[i] < i8[j] }) {
+		t.Fatalf("int8 not sorted: %v", i8)
+	}
+	u64 := []uint64{math.MaxUint64, 0, 1 << 63, 42, 1<<63 - 1}
+	algo.RadixSort(u64)
+	if !sort.SliceIsSorted(u64, func(i, j int) bool { return u64[i] < u64[j] }) {
+		t.Fatalf("uint64 not sorted: %v", u64)
+	}
+	i64 := make([]int64, 1000)
+	for i := range i64 {
+		i64[i] = rand.Int63() - math.MaxInt64/2
+	}
+	i64[0], i64[1] = math.MinInt64, math.MaxInt64
+	algo.RadixSort(i64)
+	if !sort.SliceIsSorted(i64, func(i, j int) bool { return i64[i] < i64[j] }) {
+		t.Fatalf("int64 not sorted")
+	}
+}
+
+func TestRadixSortStrings(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 10, 1000, 20000} {
+		xs := make([]string, n)
+		for i := range xs {
+			// short strings of a small alphabet have long common
+			// prefixes and many duplicates.
+			b := make([]byte, r.Intn(12))
+			for j := range b {
+				b[j] = "ab\x00\xff"[r.Intn(4)]
+			}
+			xs[i] = string(b)
+		}
+		want := append([]string(nil), xs...)
+		sort.Strings(want)
+		algo.RadixSortStrings(xs)
+		if strings.Join(xs, ",") != strings.Join(want, ",") {
+			t.Fatalf("radix sort of %d strings is wrong", n)
+		}
+	}
+}
@@ -23,3 +23,94 @@ func BinarySearch(arr []interface{}, x interface{}, less common.Less) int {
 	}
 	return -1
 }
+
+// Float is a constraint of floating-point types.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is a constraint of integer and floating-point types.
+type Number interface {
+	Integer | Float
+}
+
+// LowerBound returns the index of the first element of sorted xs that
+// is not less than x, or len(xs) if there is no such element.
+func LowerBound[T any](xs []T, x T, less func(a, b T) bool) int {
+	l, r := 0, len(xs)
+	for l < r {
+		mid := int(uint(l+r) >> 1)
+		if less(xs[mid], x) {
+			l = mid + 1
+		} else {
+			r = mid
+		}
+	}
+	return l
+}
+
+// UpperBound returns the index of the first element of sorted xs that
+// is greater than x, or len(xs) if there is no such element.
+func UpperBound[T any](xs []T, x T, less func(a, b T) bool) int {
+	l, r := 0, len(xs)
+	for l < r {
+		mid := int(uint(l+r) >> 1)
+		if less(x, xs[mid]) {
+			r = mid
+		} else {
+			l = mid + 1
+		}
+	}
+	return l
+}
+
+// ExponentialSearch returns the index of the first element of sorted
+// xs that is not less than x, and whether the element equals x. It
+// doubles the search range from the start before binary search, which
+// takes O(log i) time if the result is i, thus it is faster than
+// LowerBound when x is near the start of a long or unbounded slice.
+func ExponentialSearch[T any](xs []T, x T, less func(a, b T) bool) (int, bool) {
+	bound := 1
+	for bound < len(xs) && less(xs[bound-1], x) {
+		bound *= 2
+	}
+	lo, hi := bound/2, min(bound, len(xs))
+	i := lo + LowerBound(xs[lo:hi], x, less)
+	return i, i < len(xs) && !less(x, xs[i])
+}
+
+// InterpolationSearch returns the index of x in sorted xs, and
+// whether x is found. If x is not found, the index is where x would
+// be inserted. It guesses the position of x by linear interpolation
+// between the bounds, which takes O(log log n) time on uniformly
+// distributed elements, and O(n) time in the worst case. NaNs are not
+// supported.
+func InterpolationSearch[T Number](xs []T, x T) (int, bool) {
+	// all elements before lo are less than x, and all elements
+	// after hi are greater than x.
+	lo, hi := 0, len(xs)-1
+	for lo <= hi {
+		if x < xs[lo] {
+			return lo, false
+		}
+		if x > xs[hi] {
+			return hi + 1, false
+		}
+		if xs[lo] == xs[hi] { // then x equals both
+			return lo, true
+		}
+		// convert before subtraction, which may overflow.
+		frac := (float64(x) - float64(xs[lo])) / (float64(xs[hi]) - float64(xs[lo]))
+		pos := lo + int(frac*float64(hi-lo))
+		pos = min(max(pos, lo), hi)
+		switch {
+		case xs[pos] == x:
+			return pos, true
+		case xs[pos] < x:
+			lo = pos + 1
+		default:
+			hi = pos - 1
+		}
+	}
+	return lo, false
+}
@@ -5,7 +5,10 @@
 package algo_test
 
 import (
+	"math"
+	"sort"
 	"testing"
+	"testing/quick"
 
 	"changkun.de/x/pkg/algo"
 	"changkun.de/x/pkg/common"
@@ -60,3 +63,50 @@ func TestBinarySearch(t *testing.T) {
 		}
 	}
 }
+
+func intLess(a, b int) bool { return a < b }
+
+// TestBoundsProperty checks that the bounds split a sorted slice into
+// the elements less than, equal to and greater than x.
+func TestBoundsProperty(t *testing.T) {
+	f := func(xs []int8, x int8) bool {
+		ys := make([]int, len(xs))
+		for i := range xs {
+			ys[i] = int(xs[i] / 8) // make duplicates likely
+		}
+		sort.Ints(ys)
+		v := int(x / 8)
+		lb, ub := algo.LowerBound(ys, v, intLess), algo.UpperBound(ys, v, intLess)
+		for i, y := range ys {
+			if (i < lb) != (y < v) || (i >= ub) != (y > v) {
+				return false
+			}
+		}
+		i, found := algo.ExponentialSearch(ys, v, intLess)
+		j, ok := algo.InterpolationSearch(ys, v)
+		if i != lb || found != (lb < ub) || ok != found {
+			return false
+		}
+		if ok {
+			return ys[j] == v
+		}
+		return j == lb
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInterpolationSearchFloat(t *testing.T) {
+	xs := []float64{-1.5, 0, 0.25, 3, 3, 1e9}
+	if i, ok := algo.InterpolationSearch(xs, 3); !ok || xs[i] != 3 {
+		t.Fatalf("want found 3, got %v, %v", i, ok)
+	}
+	if i, ok := algo.InterpolationSearch(xs, 1); ok || i != 3 {
+		t.Fatalf("want not found at 3, got %v, %v", i, ok)
+	}
+	extreme := []int64{math.MinInt64, 0, math.MaxInt64}
+	if i, ok := algo.InterpolationSearch(extreme, math.MaxInt64); !ok || i != 2 {
+		t.Fatalf("want found at 2, got %v, %v", i, ok)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// QuickSelect returns the k-th smallest element of xs, counting from
+// zero. It rearranges xs so that xs[k] is the element, and no element
+// before k is greater, and no element after k is less. It panics if k
+// is out of range.
+//
+// It partitions around random pivots, which takes O(n) expected time.
+// If it takes too many rounds, it switches to median-of-medians
+// pivots, which bounds the worst case to O(n) time as well.
+func QuickSelect[T any](xs []T, k int, less func(a, b T) bool) T {
+	if k < 0 || k >= len(xs) {
+		panic("algo: select index out of range")
+	}
+	lo, hi := 0, len(xs)
+	for rounds := 2 * bits.Len(uint(len(xs))); hi-lo > 1; rounds-- {
+		if rounds == 0 {
+			selectMoM(xs[lo:hi], k-lo, less)
+			break
+		}
+		pivot := xs[lo+rand.Intn(hi-lo)]
+		lt, gt := partition3(xs[lo:hi], pivot, less)
+		switch {
+		case k < lo+lt:
+			hi = lo + lt
+		case k >= lo+gt:
+			lo += gt
+		default:
+			return xs[k]
+		}
+	}
+	return xs[k]
+}
+
+// MedianOfMedians returns the k-th smallest element of xs, counting
+// from zero, and rearranges xs the same as QuickSelect. It takes O(n)
+// time in the worst case without randomness, but it is slower than
+// QuickSelect on average. It panics if k is out of range.
+// Paper: Blum, M., Floyd, R. W., Pratt, V. R., Rivest, R. L. and
+// Tarjan, R. E. (1973). "Time bounds for selection". Journal of
+// Computer and System Sciences 7 (4): 448–461
+func MedianOfMedians[T any](xs []T, k int, less func(a, b T) bool) T {
+	if k < 0 || k >= len(xs) {
+		panic("algo: select index out of range")
+	}
+	selectMoM(xs, k, less)
+	return xs[k]
+}
+
+func selectMoM[T any](xs []T, k int, less func(a, b T) bool) {
+	for len(xs) > 5 {
+		lt, gt := partition3(xs, pivotMoM(xs, less), less)
+		switch {
+		case k < lt:
+			xs = xs[:lt]
+		case k >= gt:
+			xs, k = xs[gt:], k-gt
+		default:
+			return
+		}
+	}
+	insertionSort(xs, less)
+}
+
+// pivotMoM returns the median of the medians of groups of five
+// elements, which is greater than and less than at least 30% of the
+// elements. It moves the medians to the front of xs.
+func pivotMoM[T any](xs []T, less func(a, b T) bool) T {
+	m := 0
+	for i := 0; i < len(xs); i += 5 {
+		g := xs[i:min(i+5, len(xs))]
+		insertionSort(g, less)
+		xs[m], g[len(g)/2] = g[len(g)/2], xs[m]
+		m++
+	}
+	selectMoM(xs[:m], m/2, less)
+	return xs[m/2]
+}
+
+// partition3 partitions xs into the elements that are less than,
+// equal to, and greater than pivot, and returns the bounds lt and gt
+// of the equal ones.
+func partition3[T any](xs []T, pivot T, less func(a, b T) bool) (lt, gt int) {
+	i, gt := 0, len(xs)
+	for i < gt {
+		switch {
+		case less(xs[i], pivot):
+			xs[lt], xs[i] = xs[i], xs[lt]
+			lt++
+			i++
+		case less(pivot, xs[i]):
+			gt--
+			xs[i], xs[gt] = xs[gt], xs[i]
+		default:
+			i++
+		}
+	}
+	return lt, gt
+}
+
+// TopK returns the k greatest elements of xs in descending order, or
+// all elements if k is not less than len(xs). It keeps a min-heap of
+// the k greatest elements so far, which takes O(n log k) time and
+// O(k) extra memory, and xs is not modified.
+func TopK[T any](xs []T, k int, less func(a, b T) bool) []T {
+	k = min(k, len(xs))
+	if k <= 0 {
+		return nil
+	}
+	// a max-heap by greater is a min-heap by less.
+	greater := func(a, b T) bool { return less(b, a) }
+	h := append(make([]T, 0, k), xs[:k]...)
+	for i := k/2 - 1; i >= 0; i-- {
+		siftDown(h, greater, i, k)
+	}
+	for _, x := range xs[k:] {
+		if less(h[0], x) {
+			h[0] = x
+			siftDown(h, greater, 0, k)
+		}
+	}
+	heapSort(h, greater)
+	return h
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package algo_test
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+	"testing/quick"
+
+	"changkun.de/x/pkg/algo"
+)
+
+// TestSelectProperty checks that the selected element is the k-th
+// element of the sorted slice, and that the slice is partitioned
+// around it.
+func TestSelectProperty(t *testing.T) {
+	selects := map[string]func([]int, int, func(a, b int) bool) int{
+		"quick":             algo.QuickSelect[int],
+		"median of medians": algo.MedianOfMedians[int],
+	}
+	for name, sel := range selects {
+		f := func(xs []int16, k uint) bool {
+			if len(xs) == 0 {
+				return true
+			}
+			ys := make([]int, len(xs))
+			for i := range xs {
+				ys[i] = int(xs[i] % 64)
+			}
+			sorted := append([]int(nil), ys...)
+			sort.Ints(sorted)
+			i := int(k % uint(len(ys)))
+			v := sel(ys, i, intLess)
+			if v != sorted[i] || ys[i] != v {
+				return false
+			}
+			for j := range ys {
+				if (j < i && ys[j] > v) || (j > i && ys[j] < v) {
+					return false
+				}
+			}
+			sort.Ints(ys)
+			return reflect.DeepEqual(ys, sorted)
+		}
+		if err := quick.Check(f, &quick.Config{MaxCount: 2000}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestSelectAdversarial(t *testing.T) {
+	// sorted, reversed and equal inputs of a large size.
+	n := 100000
+	for _, gen := range []func(i int) int{
+		func(i int) int { return i },
+		func(i int) int { return n - i },
+		func(i int) int { return 1 },
+	} {
+		xs := make([]int, n)
+		for i := range xs {
+			xs[i] = gen(i)
+		}
+		want := append([]int(nil), xs...)
+		sort.Ints(want)
+		for _, k := range []int{0, n / 2, n - 1} {
+			if v := algo.MedianOfMedians(append([]int(nil), xs...), k, intLess); v != want[k] {
+				t.Fatalf("median of medians %d-th, want %d, got %d", k, want[k], v)
+			}
+			if v := algo.QuickSelect(append([]int(nil), xs...), k, intLess); v != want[k] {
+				t.Fatalf("quick select %d-th, want %d, got %d", k, want[k], v)
+			}
+		}
+	}
+}
+
+func TestTopKProperty(t *testing.T) {
+	f := func(xs []int, k uint8) bool {
+		input := append([]int(nil), xs...)
+		got := algo.TopK(xs, int(k), intLess)
+		if !slices.Equal(xs, input) {
+			return false // xs is modified
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(input)))
+		return slices.Equal(got, input[:min(int(k), len(input))])
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkSelect(b *testing.B) {
+	input := rand.Perm(1 << 16)
+	xs := make([]int, len(input))
+	for name, sel := range map[string]func([]int, int, func(a, b int) bool) int{
+		"quick":             algo.QuickSelect[int],
+		"median of medians": algo.MedianOfMedians[int],
+	} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(xs, input)
+				sel(xs, len(xs)/2, intLess)
+			}
+		})
+	}
+}
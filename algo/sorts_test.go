@@ -5,7 +5,9 @@
 package algo_test
 
 import (
+	"math/rand"
 	"reflect"
+	"sort"
 	"testing"
 
 	"changkun.de/x/pkg/algo"
@@ -41,3 +43,83 @@ func TestSorts(t *testing.T) {
 	}
 
 }
+
+// patterns returns inputs of different patterns of length n.
+func patterns(n int) map[string][]int {
+	r := rand.New(rand.NewSource(int64(n)))
+	ps := map[string][]int{}
+	random, sorted, reversed, equal, few, sawtooth, organ := make([]int, n), make([]int, n), make([]int, n), make([]int, n), make([]int, n), make([]int, n), make([]int, n)
+	for i := 0; i < n; i++ {
+		random[i] = r.Intn(1 << 30)
+		sorted[i] = i
+		reversed[i] = n - i
+		equal[i] = 7
+		few[i] = r.Intn(4)
+		sawtooth[i] = i % 17
+		if i < n/2 {
+			organ[i] = i
+		} else {
+			organ[i] = n - i
+		}
+	}
+	ps["random"], ps["sorted"], ps["reversed"], ps["equal"] = random, sorted, reversed, equal
+	ps["few"], ps["sawtooth"], ps["organ"] = few, sawtooth, organ
+	// a sorted input with a few swaps
+	nearly := append([]int(nil), sorted...)
+	for i := 0; i < 3 && n > 1; i++ {
+		a, b := r.Intn(n), r.Intn(n)
+		nearly[a], nearly[b] = nearly[b], nearly[a]
+	}
+	ps["nearly"] = nearly
+	return ps
+}
+
+func TestGenericSorts(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	sorts := map[string]func([]int){
+		"intro":    func(xs []int) { algo.IntroSort(xs, less) },
+		"pdq":      func(xs []int) { algo.PdqSort(xs, less) },
+		"radix":    algo.RadixSort[int],
+		"parallel": func(xs []int) { algo.ParallelMergeSort(xs, less) },
+	}
+	for name, sortfn := range sorts {
+		for _, n := range []int{0, 1, 2, 13, 50, 1000, 100000} {
+			for pattern, input := range patterns(n) {
+				got := append([]int(nil), input...)
+				sortfn(got)
+				want := append([]int(nil), input...)
+				sort.Ints(want)
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("%s sort of %d %s integers is wrong", name, n, pattern)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSorts(b *testing.B) {
+	less := func(a, b int) bool { return a < b }
+	sorts := []struct {
+		name string
+		fn   func([]int)
+	}{
+		{"sort.Ints", sort.Ints},
+		{"merge", func(xs []int) { algo.MergeSort(xs) }},
+		{"intro", func(xs []int) { algo.IntroSort(xs, less) }},
+		{"pdq", func(xs []int) { algo.PdqSort(xs, less) }},
+		{"radix", algo.RadixSort[int]},
+		{"parallel", func(xs []int) { algo.ParallelMergeSort(xs, less) }},
+	}
+	for _, pattern := range []string{"random", "sorted", "few"} {
+		input := patterns(1 << 16)[pattern]
+		xs := make([]int, len(input))
+		for _, s := range sorts {
+			b.Run(pattern+"/"+s.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					copy(xs, input)
+					s.fn(xs)
+				}
+			})
+		}
+	}
+}
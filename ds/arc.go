@@ -0,0 +1,157 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// list segments of ARCCache
+const (
+	arcT1 uint8 = iota // recent entries
+	arcT2              // frequent entries
+	arcB1              // ghosts evicted from t1
+	arcB2              // ghosts evicted from t2
+)
+
+// ARCCache implements the adaptive replacement cache. It balances
+// between recency and frequency by tracking recently evicted keys,
+// which makes it resistant to scans that would flush an LRU cache.
+// Paper: Megiddo, Nimrod and Modha, Dharmendra S. (2003). "ARC: A
+// Self-Tuning, Low Overhead Replacement Cache". FAST '03: 115–130
+type ARCCache[K comparable, V any] struct {
+	cap            int
+	p              int // target size of t1
+	items          map[K]*centry[K, V]
+	t1, t2, b1, b2 clist[K, V]
+}
+
+// NewARCCache creates an ARC cache with given capacity.
+func NewARCCache[K comparable, V any](capacity int) *ARCCache[K, V] {
+	return &ARCCache[K, V]{
+		cap:   capacity,
+		items: make(map[K]*centry[K, V], 2*capacity),
+	}
+}
+
+// Get returns the cached value of key and reports whether it was found.
+func (c *ARCCache[K, V]) Get(key K) (v V, ok bool) {
+	e, ok := c.items[key]
+	if !ok || e.seg == arcB1 || e.seg == arcB2 {
+		return v, false
+	}
+	c.move(e, arcT2)
+	return e.v, true
+}
+
+// Put stores the value by given key.
+func (c *ARCCache[K, V]) Put(key K, value V) {
+	if c.cap <= 0 {
+		return
+	}
+	e, ok := c.items[key]
+	if ok {
+		switch e.seg {
+		case arcB1:
+			c.p = minInt(c.cap, c.p+maxInt(c.b2.len/c.b1.len, 1))
+			c.replace(false)
+		case arcB2:
+			c.p = maxInt(0, c.p-maxInt(c.b1.len/c.b2.len, 1))
+			c.replace(true)
+		}
+		e.v = value
+		c.move(e, arcT2)
+		return
+	}
+
+	if c.t1.len+c.b1.len >= c.cap {
+		if c.t1.len < c.cap {
+			c.drop(c.b1.back())
+			c.replace(false)
+		} else {
+			c.drop(c.t1.back())
+		}
+	} else if c.t1.len+c.t2.len+c.b1.len+c.b2.len >= c.cap {
+		if c.t1.len+c.t2.len+c.b1.len+c.b2.len >= 2*c.cap {
+			c.drop(c.b2.back())
+		}
+		c.replace(false)
+	}
+	e = &centry[K, V]{k: key, v: value, seg: arcT1}
+	c.t1.pushFront(e)
+	c.items[key] = e
+}
+
+// Del deletes the stored value by given key.
+func (c *ARCCache[K, V]) Del(key K) {
+	if e, ok := c.items[key]; ok {
+		c.drop(e)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *ARCCache[K, V]) Len() int {
+	return c.t1.len + c.t2.len
+}
+
+// replace evicts an entry from t1 or t2 into its ghost list if the
+// cache is full. inB2 reports whether the current request hit b2.
+func (c *ARCCache[K, V]) replace(inB2 bool) {
+	if c.t1.len+c.t2.len < c.cap {
+		return
+	}
+	if c.t1.len > 0 && (c.t1.len > c.p || (inB2 && c.t1.len == c.p) || c.t2.len == 0) {
+		c.move(c.t1.back(), arcB1)
+	} else {
+		c.move(c.t2.back(), arcB2)
+	}
+}
+
+// move moves e to the front of given segment. Values of entries
+// that become ghosts are released.
+func (c *ARCCache[K, V]) move(e *centry[K, V], seg uint8) {
+	if e.seg == seg {
+		c.list(seg).moveToFront(e)
+		return
+	}
+	c.list(e.seg).remove(e)
+	e.seg = seg
+	if seg == arcB1 || seg == arcB2 {
+		var zero V
+		e.v = zero
+	}
+	c.list(seg).pushFront(e)
+}
+
+func (c *ARCCache[K, V]) drop(e *centry[K, V]) {
+	if e == nil {
+		return
+	}
+	c.list(e.seg).remove(e)
+	delete(c.items, e.k)
+}
+
+func (c *ARCCache[K, V]) list(seg uint8) *clist[K, V] {
+	switch seg {
+	case arcT1:
+		return &c.t1
+	case arcT2:
+		return &c.t2
+	case arcB1:
+		return &c.b1
+	default:
+		return &c.b2
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,219 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+// BloomFilter is a set membership sketch. Contains never reports a
+// false negative, and reports a false positive with a probability
+// that is chosen when the filter is created.
+type BloomFilter struct {
+	bits []uint64
+	m, k uint64
+}
+
+// NewBloomFilter creates a Bloom filter for n elements with a false
+// positive rate p when n elements are added.
+func NewBloomFilter(n int, p float64) *BloomFilter {
+	m, k := bloomParams(n, p)
+	return &BloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// bloomParams returns the optimal number of bits and hash functions
+// of a Bloom filter for n elements with a false positive rate p.
+func bloomParams(n int, p float64) (m, k uint64) {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		panic("ds: false positive rate must be in (0, 1)")
+	}
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return m, k
+}
+
+// bloomLocations calls f with the k locations of data in m slots.
+// The locations are derived from one hash by double hashing.
+// Paper: Kirsch, Adam and Mitzenmacher, Michael (2006). "Less Hashing,
+// Same Performance: Building a Better Bloom Filter". ESA 2006
+func bloomLocations(data []byte, m, k uint64, f func(i uint64) bool) bool {
+	a := sketchHash(data)
+	b := fmix64(a) | 1
+	for i := uint64(0); i < k; i++ {
+		if !f((a + i*b) % m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds data to the filter.
+func (f *BloomFilter) Add(data []byte) {
+	bloomLocations(data, f.m, f.k, func(i uint64) bool {
+		f.bits[i/64] |= 1 << (i % 64)
+		return true
+	})
+}
+
+// Contains reports whether data may have been added to the filter.
+func (f *BloomFilter) Contains(data []byte) bool {
+	return bloomLocations(data, f.m, f.k, func(i uint64) bool {
+		return f.bits[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+// EstimatedLen estimates the number of distinct elements that were
+// added to the filter.
+// Paper: Swamidass, S. Joshua and Baldi, Pierre (2007). "Mathematical
+// correlation of modeled and observed molecular properties".
+// J. Chem. Inf. Model. 47 (3): 952–964
+func (f *BloomFilter) EstimatedLen() int {
+	x := 0
+	for _, w := range f.bits {
+		x += bits.OnesCount64(w)
+	}
+	m, k := float64(f.m), float64(f.k)
+	if x >= int(f.m) {
+		return int(m / k * math.Log(m)) // saturated
+	}
+	return int(math.Round(-m / k * math.Log(1-float64(x)/m)))
+}
+
+// Merge merges o into f, and f becomes the filter of the union of
+// both sets. Both filters must be created with the same parameters.
+func (f *BloomFilter) Merge(o *BloomFilter) error {
+	if f.m != o.m || f.k != o.k {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] |= o.bits[i]
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 2+16+8*len(f.bits))
+	b = appendSketchHeader(b, sketchBloom)
+	b = binary.BigEndian.AppendUint64(b, f.m)
+	b = binary.BigEndian.AppendUint64(b, f.k)
+	for _, w := range f.bits {
+		b = binary.BigEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	r := newSketchReader(data, sketchBloom)
+	m, k := r.uint64(), r.uint64()
+	if r.err == nil && (m == 0 || k == 0 || m > 8*uint64(len(r.b)) || (m+63)/64 != uint64(len(r.b)/8)) {
+		return ErrBadEncoding
+	}
+	ws := make([]uint64, (m+63)/64)
+	for i := range ws {
+		ws[i] = r.uint64()
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	f.bits, f.m, f.k = ws, m, k
+	return nil
+}
+
+// CountingBloomFilter is a Bloom filter that supports removal. Every
+// location holds an 8-bit saturating counter instead of a bit, a
+// counter that has saturated is never decremented.
+type CountingBloomFilter struct {
+	counters []uint8
+	m, k     uint64
+}
+
+// NewCountingBloomFilter creates a counting Bloom filter for n
+// elements with a false positive rate p when n elements are added.
+func NewCountingBloomFilter(n int, p float64) *CountingBloomFilter {
+	m, k := bloomParams(n, p)
+	return &CountingBloomFilter{counters: make([]uint8, m), m: m, k: k}
+}
+
+// Add adds data to the filter.
+func (f *CountingBloomFilter) Add(data []byte) {
+	bloomLocations(data, f.m, f.k, func(i uint64) bool {
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]++
+		}
+		return true
+	})
+}
+
+// Remove removes data that was added to the filter. Removing data
+// that was never added may introduce false negatives.
+func (f *CountingBloomFilter) Remove(data []byte) {
+	if !f.Contains(data) {
+		return
+	}
+	bloomLocations(data, f.m, f.k, func(i uint64) bool {
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]--
+		}
+		return true
+	})
+}
+
+// Contains reports whether data may have been added to the filter.
+func (f *CountingBloomFilter) Contains(data []byte) bool {
+	return bloomLocations(data, f.m, f.k, func(i uint64) bool {
+		return f.counters[i] != 0
+	})
+}
+
+// Merge merges o into f, and f becomes the filter of the multiset
+// union of both filters. Both filters must be created with the same
+// parameters.
+func (f *CountingBloomFilter) Merge(o *CountingBloomFilter) error {
+	if f.m != o.m || f.k != o.k {
+		return ErrIncompatible
+	}
+	for i, c := range o.counters {
+		if s := uint(f.counters[i]) + uint(c); s < math.MaxUint8 {
+			f.counters[i] = uint8(s)
+		} else {
+			f.counters[i] = math.MaxUint8
+		}
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (f *CountingBloomFilter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 2+16+len(f.counters))
+	b = appendSketchHeader(b, sketchCountingBloom)
+	b = binary.BigEndian.AppendUint64(b, f.m)
+	b = binary.BigEndian.AppendUint64(b, f.k)
+	return append(b, f.counters...), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (f *CountingBloomFilter) UnmarshalBinary(data []byte) error {
+	r := newSketchReader(data, sketchCountingBloom)
+	m, k := r.uint64(), r.uint64()
+	if r.err == nil && (m == 0 || k == 0 || m != uint64(len(r.b))) {
+		return ErrBadEncoding
+	}
+	counters := append([]uint8(nil), r.next(int(m))...)
+	if err := r.done(); err != nil {
+		return err
+	}
+	f.counters, f.m, f.k = counters, m, k
+	return nil
+}
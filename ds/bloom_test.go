@@ -0,0 +1,107 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const n, p = 10000, 0.01
+	f := ds.NewBloomFilter(n, p)
+	for i := 0; i < n; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	for i := 0; i < n; i++ {
+		if !f.Contains([]byte(strconv.Itoa(i))) {
+			t.Fatalf("false negative of %v", i)
+		}
+	}
+	fp := 0
+	for i := n; i < 2*n; i++ {
+		if f.Contains([]byte(strconv.Itoa(i))) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 2*p {
+		t.Fatalf("false positive rate is too high: %v", rate)
+	}
+	if l := f.EstimatedLen(); l < n*95/100 || l > n*105/100 {
+		t.Fatalf("estimated length is inaccurate: %v", l)
+	}
+
+	b, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	g := &ds.BloomFilter{}
+	if err := g.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if !g.Contains([]byte("42")) {
+		t.Fatalf("unmarshaled filter lost elements")
+	}
+	if err := g.UnmarshalBinary(b[:len(b)-1]); !errors.Is(err, ds.ErrBadEncoding) {
+		t.Fatalf("unmarshal of truncated data, want %v, got %v", ds.ErrBadEncoding, err)
+	}
+}
+
+func TestBloomFilterMerge(t *testing.T) {
+	a, b := ds.NewBloomFilter(1000, 0.01), ds.NewBloomFilter(1000, 0.01)
+	a.Add([]byte("a"))
+	b.Add([]byte("b"))
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if !a.Contains([]byte("a")) || !a.Contains([]byte("b")) {
+		t.Fatalf("merged filter lost elements")
+	}
+	if err := a.Merge(ds.NewBloomFilter(10, 0.01)); !errors.Is(err, ds.ErrIncompatible) {
+		t.Fatalf("merge of different filters, want %v, got %v", ds.ErrIncompatible, err)
+	}
+}
+
+func TestCountingBloomFilter(t *testing.T) {
+	f := ds.NewCountingBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	for i := 0; i < 500; i++ {
+		f.Remove([]byte(strconv.Itoa(i)))
+	}
+	fp := 0
+	for i := 0; i < 500; i++ {
+		if f.Contains([]byte(strconv.Itoa(i))) {
+			fp++
+		}
+	}
+	if fp > 20 {
+		t.Fatalf("too many removed elements remain: %v", fp)
+	}
+	for i := 500; i < 1000; i++ {
+		if !f.Contains([]byte(strconv.Itoa(i))) {
+			t.Fatalf("false negative of %v", i)
+		}
+	}
+
+	g := ds.NewCountingBloomFilter(1000, 0.01)
+	g.Add([]byte("x"))
+	if err := f.Merge(g); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	b, _ := f.MarshalBinary()
+	h := &ds.CountingBloomFilter{}
+	if err := h.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	h.Remove([]byte("x"))
+	if !h.Contains([]byte("999")) || h.Contains([]byte("x")) {
+		t.Fatalf("unmarshaled filter is inconsistent")
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import "sort"
+
+// BTree is a sorted map on a B-tree. Every node except the root
+// holds between degree-1 and 2*degree-1 entries in a contiguous
+// slice, which makes it more cache friendly than RBTree for small
+// keys. Put, Get and Del are O(log n).
+// Paper: Bayer, R. and McCreight, E. (1972). "Organization and
+// maintenance of large ordered indexes". Acta Informatica 1 (3): 173–189
+type BTree[K any, V any] struct {
+	root   *bnode[K, V]
+	degree int
+	len    int
+	less   func(a, b K) bool
+}
+
+type bnode[K any, V any] struct {
+	items    []bitem[K, V]
+	children []*bnode[K, V] // nil if leaf, otherwise len(items)+1
+}
+
+type bitem[K any, V any] struct {
+	k K
+	v V
+}
+
+// NewBTree creates a B-tree of given minimum degree, which must be
+// at least 2. A degree of 2 makes a 2-3-4 tree, a larger degree makes
+// shallower trees.
+func NewBTree[K any, V any](degree int, less func(a, b K) bool) *BTree[K, V] {
+	if degree < 2 {
+		panic("ds: degree of a B-tree must be at least 2")
+	}
+	return &BTree[K, V]{root: &bnode[K, V]{}, degree: degree, less: less}
+}
+
+// Len returns the number of entries in the tree.
+func (t *BTree[K, V]) Len() int {
+	return t.len
+}
+
+// find returns the position of the first item that is not less than
+// k, and whether the item equals k.
+func (t *BTree[K, V]) find(n *bnode[K, V], k K) (int, bool) {
+	i := sort.Search(len(n.items), func(i int) bool {
+		return !t.less(n.items[i].k, k)
+	})
+	return i, i < len(n.items) && !t.less(k, n.items[i].k)
+}
+
+// Get returns the value of k.
+func (t *BTree[K, V]) Get(k K) (v V, ok bool) {
+	n := t.root
+	for {
+		i, found := t.find(n, k)
+		if found {
+			return n.items[i].v, true
+		}
+		if n.children == nil {
+			return
+		}
+		n = n.children[i]
+	}
+}
+
+// Put stores the value by given key.
+func (t *BTree[K, V]) Put(k K, v V) {
+	if len(t.root.items) == 2*t.degree-1 {
+		old := t.root
+		t.root = &bnode[K, V]{children: []*bnode[K, V]{old}}
+		t.split(t.root, 0)
+	}
+	n := t.root
+	for {
+		i, found := t.find(n, k)
+		if found {
+			n.items[i].v = v
+			return
+		}
+		if n.children == nil {
+			n.items = insertAt(n.items, i, bitem[K, V]{k, v})
+			t.len++
+			return
+		}
+		// split a full child before descending into it, so that
+		// there is always room for the item that a split promotes.
+		if len(n.children[i].items) == 2*t.degree-1 {
+			t.split(n, i)
+			if t.less(n.items[i].k, k) {
+				i++
+			} else if !t.less(k, n.items[i].k) {
+				n.items[i].v = v
+				return
+			}
+		}
+		n = n.children[i]
+	}
+}
+
+// split splits the full i-th child of n, and moves its median item
+// into n.
+func (t *BTree[K, V]) split(n *bnode[K, V], i int) {
+	c, d := n.children[i], t.degree
+	right := &bnode[K, V]{items: append([]bitem[K, V](nil), c.items[d:]...)}
+	if c.children != nil {
+		right.children = append([]*bnode[K, V](nil), c.children[d:]...)
+		clear(c.children[d:])
+		c.children = c.children[:d]
+	}
+	median := c.items[d-1]
+	clear(c.items[d-1:])
+	c.items = c.items[:d-1]
+
+	n.items = insertAt(n.items, i, median)
+	n.children = insertAt(n.children, i+1, right)
+}
+
+// Del deletes the value of k.
+func (t *BTree[K, V]) Del(k K) {
+	if t.del(t.root, k) {
+		t.len--
+	}
+	if len(t.root.items) == 0 && t.root.children != nil {
+		t.root = t.root.children[0]
+	}
+}
+
+// del deletes k from the subtree of n, which has at least degree
+// items unless n is the root.
+func (t *BTree[K, V]) del(n *bnode[K, V], k K) bool {
+	i, found := t.find(n, k)
+	if n.children == nil {
+		if !found {
+			return false
+		}
+		n.items = removeAt(n.items, i)
+		return true
+	}
+
+	d := t.degree
+	if found {
+		switch {
+		case len(n.children[i].items) >= d: // replace by predecessor
+			c := n.children[i]
+			for c.children != nil {
+				c = c.children[len(c.children)-1]
+			}
+			n.items[i] = c.items[len(c.items)-1]
+			return t.del(n.children[i], n.items[i].k)
+		case len(n.children[i+1].items) >= d: // replace by successor
+			c := n.children[i+1]
+			for c.children != nil {
+				c = c.children[0]
+			}
+			n.items[i] = c.items[0]
+			return t.del(n.children[i+1], n.items[i].k)
+		default:
+			t.merge(n, i)
+			return t.del(n.children[i], k)
+		}
+	}
+
+	// make sure the child has at least degree items before descending.
+	if len(n.children[i].items) == d-1 {
+		switch {
+		case i > 0 && len(n.children[i-1].items) >= d:
+			t.rotateRight(n, i-1)
+		case i < len(n.items) && len(n.children[i+1].items) >= d:
+			t.rotateLeft(n, i)
+		case i < len(n.items):
+			t.merge(n, i)
+		default:
+			t.merge(n, i-1)
+			i--
+		}
+	}
+	return t.del(n.children[i], k)
+}
+
+// merge merges the i-th item of n and the (i+1)-th child into the
+// i-th child.
+func (t *BTree[K, V]) merge(n *bnode[K, V], i int) {
+	left, right := n.children[i], n.children[i+1]
+	left.items = append(left.items, n.items[i])
+	left.items = append(left.items, right.items...)
+	left.children = append(left.children, right.children...)
+
+	n.items = removeAt(n.items, i)
+	n.children = removeAt(n.children, i+1)
+}
+
+// rotateRight moves the last item of the i-th child through n into
+// the (i+1)-th child.
+func (t *BTree[K, V]) rotateRight(n *bnode[K, V], i int) {
+	left, right := n.children[i], n.children[i+1]
+	right.items = insertAt(right.items, 0, n.items[i])
+	n.items[i] = left.items[len(left.items)-1]
+	left.items = removeAt(left.items, len(left.items)-1)
+	if left.children != nil {
+		right.children = insertAt(right.children, 0, left.children[len(left.children)-1])
+		left.children = removeAt(left.children, len(left.children)-1)
+	}
+}
+
+// rotateLeft moves the first item of the (i+1)-th child through n
+// into the i-th child.
+func (t *BTree[K, V]) rotateLeft(n *bnode[K, V], i int) {
+	left, right := n.children[i], n.children[i+1]
+	left.items = append(left.items, n.items[i])
+	n.items[i] = right.items[0]
+	right.items = removeAt(right.items, 0)
+	if right.children != nil {
+		left.children = append(left.children, right.children[0])
+		right.children = removeAt(right.children, 0)
+	}
+}
+
+// Min returns the smallest key and its value.
+// It returns false if the tree is empty.
+func (t *BTree[K, V]) Min() (k K, v V, ok bool) {
+	if t.len == 0 {
+		return
+	}
+	n := t.root
+	for n.children != nil {
+		n = n.children[0]
+	}
+	return n.items[0].k, n.items[0].v, true
+}
+
+// Max returns the largest key and its value.
+// It returns false if the tree is empty.
+func (t *BTree[K, V]) Max() (k K, v V, ok bool) {
+	if t.len == 0 {
+		return
+	}
+	n := t.root
+	for n.children != nil {
+		n = n.children[len(n.children)-1]
+	}
+	it := n.items[len(n.items)-1]
+	return it.k, it.v, true
+}
+
+// Range iterates all keys k that from <= k < to in ascending order
+// with op. The iteration stops if op returns false.
+func (t *BTree[K, V]) Range(from, to K, op func(k K, v V) bool) {
+	t.rangeNode(t.root, from, to, op)
+}
+
+func (t *BTree[K, V]) rangeNode(n *bnode[K, V], from, to K, op func(k K, v V) bool) bool {
+	i, _ := t.find(n, from)
+	for ; i <= len(n.items); i++ {
+		if n.children != nil && !t.rangeNode(n.children[i], from, to, op) {
+			return false
+		}
+		if i == len(n.items) {
+			break
+		}
+		if !t.less(n.items[i].k, to) || !op(n.items[i].k, n.items[i].v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Each iterates all entries in ascending order with op.
+// The iteration stops if op returns false.
+func (t *BTree[K, V]) Each(op func(k K, v V) bool) {
+	t.each(t.root, op)
+}
+
+func (t *BTree[K, V]) each(n *bnode[K, V], op func(k K, v V) bool) bool {
+	for i, it := range n.items {
+		if n.children != nil && !t.each(n.children[i], op) {
+			return false
+		}
+		if !op(it.k, it.v) {
+			return false
+		}
+	}
+	return n.children == nil || t.each(n.children[len(n.children)-1], op)
+}
+
+func insertAt[T any](s []T, i int, x T) []T {
+	s = append(s, x)
+	copy(s[i+1:], s[i:])
+	s[i] = x
+	return s
+}
+
+// removeAt removes the i-th element of s, and clears the vacated
+// element for GC.
+func removeAt[T any](s []T, i int) []T {
+	copy(s[i:], s[i+1:])
+	var zero T
+	s[len(s)-1] = zero
+	return s[:len(s)-1]
+}
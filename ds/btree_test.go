@@ -0,0 +1,102 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestBTree(t *testing.T) {
+	for _, degree := range []int{2, 3, 16} {
+		r := rand.New(rand.NewSource(int64(degree)))
+		tree := ds.NewBTree[int, int](degree, intLess)
+		want := map[int]int{}
+		for i := 0; i < 20000; i++ {
+			k := r.Intn(2000)
+			if r.Intn(2) == 0 {
+				tree.Del(k)
+				delete(want, k)
+			} else {
+				tree.Put(k, i)
+				want[k] = i
+			}
+			if tree.Len() != len(want) {
+				t.Fatalf("degree %v: want len %v, got %v", degree, len(want), tree.Len())
+			}
+		}
+		for k := 0; k < 2000; k++ {
+			v, ok := tree.Get(k)
+			if wv, wok := want[k]; ok != wok || v != wv {
+				t.Fatalf("degree %v: get %v, want %v, got %v", degree, k, wv, v)
+			}
+		}
+
+		keys := make([]int, 0, len(want))
+		for k := range want {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		if k, _, _ := tree.Min(); k != keys[0] {
+			t.Fatalf("want min %v, got %v", keys[0], k)
+		}
+		if k, _, _ := tree.Max(); k != keys[len(keys)-1] {
+			t.Fatalf("want max %v, got %v", keys[len(keys)-1], k)
+		}
+		var got []int
+		tree.Each(func(k, v int) bool {
+			got = append(got, k)
+			return true
+		})
+		if len(got) != len(keys) {
+			t.Fatalf("each, want %v keys, got %v", len(keys), len(got))
+		}
+		for i := range keys {
+			if got[i] != keys[i] {
+				t.Fatalf("each is not in order at %v", i)
+			}
+		}
+		got = got[:0]
+		tree.Range(500, 600, func(k, v int) bool {
+			got = append(got, k)
+			return true
+		})
+		lo, hi := sort.SearchInts(keys, 500), sort.SearchInts(keys, 600)
+		if len(got) != hi-lo || (len(got) > 0 && got[0] != keys[lo]) {
+			t.Fatalf("range [500, 600), want %v, got %v", keys[lo:hi], got)
+		}
+	}
+}
+
+func BenchmarkBTree(b *testing.B) {
+	keys := rand.New(rand.NewSource(1)).Perm(100000)
+	b.Run("btree", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			t := ds.NewBTree[int, int](32, intLess)
+			for _, k := range keys {
+				t.Put(k, k)
+			}
+			for _, k := range keys {
+				t.Get(k)
+			}
+		}
+	})
+	b.Run("rbtree", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			t := ds.NewRBTree(func(a, b interface{}) bool { return a.(int) < b.(int) })
+			for _, k := range keys {
+				t.Put(k, k)
+			}
+			for _, k := range keys {
+				t.Get(k)
+			}
+		}
+	})
+}
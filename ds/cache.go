@@ -0,0 +1,149 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// Cache is a fixed capacity key value store with an eviction policy.
+// LRUCache, LFUCache, ARCCache and TinyLFUCache implement Cache, so
+// that callers can swap policies without changing their call sites.
+// The older LRU implements it through its Cache method.
+type Cache[K comparable, V any] interface {
+	// Get returns the cached value of key and reports whether it was found.
+	Get(key K) (V, bool)
+	// Put stores the value by given key, and evicts an entry
+	// according to the policy if the cache is full.
+	Put(key K, value V)
+	// Del deletes the stored value by given key.
+	Del(key K)
+	// Len returns the number of cached entries.
+	Len() int
+}
+
+var (
+	_ Cache[int, int] = (*LRUCache[int, int])(nil)
+	_ Cache[int, int] = (*LFUCache[int, int])(nil)
+	_ Cache[int, int] = (*ARCCache[int, int])(nil)
+	_ Cache[int, int] = (*TinyLFUCache[int, int])(nil)
+	_ Cache[int, int] = lruCache{}
+)
+
+// CacheStats records the lookup statistics of a cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio returns the fraction of lookups that were hits.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// StatsCache wraps a Cache and counts its hits and misses.
+type StatsCache[K comparable, V any] struct {
+	Cache[K, V]
+	stats CacheStats
+}
+
+// NewStatsCache returns a cache that records statistics of c.
+func NewStatsCache[K comparable, V any](c Cache[K, V]) *StatsCache[K, V] {
+	return &StatsCache[K, V]{Cache: c}
+}
+
+// Get returns the cached value of key and records a hit or a miss.
+func (c *StatsCache[K, V]) Get(key K) (V, bool) {
+	v, ok := c.Cache.Get(key)
+	if ok {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	return v, ok
+}
+
+// Stats returns the statistics recorded so far.
+func (c *StatsCache[K, V]) Stats() CacheStats {
+	return c.stats
+}
+
+// ResetStats clears the recorded statistics.
+func (c *StatsCache[K, V]) ResetStats() {
+	c.stats = CacheStats{}
+}
+
+// Replay replays a recorded trace of key accesses against c. Every
+// access is a Get, and a miss is followed by a Put of load(key).
+// It returns the statistics of the replay, which can be used for
+// comparing policies on the same trace.
+func Replay[K comparable, V any](c Cache[K, V], trace []K, load func(K) V) CacheStats {
+	var s CacheStats
+	for _, k := range trace {
+		if _, ok := c.Get(k); ok {
+			s.Hits++
+			continue
+		}
+		s.Misses++
+		c.Put(k, load(k))
+	}
+	return s
+}
+
+// centry is an entry of a cache list.
+type centry[K comparable, V any] struct {
+	prev, next *centry[K, V]
+	k          K
+	v          V
+	freq       int   // access frequency, used by LFUCache
+	seg        uint8 // list that holds the entry, used by ARCCache and TinyLFUCache
+}
+
+// clist is a doubly linked list of cache entries. The front of the
+// list is the most recently used entry.
+type clist[K comparable, V any] struct {
+	root centry[K, V]
+	len  int
+}
+
+func (l *clist[K, V]) lazyInit() {
+	if l.root.next == nil {
+		l.root.next = &l.root
+		l.root.prev = &l.root
+	}
+}
+
+func (l *clist[K, V]) pushFront(e *centry[K, V]) {
+	l.lazyInit()
+	e.prev = &l.root
+	e.next = l.root.next
+	l.root.next.prev = e
+	l.root.next = e
+	l.len++
+}
+
+func (l *clist[K, V]) remove(e *centry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = nil
+	e.next = nil
+	l.len--
+}
+
+func (l *clist[K, V]) moveToFront(e *centry[K, V]) {
+	if l.root.next == e {
+		return
+	}
+	l.remove(e)
+	l.pushFront(e)
+}
+
+// back returns the least recently used entry, or nil if l is empty.
+func (l *clist[K, V]) back() *centry[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
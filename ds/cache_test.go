@@ -0,0 +1,200 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func caches(capacity int) map[string]ds.Cache[int, int] {
+	return map[string]ds.Cache[int, int]{
+		"lru":     ds.NewLRUCache[int, int](capacity),
+		"lfu":     ds.NewLFUCache[int, int](capacity),
+		"arc":     ds.NewARCCache[int, int](capacity),
+		"tinylfu": ds.NewTinyLFUCache[int, int](capacity, func(k int) uint64 { return uint64(k) }),
+	}
+}
+
+func TestCache(t *testing.T) {
+	for name, c := range caches(100) {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := c.Get(1); ok {
+				t.Fatalf("get from empty cache succeeded")
+			}
+			for i := 0; i < 1000; i++ {
+				c.Put(i, i)
+				if v, ok := c.Get(i); ok && v != i {
+					t.Fatalf("want %v, got %v", i, v)
+				}
+				if c.Len() > 100 {
+					t.Fatalf("cache exceeds its capacity: %v", c.Len())
+				}
+			}
+			c.Put(2000, 1)
+			c.Put(2000, 2)
+			if v, ok := c.Get(2000); !ok || v != 2 {
+				t.Fatalf("want 2, got %v, %v", v, ok)
+			}
+			n := c.Len()
+			c.Del(2000)
+			if _, ok := c.Get(2000); ok {
+				t.Fatalf("get deleted key succeeded")
+			}
+			if c.Len() != n-1 {
+				t.Fatalf("want %v, got %v", n-1, c.Len())
+			}
+		})
+	}
+}
+
+func TestCacheZeroCapacity(t *testing.T) {
+	for name, c := range caches(0) {
+		t.Run(name, func(t *testing.T) {
+			c.Put(1, 1)
+			if _, ok := c.Get(1); ok || c.Len() != 0 {
+				t.Fatalf("zero capacity cache stores values")
+			}
+		})
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	c := ds.NewLRUCache[int, int](2)
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Get(1)
+	c.Put(3, 3)
+	if _, ok := c.Get(2); ok {
+		t.Fatalf("want 2 evicted")
+	}
+	if _, ok := c.Get(1); !ok {
+		t.Fatalf("want 1 cached")
+	}
+}
+
+func TestLFUCache(t *testing.T) {
+	c := ds.NewLFUCache[int, int](2)
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	c.Put(3, 3) // evicts 2
+	if _, ok := c.Get(2); ok {
+		t.Fatalf("want 2 evicted")
+	}
+	c.Get(3)
+	c.Get(3)
+	c.Put(4, 4) // evicts 1 by recency, both 1 and 3 were used three times
+	if _, ok := c.Get(1); ok {
+		t.Fatalf("want 1 evicted")
+	}
+	c.Del(3)
+	c.Put(5, 5)
+	c.Get(4)
+	c.Put(6, 6) // evicts 5, the least frequently used one
+	if _, ok := c.Get(5); ok {
+		t.Fatalf("want 5 evicted")
+	}
+	if _, ok := c.Get(4); !ok {
+		t.Fatalf("want 4 cached")
+	}
+}
+
+func TestLFUCacheDel(t *testing.T) {
+	c := ds.NewLFUCache[int, int](3)
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Put(3, 3)
+	c.Get(2)
+	c.Get(3)
+	c.Get(3)
+	c.Del(1) // the least frequency is 2 now
+	c.Put(4, 4)
+	c.Get(4)
+	c.Get(4)
+	c.Get(4)
+	c.Put(5, 5) // evicts 2
+	if _, ok := c.Get(2); ok {
+		t.Fatalf("want 2 evicted")
+	}
+	for _, k := range []int{3, 4, 5} {
+		if _, ok := c.Get(k); !ok {
+			t.Fatalf("want %v cached", k)
+		}
+	}
+}
+
+// scanTrace returns a trace that accesses a small hot set, and
+// interleaves it with long scans of keys that are never reused.
+func scanTrace(hot, scan, rounds int) []int {
+	r := rand.New(rand.NewSource(0))
+	trace := []int{}
+	next := hot
+	for i := 0; i < rounds; i++ {
+		for j := 0; j < 2*hot; j++ {
+			trace = append(trace, r.Intn(hot))
+		}
+		for j := 0; j < scan; j++ {
+			trace = append(trace, next)
+			next++
+		}
+	}
+	return trace
+}
+
+func TestCacheScanResistance(t *testing.T) {
+	trace := scanTrace(50, 200, 100)
+	load := func(k int) int { return k }
+
+	ratios := map[string]float64{}
+	for name, c := range caches(100) {
+		s := ds.NewStatsCache(c)
+		ds.Replay[int, int](s, trace, load)
+		ratios[name] = s.Stats().HitRatio()
+	}
+	for _, name := range []string{"lfu", "arc", "tinylfu"} {
+		if ratios[name] <= ratios["lru"] {
+			t.Fatalf("%v is not scan resistant: %v", name, ratios)
+		}
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	s := ds.NewStatsCache[int, int](ds.NewLRUCache[int, int](1))
+	if s.Stats().HitRatio() != 0 {
+		t.Fatalf("want 0, got %v", s.Stats().HitRatio())
+	}
+	stats := ds.Replay[int, int](s, []int{1, 1, 2, 1}, func(k int) int { return k })
+	if stats.Hits != 1 || stats.Misses != 3 {
+		t.Fatalf("unexpected replay stats: %+v", stats)
+	}
+	if got := s.Stats(); got != stats || got.HitRatio() != 0.25 {
+		t.Fatalf("unexpected cache stats: %+v", got)
+	}
+	s.ResetStats()
+	if s.Stats() != (ds.CacheStats{}) {
+		t.Fatalf("stats are not reset: %+v", s.Stats())
+	}
+}
+
+func BenchmarkCache(b *testing.B) {
+	trace := scanTrace(500, 2000, 10)
+	for _, name := range []string{"lru", "lfu", "arc", "tinylfu"} {
+		b.Run(fmt.Sprintf("%v", name), func(b *testing.B) {
+			c := caches(1000)[name]
+			for i := 0; i < b.N; i++ {
+				k := trace[i%len(trace)]
+				if _, ok := c.Get(k); !ok {
+					c.Put(k, k)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import "net/netip"
+
+// CIDRTree is a routing table of IP prefixes, which finds the longest
+// prefix that contains an address. IPv4 and IPv6 prefixes are kept
+// apart, and an IPv4-mapped IPv6 address only matches IPv6 prefixes.
+//
+// It is a radix tree on the bits of addresses, i.e. a PATRICIA trie,
+// which is the byte-oriented counterpart of RadixTree. All operations
+// are O(w) for addresses of w bits.
+// Paper: Morrison, Donald R. (1968). "PATRICIA—Practical Algorithm To
+// Retrieve Information Coded in Alphanumeric". Journal of the ACM
+// 15 (4): 514–534
+type CIDRTree[V any] struct {
+	v4, v6 *bitnode[V]
+	len    int
+}
+
+// bitnode is a node of a binary radix tree of keys that are the
+// leading bits of byte strings. Keys are masked to their lengths.
+type bitnode[V any] struct {
+	key   []byte
+	bits  int
+	child [2]*bitnode[V]
+	leaf  bool // whether the node stores a value
+	v     V
+}
+
+// bitAt returns the i-th bit of key, from the most significant one.
+func bitAt(key []byte, i int) int {
+	return int(key[i/8]>>(7-i%8)) & 1
+}
+
+// commonBits returns the number of leading bits that a and b share,
+// up to n bits.
+func commonBits(a, b []byte, n int) int {
+	i := 0
+	for ; i+8 <= n && a[i/8] == b[i/8]; i += 8 {
+	}
+	for ; i < n && bitAt(a, i) == bitAt(b, i); i++ {
+	}
+	return i
+}
+
+// maskBits returns a copy of the leading bits of key.
+func maskBits(key []byte, bits int) []byte {
+	m := make([]byte, (bits+7)/8)
+	copy(m, key)
+	if bits%8 != 0 {
+		m[len(m)-1] &= 0xff << (8 - bits%8)
+	}
+	return m
+}
+
+// NewCIDRTree creates an empty CIDR tree.
+func NewCIDRTree[V any]() *CIDRTree[V] {
+	return &CIDRTree[V]{}
+}
+
+// Len returns the number of prefixes in the tree.
+func (t *CIDRTree[V]) Len() int {
+	return t.len
+}
+
+func (t *CIDRTree[V]) root(a netip.Addr) **bitnode[V] {
+	if a.Is4() {
+		return &t.v4
+	}
+	return &t.v6
+}
+
+// Put stores the value by given prefix. The host bits of the prefix
+// are ignored. It panics if the prefix is invalid.
+func (t *CIDRTree[V]) Put(p netip.Prefix, v V) {
+	if !p.IsValid() {
+		panic("ds: invalid prefix")
+	}
+	key, bits := p.Addr().AsSlice(), p.Bits()
+	slot := t.root(p.Addr())
+	for {
+		n := *slot
+		if n == nil {
+			*slot = &bitnode[V]{key: maskBits(key, bits), bits: bits, leaf: true, v: v}
+			t.len++
+			return
+		}
+		c := commonBits(key, n.key, minInt(bits, n.bits))
+		switch {
+		case c == n.bits && c == bits:
+			if !n.leaf {
+				t.len++
+			}
+			n.leaf, n.v = true, v
+			return
+		case c == n.bits: // n is a prefix of p
+			slot = &n.child[bitAt(key, c)]
+			continue
+		case c == bits: // p is a prefix of n
+			nn := &bitnode[V]{key: maskBits(key, bits), bits: bits, leaf: true, v: v}
+			nn.child[bitAt(n.key, c)] = n
+			*slot = nn
+		default: // p and n diverge at bit c
+			mid := &bitnode[V]{key: maskBits(key, c), bits: c}
+			mid.child[bitAt(n.key, c)] = n
+			mid.child[bitAt(key, c)] = &bitnode[V]{key: maskBits(key, bits), bits: bits, leaf: true, v: v}
+			*slot = mid
+		}
+		t.len++
+		return
+	}
+}
+
+// find returns the slots from the root to the node of the prefix,
+// or nil if the prefix is not in the tree.
+func (t *CIDRTree[V]) find(p netip.Prefix) []**bitnode[V] {
+	if !p.IsValid() {
+		return nil
+	}
+	key, bits := p.Addr().AsSlice(), p.Bits()
+	slots := []**bitnode[V]{t.root(p.Addr())}
+	for {
+		n := *slots[len(slots)-1]
+		if n == nil || n.bits > bits || commonBits(key, n.key, n.bits) != n.bits {
+			return nil
+		}
+		if n.bits == bits {
+			if !n.leaf {
+				return nil
+			}
+			return slots
+		}
+		slots = append(slots, &n.child[bitAt(key, n.bits)])
+	}
+}
+
+// Get returns the value of the prefix.
+func (t *CIDRTree[V]) Get(p netip.Prefix) (v V, ok bool) {
+	slots := t.find(p)
+	if slots == nil {
+		return
+	}
+	return (*slots[len(slots)-1]).v, true
+}
+
+// Del deletes the value of the prefix.
+func (t *CIDRTree[V]) Del(p netip.Prefix) {
+	slots := t.find(p)
+	if slots == nil {
+		return
+	}
+	slot := slots[len(slots)-1]
+	n := *slot
+	var zero V
+	n.leaf, n.v = false, zero
+	t.len--
+
+	// remove n if it has no child, and merge its parent if it
+	// becomes a single child node without a value.
+	switch {
+	case n.child[0] != nil && n.child[1] != nil:
+	case n.child[0] != nil:
+		*slot = n.child[0]
+	case n.child[1] != nil:
+		*slot = n.child[1]
+	default:
+		*slot = nil
+		if len(slots) < 2 {
+			return
+		}
+		pslot := slots[len(slots)-2]
+		parent := *pslot
+		if parent.leaf {
+			return
+		}
+		if parent.child[0] != nil {
+			*pslot = parent.child[0]
+		} else {
+			*pslot = parent.child[1]
+		}
+	}
+}
+
+// Lookup returns the longest prefix that contains the address, and
+// its value. It returns false if there is no such prefix.
+func (t *CIDRTree[V]) Lookup(a netip.Addr) (p netip.Prefix, v V, ok bool) {
+	if !a.IsValid() {
+		return
+	}
+	key := a.AsSlice()
+	var best *bitnode[V]
+	for n := *t.root(a); n != nil && commonBits(key, n.key, n.bits) == n.bits; {
+		if n.leaf {
+			best = n
+		}
+		if n.bits == a.BitLen() {
+			break
+		}
+		n = n.child[bitAt(key, n.bits)]
+	}
+	if best == nil {
+		return
+	}
+	return bitnodePrefix(a, best), best.v, true
+}
+
+func bitnodePrefix[V any](a netip.Addr, n *bitnode[V]) netip.Prefix {
+	key := make([]byte, a.BitLen()/8)
+	copy(key, n.key)
+	addr, _ := netip.AddrFromSlice(key)
+	return netip.PrefixFrom(addr, n.bits)
+}
+
+// Walk iterates all prefixes with op, IPv4 prefixes first, and then
+// in the order of addresses and lengths. The iteration stops if op
+// returns false.
+func (t *CIDRTree[V]) Walk(op func(p netip.Prefix, v V) bool) {
+	if t.v4.walk(netip.IPv4Unspecified(), op) {
+		t.v6.walk(netip.IPv6Unspecified(), op)
+	}
+}
+
+func (n *bitnode[V]) walk(a netip.Addr, op func(p netip.Prefix, v V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.leaf && !op(bitnodePrefix(a, n), n.v) {
+		return false
+	}
+	return n.child[0].walk(a, op) && n.child[1].walk(a, op)
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestCIDRTree(t *testing.T) {
+	tree := ds.NewCIDRTree[string]()
+	for _, p := range []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "10.1.2.3/32", "192.168.1.0/24", "::/0", "2001:db8::/32"} {
+		tree.Put(netip.MustParsePrefix(p), p)
+	}
+	tree.Put(netip.MustParsePrefix("10.1.2.99/24"), "10.1.2.0/24") // host bits are ignored
+	if tree.Len() != 8 {
+		t.Fatalf("want len 8, got %v", tree.Len())
+	}
+	tests := map[string]string{
+		"10.1.2.3":        "10.1.2.3/32",
+		"10.1.2.4":        "10.1.2.0/24",
+		"10.1.3.1":        "10.1.0.0/16",
+		"10.2.0.1":        "10.0.0.0/8",
+		"8.8.8.8":         "0.0.0.0/0",
+		"2001:db8::1":     "2001:db8::/32",
+		"::ffff:10.1.2.3": "::/0",
+	}
+	for a, want := range tests {
+		p, v, ok := tree.Lookup(netip.MustParseAddr(a))
+		if !ok || v != want || p.String() != want {
+			t.Fatalf("lookup %v, want %v, got %v %v", a, want, p, v)
+		}
+	}
+
+	tree.Del(netip.MustParsePrefix("10.1.0.0/16"))
+	tree.Del(netip.MustParsePrefix("0.0.0.0/0"))
+	tree.Del(netip.MustParsePrefix("10.1.0.0/16")) // not found
+	if _, _, ok := tree.Lookup(netip.MustParseAddr("8.8.8.8")); ok {
+		t.Fatalf("lookup of a deleted prefix succeeded")
+	}
+	if p, _, _ := tree.Lookup(netip.MustParseAddr("10.1.3.1")); p.String() != "10.0.0.0/8" {
+		t.Fatalf("lookup after delete, got %v", p)
+	}
+	var walked []string
+	tree.Walk(func(p netip.Prefix, v string) bool {
+		walked = append(walked, p.String())
+		return true
+	})
+	want := []string{"10.0.0.0/8", "10.1.2.0/24", "10.1.2.3/32", "192.168.1.0/24", "::/0", "2001:db8::/32"}
+	if len(walked) != len(want) {
+		t.Fatalf("want %v, got %v", want, walked)
+	}
+	for i := range want {
+		if walked[i] != want[i] {
+			t.Fatalf("want %v, got %v", want, walked)
+		}
+	}
+}
+
+func TestCIDRTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := ds.NewCIDRTree[int]()
+	want := map[netip.Prefix]int{}
+	randPrefix := func() netip.Prefix {
+		a := netip.AddrFrom4([4]byte{byte(r.Intn(4)), byte(r.Intn(256)), 0, 0})
+		p, _ := a.Prefix(r.Intn(17))
+		return p
+	}
+	for i := 0; i < 3000; i++ {
+		p := randPrefix()
+		if r.Intn(3) == 0 {
+			tree.Del(p)
+			delete(want, p)
+		} else {
+			tree.Put(p, i)
+			want[p] = i
+		}
+		if tree.Len() != len(want) {
+			t.Fatalf("want len %v, got %v", len(want), tree.Len())
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		a := netip.AddrFrom4([4]byte{byte(r.Intn(4)), byte(r.Intn(256)), 1, 1})
+		var best netip.Prefix
+		for p := range want {
+			if p.Contains(a) && (!best.IsValid() || p.Bits() > best.Bits()) {
+				best = p
+			}
+		}
+		p, v, ok := tree.Lookup(a)
+		if ok != best.IsValid() || (ok && (p != best || v != want[best])) {
+			t.Fatalf("lookup %v, want %v, got %v", a, best, p)
+		}
+	}
+}
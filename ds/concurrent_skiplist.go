@@ -0,0 +1,262 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"changkun.de/x/pkg/common"
+)
+
+const cslMaxLevel = 16
+
+// ConcurrentSkipList is a skiplist that is safe for concurrent use.
+// It implements the lazy skiplist: Get and Range are wait-free and
+// never acquire locks, Set and Del lock only the predecessors of the
+// modified node, then validate and retry if there was a conflict.
+// All operations are linearizable, Range is weakly consistent.
+// Paper: Herlihy, Maurice et al. (2007). "A Simple Optimistic Skiplist
+// Algorithm". SIROCCO 2007, LNCS 4474: 124–138
+type ConcurrentSkipList struct {
+	head *cslnode
+	len  int64 // atomic
+	less common.Less
+}
+
+type cslnode struct {
+	mu          sync.Mutex
+	k           interface{}
+	v           unsafe.Pointer   // *interface{}
+	next        []unsafe.Pointer // []*cslnode
+	level       int              // top level of the node
+	marked      uint32           // atomic, logically deleted
+	fullyLinked uint32           // atomic, linked at all levels
+}
+
+func newCSLNode(k, v interface{}, level int) *cslnode {
+	return &cslnode{
+		k:     k,
+		v:     unsafe.Pointer(&v),
+		next:  make([]unsafe.Pointer, level+1),
+		level: level,
+	}
+}
+
+func (n *cslnode) loadNext(level int) *cslnode {
+	return (*cslnode)(atomic.LoadPointer(&n.next[level]))
+}
+
+func (n *cslnode) storeNext(level int, next *cslnode) {
+	atomic.StorePointer(&n.next[level], unsafe.Pointer(next))
+}
+
+func (n *cslnode) value() interface{} {
+	return *(*interface{})(atomic.LoadPointer(&n.v))
+}
+
+func (n *cslnode) isMarked() bool {
+	return atomic.LoadUint32(&n.marked) == 1
+}
+
+func (n *cslnode) isFullyLinked() bool {
+	return atomic.LoadUint32(&n.fullyLinked) == 1
+}
+
+// NewConcurrentSkipList returns a concurrent skiplist.
+func NewConcurrentSkipList(less common.Less) *ConcurrentSkipList {
+	head := newCSLNode(nil, nil, cslMaxLevel-1)
+	head.fullyLinked = 1
+	return &ConcurrentSkipList{head: head, less: less}
+}
+
+// Len returns the length of given skiplist.
+func (s *ConcurrentSkipList) Len() int {
+	return int(atomic.LoadInt64(&s.len))
+}
+
+// find fills the predecessors and successors of k at every level,
+// and returns the highest level where k was found, or -1.
+func (s *ConcurrentSkipList) find(k interface{}, preds, succs *[cslMaxLevel]*cslnode) int {
+	found := -1
+	pred := s.head
+	for level := cslMaxLevel - 1; level >= 0; level-- {
+		curr := pred.loadNext(level)
+		for curr != nil && s.less(curr.k, k) {
+			pred = curr
+			curr = pred.loadNext(level)
+		}
+		if found == -1 && curr != nil && !s.less(k, curr.k) {
+			found = level
+		}
+		preds[level] = pred
+		succs[level] = curr
+	}
+	return found
+}
+
+func (s *ConcurrentSkipList) randomLevel() (n int) {
+	for n = 0; n < cslMaxLevel-1 && rand.Float64() < 0.25; n++ {
+	}
+	return
+}
+
+// Set sets given k and v pair into the skiplist.
+func (s *ConcurrentSkipList) Set(k interface{}, v interface{}) {
+	var preds, succs [cslMaxLevel]*cslnode
+	top := s.randomLevel()
+	for {
+		if found := s.find(k, &preds, &succs); found != -1 {
+			n := succs[found]
+			for !n.isMarked() && !n.isFullyLinked() {
+				runtime.Gosched()
+			}
+			// Del marks the node under its lock, thus the value is
+			// either stored before the node is deleted, or the node
+			// is being deleted and we retry until it's unlinked.
+			n.mu.Lock()
+			if n.isMarked() {
+				n.mu.Unlock()
+				runtime.Gosched()
+				continue
+			}
+			atomic.StorePointer(&n.v, unsafe.Pointer(&v))
+			n.mu.Unlock()
+			return
+		}
+
+		highest, valid := -1, true
+		for level := 0; valid && level <= top; level++ {
+			pred, succ := preds[level], succs[level]
+			if level == 0 || pred != preds[level-1] {
+				pred.mu.Lock()
+				highest = level
+			}
+			valid = !pred.isMarked() && (succ == nil || !succ.isMarked()) &&
+				pred.loadNext(level) == succ
+		}
+		if !valid {
+			unlockPreds(&preds, highest)
+			continue
+		}
+
+		n := newCSLNode(k, v, top)
+		for level := 0; level <= top; level++ {
+			n.next[level] = unsafe.Pointer(succs[level])
+		}
+		for level := 0; level <= top; level++ {
+			preds[level].storeNext(level, n)
+		}
+		atomic.StoreUint32(&n.fullyLinked, 1)
+		unlockPreds(&preds, highest)
+		atomic.AddInt64(&s.len, 1)
+		return
+	}
+}
+
+// unlockPreds unlocks the distinct predecessors up to given level.
+func unlockPreds(preds *[cslMaxLevel]*cslnode, highest int) {
+	for level := 0; level <= highest; level++ {
+		if level == 0 || preds[level] != preds[level-1] {
+			preds[level].mu.Unlock()
+		}
+	}
+}
+
+// Get returns corresponding v with given k.
+func (s *ConcurrentSkipList) Get(k interface{}) (v interface{}, ok bool) {
+	pred := s.head
+	for level := cslMaxLevel - 1; level >= 0; level-- {
+		curr := pred.loadNext(level)
+		for curr != nil && s.less(curr.k, k) {
+			pred = curr
+			curr = pred.loadNext(level)
+		}
+		if curr != nil && !s.less(k, curr.k) {
+			if !curr.isFullyLinked() || curr.isMarked() {
+				return nil, false
+			}
+			return curr.value(), true
+		}
+	}
+	return nil, false
+}
+
+// Search returns true if k is founded in the skiplist.
+func (s *ConcurrentSkipList) Search(k interface{}) (ok bool) {
+	_, ok = s.Get(k)
+	return
+}
+
+// Range interates `from` to `to` with `op`. It does not block
+// concurrent modifications, and elements that are modified during
+// the iteration may or may not be visited.
+func (s *ConcurrentSkipList) Range(from, to interface{}, op func(v interface{})) {
+	pred := s.head
+	for level := cslMaxLevel - 1; level >= 0; level-- {
+		curr := pred.loadNext(level)
+		for curr != nil && s.less(curr.k, from) {
+			pred = curr
+			curr = pred.loadNext(level)
+		}
+	}
+	for n := pred.loadNext(0); n != nil && s.less(n.k, to); n = n.loadNext(0) {
+		if n.isFullyLinked() && !n.isMarked() {
+			op(n.value())
+		}
+	}
+}
+
+// Del returns the deleted value if ok
+func (s *ConcurrentSkipList) Del(k interface{}) (v interface{}, ok bool) {
+	var preds, succs [cslMaxLevel]*cslnode
+	var victim *cslnode
+	marked := false
+	for {
+		found := s.find(k, &preds, &succs)
+		if !marked {
+			if found == -1 {
+				return nil, false
+			}
+			victim = succs[found]
+			if !victim.isFullyLinked() || victim.level != found || victim.isMarked() {
+				return nil, false
+			}
+			victim.mu.Lock()
+			if victim.isMarked() {
+				victim.mu.Unlock()
+				return nil, false
+			}
+			atomic.StoreUint32(&victim.marked, 1) // linearization point
+			v = victim.value()
+			marked = true
+		}
+
+		highest, valid := -1, true
+		for level := 0; valid && level <= victim.level; level++ {
+			pred := preds[level]
+			if level == 0 || pred != preds[level-1] {
+				pred.mu.Lock()
+				highest = level
+			}
+			valid = !pred.isMarked() && pred.loadNext(level) == victim
+		}
+		if !valid {
+			unlockPreds(&preds, highest)
+			continue
+		}
+
+		for level := victim.level; level >= 0; level-- {
+			preds[level].storeNext(level, victim.loadNext(level))
+		}
+		victim.mu.Unlock()
+		unlockPreds(&preds, highest)
+		atomic.AddInt64(&s.len, -1)
+		return v, true
+	}
+}
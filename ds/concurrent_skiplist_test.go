@@ -0,0 +1,250 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+	"changkun.de/x/pkg/lockfree/lincheck"
+)
+
+func newConcurrentSkipList() *ds.ConcurrentSkipList {
+	return ds.NewConcurrentSkipList(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+}
+
+func TestConcurrentSkipList(t *testing.T) {
+	sl := newConcurrentSkipList()
+	if _, ok := sl.Get(1); ok {
+		t.Fatalf("get from empty skiplist succeeded")
+	}
+	if _, ok := sl.Del(1); ok {
+		t.Fatalf("del from empty skiplist succeeded")
+	}
+	for i := 0; i < 100; i++ {
+		sl.Set(i, i)
+	}
+	sl.Set(1, 3)
+	if v, ok := sl.Get(1); v != 3 || !ok {
+		t.Fatalf("got %v, %v want %v, %v", v, ok, 3, true)
+	}
+	if sl.Len() != 100 {
+		t.Fatalf("Len: got %d, want %d", sl.Len(), 100)
+	}
+	if _, ok := sl.Get(100); ok || sl.Search(-1) {
+		t.Fatalf("get absent key succeeded")
+	}
+	if v, ok := sl.Del(1); v != 3 || !ok {
+		t.Fatalf("got %v, %v want %v, %v", v, ok, 3, true)
+	}
+	if sl.Search(1) || sl.Len() != 99 {
+		t.Fatalf("del failed, len: %v", sl.Len())
+	}
+
+	current := 90
+	sl.Range(90, 120, func(v interface{}) {
+		if v != current {
+			t.Fatalf("range failed, want %v, got %v", current, v)
+		}
+		current++
+	})
+	if current != 100 {
+		t.Fatalf("range out of bound, want %v, got %v", 100, current)
+	}
+}
+
+func TestConcurrentSkipList_Parallel(t *testing.T) {
+	sl := newConcurrentSkipList()
+	n, workers := 1000, runtime.GOMAXPROCS(0)+2
+
+	// every worker sets all keys and deletes its own share, deleted
+	// keys must be deleted exactly once.
+	var deleted int64
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for _, k := range rand.Perm(n) {
+				sl.Set(k, k)
+			}
+			for _, k := range rand.Perm(n) {
+				if k%workers != w {
+					continue
+				}
+				if v, ok := sl.Del(k); ok {
+					if v != k {
+						panic(fmt.Sprintf("del %v returns %v", k, v))
+					}
+					atomic.AddInt64(&deleted, 1)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	remain := 0
+	prev := -1
+	sl.Range(0, n, func(v interface{}) {
+		if v.(int) <= prev {
+			t.Fatalf("range out of order: %v after %v", v, prev)
+		}
+		prev = v.(int)
+		remain++
+	})
+	if remain != sl.Len() || int64(remain)+deleted < int64(n) {
+		t.Fatalf("len %v, remain %v, deleted %v", sl.Len(), remain, deleted)
+	}
+	for k := 0; k < n; k++ {
+		v, ok := sl.Get(k)
+		if ok && v != k {
+			t.Fatalf("want %v, got %v", k, v)
+		}
+	}
+}
+
+// cslOp is an operation on a single key of the skiplist.
+type cslOp struct {
+	op string // "set", "get" or "del"
+	v  int
+}
+
+type cslResult struct {
+	v  interface{}
+	ok bool
+}
+
+// cslModel is a register whose state is the value of the key, or -1
+// if the key is absent.
+var cslModel = lincheck.Model[int, cslOp, cslResult]{
+	Init: func() int { return -1 },
+	Step: func(state int, in cslOp, out cslResult) (bool, int) {
+		switch in.op {
+		case "set":
+			return true, in.v
+		case "get":
+			if state == -1 {
+				return !out.ok, state
+			}
+			return out.ok && out.v == state, state
+		default: // del
+			if state == -1 {
+				return !out.ok, state
+			}
+			return out.ok && out.v == state, -1
+		}
+	},
+}
+
+func TestConcurrentSkipList_Linearizable(t *testing.T) {
+	ops := []string{"set", "set", "get", "del"}
+	for round := 0; round < 50; round++ {
+		sl := newConcurrentSkipList()
+		// neighbouring keys make Set and Del contend on the same
+		// predecessors as well.
+		sl.Set(0, 0)
+		sl.Set(2, 2)
+		var (
+			r  lincheck.Recorder[cslOp, cslResult]
+			wg sync.WaitGroup
+		)
+		for c := 0; c < 4; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				for i := 0; i < 25; i++ {
+					in := cslOp{op: ops[rand.Intn(len(ops))], v: c*100 + i}
+					r.Record(c, in, func() cslResult {
+						switch in.op {
+						case "set":
+							sl.Set(1, in.v)
+							return cslResult{}
+						case "get":
+							v, ok := sl.Get(1)
+							return cslResult{v, ok}
+						default:
+							v, ok := sl.Del(1)
+							return cslResult{v, ok}
+						}
+					})
+					if i%4 == 0 {
+						runtime.Gosched()
+					}
+				}
+			}(c)
+		}
+		wg.Wait()
+		if !lincheck.Check(cslModel, r.Operations()) {
+			t.Fatalf("history is not linearizable: %+v", r.Operations())
+		}
+	}
+}
+
+type mutexSkipList struct {
+	mu sync.Mutex
+	sl *ds.SkipList
+}
+
+func (s *mutexSkipList) Set(k, v interface{}) {
+	s.mu.Lock()
+	s.sl.Set(k, v)
+	s.mu.Unlock()
+}
+
+func (s *mutexSkipList) Get(k interface{}) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sl.Get(k)
+}
+
+func (s *mutexSkipList) Del(k interface{}) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sl.Del(k)
+}
+
+type skiplistInterface interface {
+	Set(k, v interface{})
+	Get(k interface{}) (interface{}, bool)
+	Del(k interface{}) (interface{}, bool)
+}
+
+func BenchmarkConcurrentSkipList(b *testing.B) {
+	const keys = 1 << 12
+	for _, write := range []int{10, 50} {
+		lists := []skiplistInterface{
+			newConcurrentSkipList(),
+			&mutexSkipList{sl: newSkipList()},
+		}
+		for _, sl := range lists {
+			for i := 0; i < keys; i += 2 {
+				sl.Set(i, i)
+			}
+			b.Run(fmt.Sprintf("%T/write-%d%%", sl, write), func(b *testing.B) {
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewSource(rand.Int63()))
+					for pb.Next() {
+						k := r.Intn(keys)
+						switch op := r.Intn(100); {
+						case op < write/2:
+							sl.Set(k, k)
+						case op < write:
+							sl.Del(k)
+						default:
+							sl.Get(k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
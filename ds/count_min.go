@@ -0,0 +1,128 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// CountMinSketch is a frequency sketch. Estimate never underestimates
+// the frequency of an element, and with probability 1-delta it
+// overestimates by at most epsilon times the total count.
+// Paper: Cormode, Graham and Muthukrishnan, S. (2005). "An improved
+// data stream summary: the count-min sketch and its applications".
+// Journal of Algorithms 55 (1): 58–75
+type CountMinSketch struct {
+	rows         [][]uint64
+	width, depth uint64
+	count        uint64
+}
+
+// NewCountMinSketch creates a count-min sketch with error bound
+// epsilon and failure probability delta.
+func NewCountMinSketch(epsilon, delta float64) *CountMinSketch {
+	if epsilon <= 0 || delta <= 0 || delta >= 1 {
+		panic("ds: epsilon must be positive and delta must be in (0, 1)")
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := uint64(math.Ceil(math.Log(1 / delta)))
+	if depth < 1 {
+		depth = 1
+	}
+	return newCountMinSketch(width, depth)
+}
+
+func newCountMinSketch(width, depth uint64) *CountMinSketch {
+	s := &CountMinSketch{rows: make([][]uint64, depth), width: width, depth: depth}
+	for i := range s.rows {
+		s.rows[i] = make([]uint64, width)
+	}
+	return s
+}
+
+// Add adds count occurrences of data to the sketch.
+func (s *CountMinSketch) Add(data []byte, count uint64) {
+	i := 0
+	bloomLocations(data, s.width, s.depth, func(j uint64) bool {
+		s.rows[i][j] += count
+		i++
+		return true
+	})
+	s.count += count
+}
+
+// Estimate estimates the number of occurrences of data.
+func (s *CountMinSketch) Estimate(data []byte) uint64 {
+	min := uint64(math.MaxUint64)
+	i := 0
+	bloomLocations(data, s.width, s.depth, func(j uint64) bool {
+		if v := s.rows[i][j]; v < min {
+			min = v
+		}
+		i++
+		return true
+	})
+	return min
+}
+
+// Count returns the total number of occurrences added to the sketch.
+func (s *CountMinSketch) Count() uint64 {
+	return s.count
+}
+
+// Merge merges o into s, and s becomes the sketch of both streams.
+// Both sketches must be created with the same parameters.
+func (s *CountMinSketch) Merge(o *CountMinSketch) error {
+	if s.width != o.width || s.depth != o.depth {
+		return ErrIncompatible
+	}
+	for i, row := range o.rows {
+		for j, v := range row {
+			s.rows[i][j] += v
+		}
+	}
+	s.count += o.count
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s *CountMinSketch) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 2+24+8*s.width*s.depth)
+	b = appendSketchHeader(b, sketchCountMin)
+	b = binary.BigEndian.AppendUint64(b, s.width)
+	b = binary.BigEndian.AppendUint64(b, s.depth)
+	b = binary.BigEndian.AppendUint64(b, s.count)
+	for _, row := range s.rows {
+		for _, v := range row {
+			b = binary.BigEndian.AppendUint64(b, v)
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *CountMinSketch) UnmarshalBinary(data []byte) error {
+	r := newSketchReader(data, sketchCountMin)
+	width, depth, count := r.uint64(), r.uint64(), r.uint64()
+	if r.err != nil {
+		return r.err
+	}
+	if width == 0 || depth == 0 || width > uint64(len(r.b)) || depth > uint64(len(r.b)) || width*depth != uint64(len(r.b)/8) {
+		return ErrBadEncoding
+	}
+	o := newCountMinSketch(width, depth)
+	o.count = count
+	for _, row := range o.rows {
+		for j := range row {
+			row[j] = r.uint64()
+		}
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	*s = *o
+	return nil
+}
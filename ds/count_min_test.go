@@ -0,0 +1,51 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	const eps = 0.001
+	a, b := ds.NewCountMinSketch(eps, 0.01), ds.NewCountMinSketch(eps, 0.01)
+	r := rand.New(rand.NewSource(1))
+	want := map[int]uint64{}
+	for i := 0; i < 100000; i++ {
+		k := int(r.ExpFloat64() * 100) // a few heavy hitters
+		want[k]++
+		if i%2 == 0 {
+			a.Add([]byte(strconv.Itoa(k)), 1)
+		} else {
+			b.Add([]byte(strconv.Itoa(k)), 1)
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if a.Count() != 100000 {
+		t.Fatalf("want count 100000, got %v", a.Count())
+	}
+
+	data, _ := a.MarshalBinary()
+	s := &ds.CountMinSketch{}
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	for k, n := range want {
+		got := s.Estimate([]byte(strconv.Itoa(k)))
+		if got < n || float64(got-n) > 2*eps*100000 {
+			t.Fatalf("estimate of %v, want %v, got %v", k, n, got)
+		}
+	}
+	if err := a.Merge(ds.NewCountMinSketch(0.1, 0.01)); !errors.Is(err, ds.ErrIncompatible) {
+		t.Fatalf("merge of different sketches, want %v, got %v", ds.ErrIncompatible, err)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// FenwickTree, a.k.a. binary indexed tree, maintains an array and
+// answers the aggregate of its prefixes. It is smaller and faster than
+// SegmentTree, but combine must be commutative as well as associative,
+// and elements can only be accumulated rather than replaced. If
+// combine is invertible, e.g. sum, the aggregate of a range [l, r) is
+// Prefix(r) minus Prefix(l). Add and Prefix are O(log n).
+// Paper: Fenwick, Peter M. (1994). "A New Data Structure for Cumulative
+// Frequency Tables". Software: Practice and Experience 24 (3): 327–336
+type FenwickTree[T any] struct {
+	tree     []T // 1-indexed
+	combine  func(a, b T) T
+	identity T
+}
+
+// NewFenwickTree creates a Fenwick tree of n elements that are
+// all the identity.
+func NewFenwickTree[T any](n int, combine func(a, b T) T, identity T) *FenwickTree[T] {
+	ft := &FenwickTree[T]{tree: make([]T, n+1), combine: combine, identity: identity}
+	for i := range ft.tree {
+		ft.tree[i] = identity
+	}
+	return ft
+}
+
+// Len returns the length of the array.
+func (ft *FenwickTree[T]) Len() int {
+	return len(ft.tree) - 1
+}
+
+// Add combines x into the i-th element.
+func (ft *FenwickTree[T]) Add(i int, x T) {
+	if i < 0 || i >= ft.Len() {
+		panic("ds: index out of range")
+	}
+	for i++; i < len(ft.tree); i += i & -i {
+		ft.tree[i] = ft.combine(ft.tree[i], x)
+	}
+}
+
+// Prefix returns the aggregate of the elements in [0, i).
+func (ft *FenwickTree[T]) Prefix(i int) T {
+	if i < 0 || i > ft.Len() {
+		panic("ds: index out of range")
+	}
+	ret := ft.identity
+	for ; i > 0; i -= i & -i {
+		ret = ft.combine(ret, ft.tree[i])
+	}
+	return ret
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestFenwickTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	xs := make([]int, 100)
+	sum := ds.NewFenwickTree(len(xs), func(a, b int) int { return a + b }, 0)
+	max := ds.NewFenwickTree(len(xs), func(a, b int) int {
+		if a > b {
+			return a
+		}
+		return b
+	}, -1)
+	for j := range xs {
+		max.Add(j, 0)
+	}
+	for i := 0; i < 1000; i++ {
+		j, x := r.Intn(len(xs)), r.Intn(100)
+		xs[j] += x
+		sum.Add(j, x)
+		max.Add(j, xs[j]) // elements of a prefix maximum only increase
+
+		k := r.Intn(len(xs) + 1)
+		wantSum, wantMax := 0, -1
+		for _, x := range xs[:k] {
+			wantSum += x
+			if x > wantMax {
+				wantMax = x
+			}
+		}
+		if got := sum.Prefix(k); got != wantSum {
+			t.Fatalf("prefix sum of %v, want %v, got %v", k, wantSum, got)
+		}
+		if got := max.Prefix(k); got != wantMax {
+			t.Fatalf("prefix max of %v, want %v, got %v", k, wantMax, got)
+		}
+	}
+}
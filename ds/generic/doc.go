@@ -0,0 +1,9 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package generic provides type parameterised versions of the ds
+// containers. They avoid the type assertions and the allocations of
+// boxing values into interface{}, and can be used side by side with
+// the ds package.
+package generic
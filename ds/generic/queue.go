@@ -0,0 +1,38 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic
+
+// Queue is a FIFO queue
+type Queue[T any] struct {
+	v []T
+}
+
+// NewQueue returns a queue
+func NewQueue[T any]() *Queue[T] {
+	return &Queue[T]{v: make([]T, 0)}
+}
+
+// Enqueue enqueues a value to the tail of queue
+func (q *Queue[T]) Enqueue(v T) {
+	q.v = append(q.v, v)
+}
+
+// Dequeue dequeues a value from the head of queue.
+// It returns false if the queue is empty.
+func (q *Queue[T]) Dequeue() (v T, ok bool) {
+	if len(q.v) == 0 {
+		return
+	}
+	var zero T
+	v = q.v[0]
+	q.v[0] = zero
+	q.v = q.v[1:]
+	return v, true
+}
+
+// Len returns the number of values in the queue
+func (q *Queue[T]) Len() int {
+	return len(q.v)
+}
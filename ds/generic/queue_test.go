@@ -0,0 +1,51 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic_test
+
+import (
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+	"changkun.de/x/pkg/ds/generic"
+)
+
+func TestQueue(t *testing.T) {
+	q := generic.NewQueue[string]()
+	if _, ok := q.Dequeue(); ok {
+		t.Fatalf("dequeue empty queue succeeded")
+	}
+	q.Enqueue("a")
+	q.Enqueue("b")
+	if q.Len() != 2 {
+		t.Fatalf("want 2, got %v", q.Len())
+	}
+	for _, want := range []string{"a", "b"} {
+		if v, ok := q.Dequeue(); !ok || v != want {
+			t.Fatalf("want %v, got %v", want, v)
+		}
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Fatalf("dequeue empty queue succeeded")
+	}
+}
+
+func BenchmarkQueue(b *testing.B) {
+	b.Run("ds", func(b *testing.B) {
+		b.ReportAllocs()
+		q := ds.NewQueue()
+		for i := 0; i < b.N; i++ {
+			q.Enqueue(i)
+			_ = q.Dequeue().(int)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		b.ReportAllocs()
+		q := generic.NewQueue[int]()
+		for i := 0; i < b.N; i++ {
+			q.Enqueue(i)
+			q.Dequeue()
+		}
+	})
+}
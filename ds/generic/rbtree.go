@@ -0,0 +1,599 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic
+
+import (
+	"cmp"
+	"fmt"
+)
+
+type color uint32
+
+const (
+	red color = iota
+	black
+)
+
+type rbnode[K any, V any] struct {
+	c      color
+	left   *rbnode[K, V]
+	right  *rbnode[K, V]
+	parent *rbnode[K, V]
+	k      K
+	v      V
+	size   int // number of nodes in the subtree rooted at this node
+}
+
+func (n *rbnode[K, V]) color() color {
+	if n == nil {
+		return black
+	}
+	return n.c
+}
+
+func (n *rbnode[K, V]) grandparent() *rbnode[K, V] {
+	return n.parent.parent
+}
+
+func (n *rbnode[K, V]) uncle() *rbnode[K, V] {
+	if n.parent == n.grandparent().left {
+		return n.grandparent().right
+	}
+	return n.grandparent().left
+}
+
+func (n *rbnode[K, V]) sibling() *rbnode[K, V] {
+	if n == n.parent.left {
+		return n.parent.right
+	}
+	return n.parent.left
+}
+
+func (n *rbnode[K, V]) maximumNode() *rbnode[K, V] {
+	for n.right != nil {
+		n = n.right
+	}
+	return n
+}
+
+func (n *rbnode[K, V]) minimumNode() *rbnode[K, V] {
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func (n *rbnode[K, V]) successor() *rbnode[K, V] {
+	if n.right != nil {
+		return n.right.minimumNode()
+	}
+	p := n.parent
+	for p != nil && n == p.right {
+		n, p = p, p.parent
+	}
+	return p
+}
+
+func (n *rbnode[K, V]) predecessor() *rbnode[K, V] {
+	if n.left != nil {
+		return n.left.maximumNode()
+	}
+	p := n.parent
+	for p != nil && n == p.left {
+		n, p = p, p.parent
+	}
+	return p
+}
+
+func (n *rbnode[K, V]) subtreeSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *rbnode[K, V]) resize() {
+	n.size = n.left.subtreeSize() + n.right.subtreeSize() + 1
+}
+
+// RBTree is a red-black tree. It is a copy of ds.RBTree with type
+// parameters rather than a wrapper of it, which would box every key and
+// value into interface{} again.
+type RBTree[K any, V any] struct {
+	root *rbnode[K, V]
+	len  int
+	less func(a, b K) bool
+}
+
+// NewRBTree creates a red-black tree that orders keys by their natural order
+func NewRBTree[K cmp.Ordered, V any]() *RBTree[K, V] {
+	return &RBTree[K, V]{less: cmp.Less[K]}
+}
+
+// NewRBTreeFunc creates a red-black tree that orders keys by less
+func NewRBTreeFunc[K any, V any](less func(a, b K) bool) *RBTree[K, V] {
+	return &RBTree[K, V]{less: less}
+}
+
+// Len returns the size of the tree
+func (t *RBTree[K, V]) Len() int {
+	return t.len
+}
+
+// Put stores the value by given key
+func (t *RBTree[K, V]) Put(key K, value V) {
+	var parent *rbnode[K, V]
+	var left bool
+
+	for node := t.root; node != nil; {
+		switch {
+		case t.less(key, node.k):
+			parent, left, node = node, true, node.left
+		case t.less(node.k, key):
+			parent, left, node = node, false, node.right
+		default: // =
+			node.k = key
+			node.v = value
+			return
+		}
+	}
+
+	new := &rbnode[K, V]{k: key, v: value, c: red, size: 1, parent: parent}
+	switch {
+	case parent == nil:
+		t.root = new
+	case left:
+		parent.left = new
+	default:
+		parent.right = new
+	}
+	for p := parent; p != nil; p = p.parent {
+		p.size++
+	}
+	t.insertCase1(new)
+	t.len++
+}
+
+func (t *RBTree[K, V]) insertCase1(n *rbnode[K, V]) {
+	if n.parent == nil {
+		n.c = black
+		return
+	}
+	t.insertCase2(n)
+}
+func (t *RBTree[K, V]) insertCase2(n *rbnode[K, V]) {
+	if n.parent.color() == black {
+		return
+	}
+	t.insertCase3(n)
+}
+func (t *RBTree[K, V]) insertCase3(n *rbnode[K, V]) {
+	if n.uncle().color() == red {
+		n.parent.c = black
+		n.uncle().c = black
+		n.grandparent().c = red
+		t.insertCase1(n.grandparent())
+		return
+	}
+	t.insertCase4(n)
+
+}
+func (t *RBTree[K, V]) insertCase4(n *rbnode[K, V]) {
+	if n == n.parent.right && n.parent == n.grandparent().left {
+		t.rotateLeft(n.parent)
+		n = n.left
+	} else if n == n.parent.left && n.parent == n.grandparent().right {
+		t.rotateRight(n.parent)
+		n = n.right
+	}
+	t.insertCase5(n)
+}
+func (t *RBTree[K, V]) insertCase5(n *rbnode[K, V]) {
+	n.parent.c = black
+	n.grandparent().c = red
+	if n == n.parent.left && n.parent == n.grandparent().left {
+		t.rotateRight(n.grandparent())
+		return
+	} else if n == n.parent.right && n.parent == n.grandparent().right {
+		t.rotateLeft(n.grandparent())
+	}
+}
+
+func (t *RBTree[K, V]) replace(old, new *rbnode[K, V]) {
+	if old.parent == nil {
+		t.root = new
+	} else {
+		if old == old.parent.left {
+			old.parent.left = new
+		} else {
+			old.parent.right = new
+		}
+	}
+	if new != nil {
+		new.parent = old.parent
+	}
+}
+
+func (t *RBTree[K, V]) rotateLeft(n *rbnode[K, V]) {
+	right := n.right
+	t.replace(n, right)
+	n.right = right.left
+	if right.left != nil {
+		right.left.parent = n
+	}
+	right.left = n
+	n.parent = right
+	n.resize()
+	right.resize()
+}
+func (t *RBTree[K, V]) rotateRight(n *rbnode[K, V]) {
+	left := n.left
+	t.replace(n, left)
+	n.left = left.right
+	if left.right != nil {
+		left.right.parent = n
+	}
+	left.right = n
+	n.parent = left
+	n.resize()
+	left.resize()
+}
+
+// Get returns the stored value by given key
+func (t *RBTree[K, V]) Get(key K) (v V, ok bool) {
+	n := t.find(key)
+	if n == nil {
+		return
+	}
+	return n.v, true
+}
+
+func (t *RBTree[K, V]) find(key K) *rbnode[K, V] {
+	n := t.root
+	for n != nil {
+		switch {
+		case t.less(key, n.k):
+			n = n.left
+		case t.less(n.k, key):
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// Del deletes the stored value by given key
+func (t *RBTree[K, V]) Del(key K) {
+	var child *rbnode[K, V]
+
+	n := t.find(key)
+	if n == nil {
+		return
+	}
+
+	if n.left != nil && n.right != nil {
+		pred := n.left.maximumNode()
+		n.k = pred.k
+		n.v = pred.v
+		n = pred
+	}
+
+	if n.left == nil || n.right == nil {
+		if n.right == nil {
+			child = n.left
+		} else {
+			child = n.right
+		}
+		if n.c == black {
+			n.c = child.color()
+			t.delCase1(n)
+		}
+
+		t.replace(n, child)
+		if n.parent == nil && child != nil {
+			child.c = black
+		}
+		for p := n.parent; p != nil; p = p.parent {
+			p.resize()
+		}
+	}
+	t.len--
+}
+
+func (t *RBTree[K, V]) delCase1(n *rbnode[K, V]) {
+	if n.parent == nil {
+		return
+	}
+
+	t.delCase2(n)
+}
+func (t *RBTree[K, V]) delCase2(n *rbnode[K, V]) {
+	sibling := n.sibling()
+	if sibling.color() == red {
+		n.parent.c = red
+		sibling.c = black
+		if n == n.parent.left {
+			t.rotateLeft(n.parent)
+		} else {
+			t.rotateRight(n.parent)
+		}
+	}
+	t.delCase3(n)
+}
+func (t *RBTree[K, V]) delCase3(n *rbnode[K, V]) {
+	sibling := n.sibling()
+	if n.parent.color() == black &&
+		sibling.color() == black &&
+		sibling.left.color() == black &&
+		sibling.right.color() == black {
+		sibling.c = red
+		t.delCase1(n.parent)
+		return
+	}
+	t.delCase4(n)
+}
+func (t *RBTree[K, V]) delCase4(n *rbnode[K, V]) {
+	sibling := n.sibling()
+	if n.parent.color() == red &&
+		sibling.color() == black &&
+		sibling.left.color() == black &&
+		sibling.right.color() == black {
+		sibling.c = red
+		n.parent.c = black
+		return
+	}
+	t.delCase5(n)
+}
+func (t *RBTree[K, V]) delCase5(n *rbnode[K, V]) {
+	sibling := n.sibling()
+	if n == n.parent.left &&
+		sibling.color() == black &&
+		sibling.left.color() == red &&
+		sibling.right.color() == black {
+		sibling.c = red
+		sibling.left.c = black
+		t.rotateRight(sibling)
+	} else if n == n.parent.right &&
+		sibling.color() == black &&
+		sibling.right.color() == red &&
+		sibling.left.color() == black {
+		sibling.c = red
+		sibling.right.c = black
+		t.rotateLeft(sibling)
+	}
+	t.delCase6(n)
+}
+func (t *RBTree[K, V]) delCase6(n *rbnode[K, V]) {
+	sibling := n.sibling()
+	sibling.c = n.parent.color()
+	n.parent.c = black
+	if n == n.parent.left && sibling.right.color() == red {
+		sibling.right.c = black
+		t.rotateLeft(n.parent)
+		return
+	}
+	sibling.left.c = black
+	t.rotateRight(n.parent)
+}
+
+// Min returns the smallest key and its value.
+// It returns false if the tree is empty.
+func (t *RBTree[K, V]) Min() (key K, value V, ok bool) {
+	if t.root == nil {
+		return
+	}
+	n := t.root.minimumNode()
+	return n.k, n.v, true
+}
+
+// Max returns the largest key and its value.
+// It returns false if the tree is empty.
+func (t *RBTree[K, V]) Max() (key K, value V, ok bool) {
+	if t.root == nil {
+		return
+	}
+	n := t.root.maximumNode()
+	return n.k, n.v, true
+}
+
+// Floor returns the largest key that is less than or equal to
+// the given key. It returns false if there is no such key.
+func (t *RBTree[K, V]) Floor(key K) (k K, v V, ok bool) {
+	n := t.floor(key)
+	if n == nil {
+		return
+	}
+	return n.k, n.v, true
+}
+
+// Ceiling returns the smallest key that is greater than or equal to
+// the given key. It returns false if there is no such key.
+func (t *RBTree[K, V]) Ceiling(key K) (k K, v V, ok bool) {
+	n := t.ceiling(key)
+	if n == nil {
+		return
+	}
+	return n.k, n.v, true
+}
+
+func (t *RBTree[K, V]) floor(key K) (found *rbnode[K, V]) {
+	n := t.root
+	for n != nil {
+		switch {
+		case t.less(key, n.k):
+			n = n.left
+		case t.less(n.k, key):
+			found = n
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return
+}
+
+func (t *RBTree[K, V]) ceiling(key K) (found *rbnode[K, V]) {
+	n := t.root
+	for n != nil {
+		switch {
+		case t.less(key, n.k):
+			found = n
+			n = n.left
+		case t.less(n.k, key):
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return
+}
+
+// Range iterates all keys k that from <= k < to in ascending order
+// with op. The iteration stops if op returns false.
+func (t *RBTree[K, V]) Range(from, to K, op func(k K, v V) bool) {
+	for n := t.ceiling(from); n != nil && t.less(n.k, to); n = n.successor() {
+		if !op(n.k, n.v) {
+			return
+		}
+	}
+}
+
+// Rank returns the number of keys that are less than the given key.
+func (t *RBTree[K, V]) Rank(key K) int {
+	rank := 0
+	n := t.root
+	for n != nil {
+		switch {
+		case t.less(key, n.k):
+			n = n.left
+		case t.less(n.k, key):
+			rank += n.left.subtreeSize() + 1
+			n = n.right
+		default:
+			return rank + n.left.subtreeSize()
+		}
+	}
+	return rank
+}
+
+// Select returns the i-th smallest key and its value, i starts from 0.
+// It returns false if i is out of range.
+func (t *RBTree[K, V]) Select(i int) (key K, value V, ok bool) {
+	if i < 0 || i >= t.len {
+		return
+	}
+	n := t.root
+	for n != nil {
+		l := n.left.subtreeSize()
+		switch {
+		case i < l:
+			n = n.left
+		case i > l:
+			i -= l + 1
+			n = n.right
+		default:
+			return n.k, n.v, true
+		}
+	}
+	return
+}
+
+// RBTreeIterator iterates over the key value pairs of a RBTree.
+// Modifying the tree invalidates the iterator.
+type RBTreeIterator[K any, V any] struct {
+	next    *rbnode[K, V]
+	cur     *rbnode[K, V]
+	reverse bool
+}
+
+// Iterator returns an iterator that visits keys in ascending order.
+func (t *RBTree[K, V]) Iterator() *RBTreeIterator[K, V] {
+	it := &RBTreeIterator[K, V]{}
+	if t.root != nil {
+		it.next = t.root.minimumNode()
+	}
+	return it
+}
+
+// ReverseIterator returns an iterator that visits keys in descending order.
+func (t *RBTree[K, V]) ReverseIterator() *RBTreeIterator[K, V] {
+	it := &RBTreeIterator[K, V]{reverse: true}
+	if t.root != nil {
+		it.next = t.root.maximumNode()
+	}
+	return it
+}
+
+// Seek returns an ascending iterator that starts from the smallest
+// key that is greater than or equal to the given key.
+func (t *RBTree[K, V]) Seek(key K) *RBTreeIterator[K, V] {
+	return &RBTreeIterator[K, V]{next: t.ceiling(key)}
+}
+
+// Next advances the iterator, it returns false if there are no more elements.
+func (it *RBTreeIterator[K, V]) Next() bool {
+	if it.next == nil {
+		it.cur = nil
+		return false
+	}
+	it.cur = it.next
+	if it.reverse {
+		it.next = it.next.predecessor()
+	} else {
+		it.next = it.next.successor()
+	}
+	return true
+}
+
+// Key returns the key of current element.
+func (it *RBTreeIterator[K, V]) Key() K {
+	return it.cur.k
+}
+
+// Value returns the value of current element.
+func (it *RBTreeIterator[K, V]) Value() V {
+	return it.cur.v
+}
+
+func (t *RBTree[K, V]) String() string {
+	str := "RBTree\n"
+	if t.Len() != 0 {
+		t.root.output("", true, &str)
+	}
+	return str
+}
+
+func (n *rbnode[K, V]) String() string {
+	return fmt.Sprintf("%v", n.k)
+}
+
+func (n *rbnode[K, V]) output(prefix string, isTail bool, str *string) {
+	if n.right != nil {
+		newPrefix := prefix
+		if isTail {
+			newPrefix += "│   "
+		} else {
+			newPrefix += "    "
+		}
+		n.right.output(newPrefix, false, str)
+	}
+	*str += prefix
+	if isTail {
+		*str += "└── "
+	} else {
+		*str += "┌── "
+	}
+	*str += n.String() + "\n"
+	if n.left != nil {
+		newPrefix := prefix
+		if isTail {
+			newPrefix += "    "
+		} else {
+			newPrefix += "│   "
+		}
+		n.left.output(newPrefix, true, str)
+	}
+}
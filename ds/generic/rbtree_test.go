@@ -0,0 +1,110 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic_test
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+	"changkun.de/x/pkg/ds/generic"
+)
+
+func TestRBTree(t *testing.T) {
+	tree := generic.NewRBTree[int, int]()
+	if _, ok := tree.Get(0); ok {
+		t.Fatalf("get from empty tree succeeded")
+	}
+	tree.Del(0)
+
+	keys := rand.Perm(1000)
+	for _, k := range keys {
+		tree.Put(k, k)
+	}
+	tree.Put(1, 2)
+	if v, ok := tree.Get(1); !ok || v != 2 || tree.Len() != 1000 {
+		t.Fatalf("get: got %v, %v, len %v", v, ok, tree.Len())
+	}
+	for _, k := range keys[:500] {
+		tree.Del(k)
+	}
+	remain := append([]int(nil), keys[500:]...)
+	sort.Ints(remain)
+	if tree.Len() != len(remain) {
+		t.Fatalf("want %v, got %v", len(remain), tree.Len())
+	}
+
+	it := tree.Iterator()
+	for i, k := range remain {
+		if !it.Next() || it.Key() != k {
+			t.Fatalf("iterator: want %v, got %v", k, it.Key())
+		}
+		if r := tree.Rank(k); r != i {
+			t.Fatalf("rank of %v: want %v, got %v", k, i, r)
+		}
+		if s, _, _ := tree.Select(i); s != k {
+			t.Fatalf("select %v: want %v, got %v", i, k, s)
+		}
+	}
+	if it.Next() {
+		t.Fatalf("iterator: want end, got %v", it.Key())
+	}
+	if k, _, ok := tree.Min(); !ok || k != remain[0] {
+		t.Fatalf("min: want %v, got %v", remain[0], k)
+	}
+	if k, _, ok := tree.Max(); !ok || k != remain[len(remain)-1] {
+		t.Fatalf("max: want %v, got %v", remain[len(remain)-1], k)
+	}
+}
+
+func TestRBTreeFunc(t *testing.T) {
+	tree := generic.NewRBTreeFunc[string, int](func(a, b string) bool {
+		return strings.ToLower(a) < strings.ToLower(b)
+	})
+	tree.Put("b", 1)
+	tree.Put("A", 2)
+	tree.Put("a", 3)
+	tree.Put("C", 4)
+	if tree.Len() != 3 {
+		t.Fatalf("want 3, got %v", tree.Len())
+	}
+	got := []string{}
+	tree.Range("a", "c", func(k string, v int) bool {
+		got = append(got, k)
+		return true
+	})
+	if strings.Join(got, "") != "ab" {
+		t.Fatalf("range: got %v", got)
+	}
+	if k, v, ok := tree.Floor("bb"); !ok || k != "b" || v != 1 {
+		t.Fatalf("floor: got %v, %v, %v", k, v, ok)
+	}
+	if k, _, ok := tree.Ceiling("bb"); !ok || k != "C" {
+		t.Fatalf("ceiling: got %v, %v", k, ok)
+	}
+}
+
+func BenchmarkRBTree(b *testing.B) {
+	b.Run("ds", func(b *testing.B) {
+		b.ReportAllocs()
+		tree := ds.NewRBTree(func(a, b interface{}) bool {
+			return a.(int) < b.(int)
+		})
+		for i := 0; i < b.N; i++ {
+			tree.Put(i%1024, i)
+			_ = tree.Get(i % 1024).(int)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		b.ReportAllocs()
+		tree := generic.NewRBTree[int, int]()
+		for i := 0; i < b.N; i++ {
+			tree.Put(i%1024, i)
+			tree.Get(i % 1024)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic
+
+// RingBuffer implements ring buffer queue
+type RingBuffer[T any] struct {
+	buf        []T
+	head, tail int
+	len        int
+}
+
+// NewRingBuffer creates a ring buffer with given capacity
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	return &RingBuffer[T]{buf: make([]T, capacity)}
+}
+
+// Put puts x into ring buffer, it returns false if the buffer is full.
+func (rb *RingBuffer[T]) Put(x T) (ok bool) {
+	if rb.len == len(rb.buf) {
+		return
+	}
+	rb.buf[rb.tail] = x
+	rb.tail = (rb.tail + 1) % len(rb.buf)
+	rb.len++
+	return true
+}
+
+// Get gets the first element from queue, it returns false if the
+// buffer is empty.
+func (rb *RingBuffer[T]) Get() (x T, ok bool) {
+	if rb.len == 0 {
+		return
+	}
+	var zero T
+	x = rb.buf[rb.head]
+	rb.buf[rb.head] = zero
+	rb.head = (rb.head + 1) % len(rb.buf)
+	rb.len--
+	return x, true
+}
+
+// Len returns the number of elements in the ring buffer
+func (rb *RingBuffer[T]) Len() int {
+	return rb.len
+}
+
+// IsFull checks if the ring buffer is full
+func (rb *RingBuffer[T]) IsFull() bool {
+	return rb.len == len(rb.buf)
+}
+
+// LookAll reads all elements from ring buffer
+// this method doesn't consume all elements
+func (rb *RingBuffer[T]) LookAll() []T {
+	all := make([]T, rb.len)
+	for i := range all {
+		all[i] = rb.buf[(rb.head+i)%len(rb.buf)]
+	}
+	return all
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic_test
+
+import (
+	"reflect"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+	"changkun.de/x/pkg/ds/generic"
+)
+
+func TestRingBuffer(t *testing.T) {
+	rb := generic.NewRingBuffer[int](10)
+	if _, ok := rb.Get(); ok {
+		t.Fatalf("get from empty buffer succeeded")
+	}
+
+	for i := 0; i < 20; i++ {
+		ok := rb.Put(i)
+		if i < 10 && !ok {
+			t.Errorf("put failed, %v:%v", i, ok)
+		}
+		if i > 9 && ok {
+			t.Errorf("put failed, %v:%v", i, ok)
+		}
+	}
+	if !rb.IsFull() {
+		t.Fatalf("buffer is not full")
+	}
+	v := rb.LookAll()
+	want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("not equal: %v", v)
+	}
+
+	for i := 0; i < 5; i++ {
+		if v, ok := rb.Get(); !ok || v != i {
+			t.Errorf("get failed, %v:%v", v, i)
+		}
+	}
+	for i := 10; i < 13; i++ {
+		rb.Put(i)
+	}
+
+	v = rb.LookAll()
+	want = []int{5, 6, 7, 8, 9, 10, 11, 12}
+	if !reflect.DeepEqual(v, want) || rb.Len() != len(want) {
+		t.Errorf("not equal: %v", v)
+	}
+}
+
+func BenchmarkRingBuffer(b *testing.B) {
+	b.Run("ds", func(b *testing.B) {
+		b.ReportAllocs()
+		rb := ds.NewRingBuffer(64)
+		for i := 0; i < b.N; i++ {
+			rb.Put(i)
+			_ = rb.Get().(int)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		b.ReportAllocs()
+		rb := generic.NewRingBuffer[int](64)
+		for i := 0; i < b.N; i++ {
+			rb.Put(i)
+			rb.Get()
+		}
+	})
+}
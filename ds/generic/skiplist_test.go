@@ -0,0 +1,70 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic_test
+
+import (
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+	"changkun.de/x/pkg/ds/generic"
+)
+
+func TestSkipList(t *testing.T) {
+	sl := generic.NewSkipList[int, string]()
+	if _, ok := sl.Get(1); ok || sl.Search(1) {
+		t.Fatalf("get from empty skiplist succeeded")
+	}
+	for i := 0; i < 100; i++ {
+		sl.Set(i, "")
+	}
+	sl.Set(1, "one")
+	if v, ok := sl.Get(1); !ok || v != "one" || sl.Len() != 100 {
+		t.Fatalf("get: got %v, %v, len %v", v, ok, sl.Len())
+	}
+	if v, ok := sl.Del(1); !ok || v != "one" || sl.Search(1) {
+		t.Fatalf("del: got %v, %v", v, ok)
+	}
+	if _, ok := sl.Del(1); ok {
+		t.Fatalf("del absent key succeeded")
+	}
+
+	// reverse order by a custom comparator
+	rl := generic.NewSkipListFunc[int, int](func(a, b int) bool { return a > b })
+	for i := 0; i < 100; i++ {
+		rl.Set(i, i)
+	}
+	current := 20
+	rl.Range(20, 10, func(v int) {
+		if v != current {
+			t.Fatalf("range failed, want %v, got %v", current, v)
+		}
+		current--
+	})
+	if current != 10 {
+		t.Fatalf("range out of bound, want %v, got %v", 10, current)
+	}
+}
+
+func BenchmarkSkipList(b *testing.B) {
+	b.Run("ds", func(b *testing.B) {
+		b.ReportAllocs()
+		sl := ds.NewSkipList(func(a, b interface{}) bool {
+			return a.(int) < b.(int)
+		})
+		for i := 0; i < b.N; i++ {
+			sl.Set(i%1024, i)
+			v, _ := sl.Get(i % 1024)
+			_ = v.(int)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		b.ReportAllocs()
+		sl := generic.NewSkipList[int, int]()
+		for i := 0; i < b.N; i++ {
+			sl.Set(i%1024, i)
+			sl.Get(i % 1024)
+		}
+	})
+}
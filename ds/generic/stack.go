@@ -0,0 +1,47 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic
+
+// Stack is a FILO stack
+type Stack[T any] struct {
+	v []T
+}
+
+// NewStack returns a new stack
+func NewStack[T any]() *Stack[T] {
+	return &Stack[T]{v: make([]T, 0)}
+}
+
+// Push pushes a value to the stack
+func (s *Stack[T]) Push(v T) {
+	s.v = append(s.v, v)
+}
+
+// Pop pops the top value out of the stack.
+// It returns false if the stack is empty.
+func (s *Stack[T]) Pop() (v T, ok bool) {
+	if len(s.v) == 0 {
+		return
+	}
+	var zero T
+	v = s.v[len(s.v)-1]
+	s.v[len(s.v)-1] = zero
+	s.v = s.v[:len(s.v)-1]
+	return v, true
+}
+
+// Peek returns the top value of the stack without removing it.
+// It returns false if the stack is empty.
+func (s *Stack[T]) Peek() (v T, ok bool) {
+	if len(s.v) == 0 {
+		return
+	}
+	return s.v[len(s.v)-1], true
+}
+
+// Len returns the number of values in the stack
+func (s *Stack[T]) Len() int {
+	return len(s.v)
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package generic_test
+
+import (
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+	"changkun.de/x/pkg/ds/generic"
+)
+
+func TestStack(t *testing.T) {
+	s := generic.NewStack[int]()
+	if _, ok := s.Pop(); ok {
+		t.Fatalf("pop empty stack succeeded")
+	}
+	if _, ok := s.Peek(); ok {
+		t.Fatalf("peek empty stack succeeded")
+	}
+	for i := 0; i < 10; i++ {
+		s.Push(i)
+	}
+	if v, ok := s.Peek(); !ok || v != 9 || s.Len() != 10 {
+		t.Fatalf("peek: want 9, got %v, len %v", v, s.Len())
+	}
+	for i := 9; i >= 0; i-- {
+		if v, ok := s.Pop(); !ok || v != i {
+			t.Fatalf("pop: want %v, got %v", i, v)
+		}
+	}
+	if s.Len() != 0 {
+		t.Fatalf("want 0, got %v", s.Len())
+	}
+}
+
+func BenchmarkStack(b *testing.B) {
+	b.Run("ds", func(b *testing.B) {
+		b.ReportAllocs()
+		s := ds.NewStack()
+		for i := 0; i < b.N; i++ {
+			s.Push(i)
+			_ = s.Pop().(int)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		b.ReportAllocs()
+		s := generic.NewStack[int]()
+		for i := 0; i < b.N; i++ {
+			s.Push(i)
+			s.Pop()
+		}
+	})
+}
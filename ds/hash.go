@@ -0,0 +1,24 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"fmt"
+	"hash/maphash"
+)
+
+// fmtHasher returns a hash function that hashes keys by their fmt
+// representation. It is correct for any comparable key whose fmt
+// representation is unique, but slow. Callers should prefer a hash
+// function that is specialised for their keys.
+func fmtHasher[K comparable]() func(K) uint64 {
+	seed := maphash.MakeSeed()
+	return func(k K) uint64 {
+		var h maphash.Hash
+		h.SetSeed(seed)
+		fmt.Fprint(&h, k)
+		return h.Sum64()
+	}
+}
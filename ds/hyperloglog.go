@@ -0,0 +1,336 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// hllSparsePrecision is the precision of the sparse representation.
+const hllSparsePrecision = 25
+
+// HyperLogLog is a cardinality sketch. Its relative standard error
+// is about 1.04/sqrt(2^p) with 2^p registers for precision p.
+//
+// It implements the improvements of HyperLogLog++: 64-bit hashes that
+// remove the correction of large cardinalities, and a sparse
+// representation of precision 25 that is exact on small cardinalities.
+// The sparse representation is a sorted list of the index and the rank
+// of every non-zero register, which is delta and varint encoded, and
+// new registers are collected in a small unsorted buffer that is merged
+// into the list when it is full. The list is converted to the dense
+// registers when it grows larger than them. The empirical bias
+// correction of HyperLogLog++ is replaced by the improved estimator of
+// Ertl, which is unbiased on all cardinalities without the tables.
+// Paper: Heule, Stefan et al. (2013). "HyperLogLog in Practice:
+// Algorithmic Engineering of a State of The Art Cardinality Estimation
+// Algorithm". EDBT 2013; Ertl, Otmar (2017). "New cardinality
+// estimation algorithms for HyperLogLog sketches". arXiv:1702.01284
+type HyperLogLog struct {
+	p         uint8
+	registers []uint8  // dense representation, nil if sparse
+	sparse    []byte   // sorted sparse entries, delta and varint encoded
+	n         int      // number of entries in sparse
+	tmp       []uint32 // sparse entries that are not merged into sparse yet
+}
+
+// A sparse entry is the index of precision 25 and the rank of a
+// register, the rank is at most 64-25+1 and fits in 6 bits. The entries
+// of the same index are sorted by rank.
+func hllEntry(idx uint32, rank uint8) uint32 { return idx<<6 | uint32(rank) }
+func hllIndex(e uint32) uint32               { return e >> 6 }
+func hllEntryRank(e uint32) uint8            { return uint8(e & 63) }
+
+// NewHyperLogLog creates a HyperLogLog of precision p in [4, 18].
+func NewHyperLogLog(p uint8) *HyperLogLog {
+	if p < 4 || p > 18 {
+		panic("ds: precision of HyperLogLog must be in [4, 18]")
+	}
+	return &HyperLogLog{p: p}
+}
+
+// hllRank splits a hash to the index of its first p bits and the
+// rank of the rest bits, i.e. the position of the leftmost 1-bit.
+func hllRank(h uint64, p uint8) (idx uint32, rank uint8) {
+	idx = uint32(h >> (64 - p))
+	w := h<<p | 1<<(p-1) // bound the rank by 64-p+1
+	return idx, uint8(bits.LeadingZeros64(w)) + 1
+}
+
+// Add adds data to the sketch.
+func (h *HyperLogLog) Add(data []byte) {
+	h.AddHash(sketchHash(data))
+}
+
+// AddHash adds an element by its 64-bit hash, which must be uniformly
+// distributed, and be stable if the sketch is merged with others.
+func (h *HyperLogLog) AddHash(x uint64) {
+	if h.registers == nil {
+		h.insert(hllEntry(hllRank(x, hllSparsePrecision)))
+		return
+	}
+	idx, rank := hllRank(x, h.p)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// insert inserts a sparse entry into the sketch of any representation.
+func (h *HyperLogLog) insert(e uint32) {
+	if h.registers != nil {
+		i, r := h.denseRank(hllIndex(e), hllEntryRank(e))
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+		return
+	}
+	h.tmp = append(h.tmp, e)
+	if len(h.tmp) >= max(1<<h.p/32, 8) {
+		h.flush()
+	}
+}
+
+// flush merges the buffered entries into the sorted list, and converts
+// the sketch to the dense registers if the list outgrows them.
+func (h *HyperLogLog) flush() {
+	if len(h.tmp) == 0 {
+		return
+	}
+	sort.Slice(h.tmp, func(i, j int) bool { return h.tmp[i] < h.tmp[j] })
+	r := hllSparseReader{b: h.sparse}
+	w := hllSparseWriter{b: make([]byte, 0, len(h.sparse)+2*len(h.tmp))}
+	e, ok := r.next()
+	for _, t := range h.tmp {
+		for ; ok && e <= t; e, ok = r.next() {
+			w.put(e)
+		}
+		w.put(t)
+	}
+	for ; ok; e, ok = r.next() {
+		w.put(e)
+	}
+	h.sparse, h.n = w.done()
+	h.tmp = h.tmp[:0]
+	if len(h.sparse) > 1<<h.p {
+		h.toDense()
+	}
+}
+
+// each iterates all sparse entries, the ones of the buffer are neither
+// sorted nor unique.
+func (h *HyperLogLog) each(op func(e uint32)) {
+	r := hllSparseReader{b: h.sparse}
+	for e, ok := r.next(); ok; e, ok = r.next() {
+		op(e)
+	}
+	for _, e := range h.tmp {
+		op(e)
+	}
+}
+
+func (h *HyperLogLog) toDense() {
+	h.registers = make([]uint8, 1<<h.p)
+	h.each(func(e uint32) {
+		i, r := h.denseRank(hllIndex(e), hllEntryRank(e))
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	})
+	h.sparse, h.n, h.tmp = nil, 0, nil
+}
+
+// hllSparseReader decodes a sorted list of sparse entries.
+type hllSparseReader struct {
+	b []byte
+	e uint32
+}
+
+func (r *hllSparseReader) next() (uint32, bool) {
+	if len(r.b) == 0 {
+		return 0, false
+	}
+	d, n := binary.Uvarint(r.b)
+	r.b = r.b[n:]
+	r.e += uint32(d)
+	return r.e, true
+}
+
+// hllSparseWriter encodes sorted sparse entries, and keeps only the
+// last, i.e. the largest rank, of the entries of the same index.
+type hllSparseWriter struct {
+	b       []byte
+	n       int
+	prev    uint32 // the last written entry
+	pending uint32 // the last put entry, 0 if none
+}
+
+func (w *hllSparseWriter) put(e uint32) {
+	if w.pending != 0 && hllIndex(e) != hllIndex(w.pending) {
+		w.write()
+	}
+	w.pending = e
+}
+
+func (w *hllSparseWriter) write() {
+	w.b = binary.AppendUvarint(w.b, uint64(w.pending-w.prev))
+	w.prev, w.n = w.pending, w.n+1
+}
+
+func (w *hllSparseWriter) done() ([]byte, int) {
+	if w.pending != 0 {
+		w.write()
+	}
+	return w.b, w.n
+}
+
+// denseRank converts a sparse index and rank to the dense ones.
+func (h *HyperLogLog) denseRank(idx uint32, rank uint8) (uint32, uint8) {
+	shift := hllSparsePrecision - h.p
+	i := idx >> shift
+	if rest := idx & (1<<shift - 1); rest != 0 {
+		return i, uint8(bits.LeadingZeros32(rest) - (32 - int(shift)) + 1)
+	}
+	return i, rank + shift
+}
+
+// Estimate estimates the number of distinct elements in the sketch.
+func (h *HyperLogLog) Estimate() uint64 {
+	h.flush()
+	if h.registers == nil {
+		// linear counting of the sparse registers, which is
+		// exact until collisions at precision 25 are frequent.
+		m := float64(uint64(1) << hllSparsePrecision)
+		return uint64(math.Round(m * math.Log(m/(m-float64(h.n)))))
+	}
+
+	q := 64 - int(h.p)
+	m := float64(len(h.registers))
+	c := make([]float64, q+2) // histogram of the registers
+	for _, r := range h.registers {
+		c[r]++
+	}
+	z := m * hllTau(1-c[q+1]/m)
+	for k := q; k >= 1; k-- {
+		z = 0.5 * (z + c[k])
+	}
+	z += m * hllSigma(c[0]/m)
+	return uint64(math.Round(m * m / (2 * math.Ln2) / z))
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
+
+// Merge merges o into h, and h becomes the sketch of the union of
+// both sets. Both sketches must have the same precision.
+func (h *HyperLogLog) Merge(o *HyperLogLog) error {
+	if h.p != o.p {
+		return ErrIncompatible
+	}
+	if h == o {
+		return nil
+	}
+	if o.registers == nil {
+		o.each(h.insert)
+		return nil
+	}
+	if h.registers == nil {
+		h.toDense()
+	}
+	for i, r := range o.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The sparse representation is encoded in ascending index order, so
+// that equal sketches have equal encodings.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	b := appendSketchHeader(nil, sketchHyperLogLog)
+	b = append(b, h.p)
+	h.flush()
+	if h.registers != nil {
+		b = append(b, 1)
+		return append(b, h.registers...), nil
+	}
+	b = append(b, 0)
+	b = binary.BigEndian.AppendUint64(b, uint64(h.n))
+	h.each(func(e uint32) {
+		b = binary.BigEndian.AppendUint32(b, hllIndex(e))
+		b = append(b, hllEntryRank(e))
+	})
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	r := newSketchReader(data, sketchHyperLogLog)
+	p, dense := r.byte(), r.byte()
+	if r.err != nil {
+		return r.err
+	}
+	if p < 4 || p > 18 || dense > 1 {
+		return ErrBadEncoding
+	}
+	o := &HyperLogLog{p: p}
+	maxRank := uint8(64 - hllSparsePrecision + 1)
+	if dense == 1 {
+		o.registers = append([]uint8(nil), r.next(1<<p)...)
+		maxRank = 64 - p + 1
+	} else {
+		n := r.length(5)
+		for i := 0; i < n; i++ {
+			idx, rank := r.uint32(), r.byte()
+			if idx >= 1<<hllSparsePrecision || rank == 0 || rank > maxRank {
+				return ErrBadEncoding
+			}
+			o.insert(hllEntry(idx, rank))
+		}
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	for _, rank := range o.registers {
+		if rank > maxRank {
+			return ErrBadEncoding
+		}
+	}
+	*h = *o
+	return nil
+}
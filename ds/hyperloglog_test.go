@@ -0,0 +1,88 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 10000, 100000, 1000000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			h := ds.NewHyperLogLog(14)
+			for i := 0; i < n; i++ {
+				h.Add([]byte(strconv.Itoa(i)))
+				h.Add([]byte(strconv.Itoa(i))) // duplicates are not counted
+			}
+			// 4 times the standard error 1.04/sqrt(2^14)
+			if e := h.Estimate(); math.Abs(float64(e)-float64(n)) > 0.033*float64(n) {
+				t.Fatalf("want about %v, got %v", n, e)
+			}
+		})
+	}
+}
+
+func TestHyperLogLogSparse(t *testing.T) {
+	// the same set added in any order with duplicates has the same
+	// sparse representation.
+	a, b := ds.NewHyperLogLog(14), ds.NewHyperLogLog(14)
+	for i := 0; i < 3000; i++ {
+		a.Add([]byte(strconv.Itoa(i)))
+	}
+	for i := 2999; i >= 0; i-- {
+		b.Add([]byte(strconv.Itoa(i)))
+		b.Add([]byte(strconv.Itoa(i / 2)))
+	}
+	da, _ := a.MarshalBinary()
+	db, _ := b.MarshalBinary()
+	if da[3] != 0 {
+		t.Fatalf("want a sparse sketch")
+	}
+	if !bytes.Equal(da, db) {
+		t.Fatalf("want equal encodings of equal sets")
+	}
+	if e := a.Estimate(); math.Abs(float64(e)-3000) > 3 {
+		t.Fatalf("want about 3000, got %v", e)
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	// merge sketches in all combinations of representations.
+	for _, sizes := range [][2]int{{100, 200}, {100, 100000}, {100000, 100}, {100000, 200000}} {
+		a, b := ds.NewHyperLogLog(12), ds.NewHyperLogLog(12)
+		for i := 0; i < sizes[0]; i++ {
+			a.Add([]byte(strconv.Itoa(i)))
+		}
+		for i := 0; i < sizes[1]; i++ {
+			b.Add([]byte(strconv.Itoa(-i)))
+		}
+		if err := a.Merge(b); err != nil {
+			t.Fatalf("merge failed: %v", err)
+		}
+
+		data, _ := a.MarshalBinary()
+		h := &ds.HyperLogLog{}
+		if err := h.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unmarshal failed: %v", err)
+		}
+		n := float64(sizes[0] + sizes[1] - 1) // 0 is in both
+		if e := h.Estimate(); math.Abs(float64(e)-n) > 0.065*n {
+			t.Fatalf("%v: want about %v, got %v", sizes, n, e)
+		}
+	}
+
+	if err := ds.NewHyperLogLog(12).Merge(ds.NewHyperLogLog(14)); !errors.Is(err, ds.ErrIncompatible) {
+		t.Fatalf("merge of different precisions, want %v, got %v", ds.ErrIncompatible, err)
+	}
+	if err := (&ds.HyperLogLog{}).UnmarshalBinary([]byte{4, 1, 99}); !errors.Is(err, ds.ErrBadEncoding) {
+		t.Fatalf("unmarshal of bad data, want %v, got %v", ds.ErrBadEncoding, err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// IntervalTree is a map from half-open intervals [start, end) to
+// values, that finds all intervals overlapping a given interval or
+// containing a given point.
+//
+// It is an RBTree of intervals ordered by start, where every node is
+// augmented by the maximum end of its subtree, so that subtrees
+// without any overlapping interval are skipped. Put, Get and Del are
+// O(log n), a query is O(log n + m) for m reported intervals.
+type IntervalTree[K any, V any] struct {
+	t    *RBTree
+	less func(a, b K) bool
+}
+
+type ikey[K any] struct {
+	start, end K
+}
+
+type ientry[K any, V any] struct {
+	v   V
+	max K // maximum end in the subtree
+}
+
+// NewIntervalTree creates an interval tree.
+func NewIntervalTree[K any, V any](less func(a, b K) bool) *IntervalTree[K, V] {
+	it := &IntervalTree[K, V]{less: less}
+	it.t = NewRBTree(func(a, b interface{}) bool {
+		x, y := a.(ikey[K]), b.(ikey[K])
+		switch {
+		case less(x.start, y.start):
+			return true
+		case less(y.start, x.start):
+			return false
+		default:
+			return less(x.end, y.end)
+		}
+	})
+	it.t.augment = func(n *rbnode) {
+		e := n.v.(*ientry[K, V])
+		e.max = n.k.(ikey[K]).end
+		for _, c := range [2]*rbnode{n.left, n.right} {
+			if c != nil && less(e.max, c.v.(*ientry[K, V]).max) {
+				e.max = c.v.(*ientry[K, V]).max
+			}
+		}
+	}
+	return it
+}
+
+// Len returns the number of intervals in the tree.
+func (it *IntervalTree[K, V]) Len() int {
+	return it.t.Len()
+}
+
+// Put stores the value by the interval [start, end). It panics if
+// the interval is empty.
+func (it *IntervalTree[K, V]) Put(start, end K, v V) {
+	if !it.less(start, end) {
+		panic("ds: empty interval")
+	}
+	if n := it.t.find(ikey[K]{start, end}); n != nil {
+		n.v.(*ientry[K, V]).v = v
+		return
+	}
+	it.t.Put(ikey[K]{start, end}, &ientry[K, V]{v: v})
+}
+
+// Get returns the value of the interval [start, end).
+func (it *IntervalTree[K, V]) Get(start, end K) (v V, ok bool) {
+	n := it.t.find(ikey[K]{start, end})
+	if n == nil {
+		return
+	}
+	return n.v.(*ientry[K, V]).v, true
+}
+
+// Del deletes the interval [start, end).
+func (it *IntervalTree[K, V]) Del(start, end K) {
+	it.t.Del(ikey[K]{start, end})
+}
+
+// Stab iterates all intervals that contain the point p in ascending
+// order of their starts with op. The iteration stops if op returns false.
+func (it *IntervalTree[K, V]) Stab(p K, op func(start, end K, v V) bool) {
+	it.overlap(it.t.root, p, p, true, op)
+}
+
+// Overlap iterates all intervals that overlap [start, end) in
+// ascending order of their starts with op. The iteration stops if
+// op returns false.
+func (it *IntervalTree[K, V]) Overlap(start, end K, op func(start, end K, v V) bool) {
+	it.overlap(it.t.root, start, end, false, op)
+}
+
+// overlap visits intervals [s, e) in the subtree of n that s < end
+// and start < e, or s <= p and p < e if stab.
+func (it *IntervalTree[K, V]) overlap(n *rbnode, start, end K, stab bool,
+	op func(start, end K, v V) bool) bool {
+	if n == nil {
+		return true
+	}
+	e := n.v.(*ientry[K, V])
+	if !it.less(start, e.max) {
+		return true // all intervals end before start
+	}
+	if !it.overlap(n.left, start, end, stab, op) {
+		return false
+	}
+	k := n.k.(ikey[K])
+	before := it.less(k.start, end) // the interval starts before end
+	if stab {
+		before = !it.less(end, k.start)
+	}
+	if !before {
+		return true // the intervals on the right start even later
+	}
+	if it.less(start, k.end) && !op(k.start, k.end, e.v) {
+		return false
+	}
+	return it.overlap(n.right, start, end, stab, op)
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestIntervalTree(t *testing.T) {
+	it := ds.NewIntervalTree[int, int](intLess)
+	want := map[[2]int]int{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		s := r.Intn(1000)
+		e := s + 1 + r.Intn(50)
+		if r.Intn(4) == 0 {
+			it.Del(s, e)
+			delete(want, [2]int{s, e})
+		} else {
+			it.Put(s, e, i)
+			want[[2]int{s, e}] = i
+		}
+		if it.Len() != len(want) {
+			t.Fatalf("want len %v, got %v", len(want), it.Len())
+		}
+	}
+	for k, v := range want {
+		if got, ok := it.Get(k[0], k[1]); !ok || got != v {
+			t.Fatalf("get %v, want %v, got %v", k, v, got)
+		}
+	}
+
+	for i := 0; i < 200; i++ {
+		s := r.Intn(1100) - 50
+		e := s + 1 + r.Intn(100)
+
+		expect := 0
+		for k := range want {
+			if k[0] < e && s < k[1] {
+				expect++
+			}
+		}
+		got, last := 0, -1
+		it.Overlap(s, e, func(start, end, v int) bool {
+			if !(start < e && s < end) || want[[2]int{start, end}] != v {
+				t.Fatalf("[%v, %v) does not overlap [%v, %v)", start, end, s, e)
+			}
+			if start < last {
+				t.Fatalf("intervals are not in ascending order")
+			}
+			got, last = got+1, start
+			return true
+		})
+		if got != expect {
+			t.Fatalf("overlap [%v, %v), want %v intervals, got %v", s, e, expect, got)
+		}
+
+		expect = 0
+		for k := range want {
+			if k[0] <= s && s < k[1] {
+				expect++
+			}
+		}
+		got = 0
+		it.Stab(s, func(start, end, v int) bool {
+			if !(start <= s && s < end) {
+				t.Fatalf("[%v, %v) does not contain %v", start, end, s)
+			}
+			got++
+			return true
+		})
+		if got != expect {
+			t.Fatalf("stab %v, want %v intervals, got %v", s, expect, got)
+		}
+	}
+
+	n := 0
+	it.Overlap(0, 1000, func(start, end, v int) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 {
+		t.Fatalf("iteration does not stop, got %v", n)
+	}
+}
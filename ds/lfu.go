@@ -0,0 +1,123 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// LFUCache implements a Cache that evicts the least frequently used
+// entry. Ties are broken by evicting the least recently used one.
+// All operations are O(1).
+//
+// The entries of the same frequency are kept in a bucket, and the
+// non-empty buckets are linked in ascending order of frequency, so
+// that the least frequently used entry is always in the first bucket.
+// Paper: Shah, Ketan; Mitra, Anirban; Matani, Dhruv (2010). "An O(1)
+// algorithm for implementing the LFU cache eviction scheme".
+type LFUCache[K comparable, V any] struct {
+	cap   int
+	items map[K]*centry[K, V]
+	freqs map[int]*lfuBucket[K, V]
+	root  lfuBucket[K, V] // sentinel of the bucket list
+}
+
+// lfuBucket holds the entries that are used freq times, the front of
+// its list is the most recently used entry.
+type lfuBucket[K comparable, V any] struct {
+	prev, next *lfuBucket[K, V]
+	freq       int
+	ll         clist[K, V]
+}
+
+// NewLFUCache creates a LFU cache with given capacity.
+func NewLFUCache[K comparable, V any](capacity int) *LFUCache[K, V] {
+	c := &LFUCache[K, V]{
+		cap:   capacity,
+		items: make(map[K]*centry[K, V], capacity),
+		freqs: map[int]*lfuBucket[K, V]{},
+	}
+	c.root.next = &c.root
+	c.root.prev = &c.root
+	return c
+}
+
+// Get returns the cached value of key and reports whether it was found.
+func (c *LFUCache[K, V]) Get(key K) (v V, ok bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.touch(e)
+	return e.v, true
+}
+
+// Put stores the value by given key.
+func (c *LFUCache[K, V]) Put(key K, value V) {
+	if e, ok := c.items[key]; ok {
+		e.v = value
+		c.touch(e)
+		return
+	}
+	if c.cap <= 0 {
+		return
+	}
+	if len(c.items) >= c.cap {
+		c.unlink(c.root.next.ll.back())
+	}
+	e := &centry[K, V]{k: key, v: value, freq: 1}
+	c.link(e, &c.root)
+	c.items[key] = e
+}
+
+// Del deletes the stored value by given key.
+func (c *LFUCache[K, V]) Del(key K) {
+	if e, ok := c.items[key]; ok {
+		c.unlink(e)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *LFUCache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// touch increases the frequency of e.
+func (c *LFUCache[K, V]) touch(e *centry[K, V]) {
+	b := c.freqs[e.freq]
+	b.ll.remove(e)
+	e.freq++
+	// drop b only after linking e, so that b is still in the bucket
+	// list as the predecessor of the bucket of e.
+	c.link(e, b)
+	c.drop(b)
+}
+
+// link pushes e to the bucket of its frequency, which is created after
+// prev if it does not exist.
+func (c *LFUCache[K, V]) link(e *centry[K, V], prev *lfuBucket[K, V]) {
+	b, ok := c.freqs[e.freq]
+	if !ok {
+		b = &lfuBucket[K, V]{prev: prev, next: prev.next, freq: e.freq}
+		prev.next.prev = b
+		prev.next = b
+		c.freqs[e.freq] = b
+	}
+	b.ll.pushFront(e)
+}
+
+// unlink removes e from the cache.
+func (c *LFUCache[K, V]) unlink(e *centry[K, V]) {
+	b := c.freqs[e.freq]
+	b.ll.remove(e)
+	c.drop(b)
+	delete(c.items, e.k)
+}
+
+// drop removes b from the bucket list if it is empty.
+func (c *LFUCache[K, V]) drop(b *lfuBucket[K, V]) {
+	if b.ll.len > 0 {
+		return
+	}
+	b.prev.next = b.next
+	b.next.prev = b.prev
+	delete(c.freqs, b.freq)
+}
@@ -69,3 +69,114 @@ func (l *LRU) Put(key int, value int) {
 	}
 	l.store[0] = &Node{k: key, v: value}
 }
+
+// Cache returns a view of l that implements Cache, so that l can be
+// used wherever a Cache[int, int] is expected. Unlike Get, the Get of
+// the view reports a missing key by ok, as -1 may be a cached value.
+func (l *LRU) Cache() Cache[int, int] {
+	return lruCache{l}
+}
+
+// lruCache adapts LRU to the Cache interface.
+type lruCache struct {
+	l *LRU
+}
+
+func (c lruCache) Get(key int) (v int, ok bool) {
+	for i, n := range c.l.store {
+		if n == nil {
+			break
+		}
+		if key == n.k {
+			copy(c.l.store[1:i+1], c.l.store[0:i])
+			c.l.store[0] = n
+			return n.v, true
+		}
+	}
+	return
+}
+
+func (c lruCache) Put(key int, value int) {
+	c.l.Put(key, value)
+}
+
+func (c lruCache) Del(key int) {
+	for i, n := range c.l.store {
+		if n == nil {
+			break
+		}
+		if key == n.k {
+			copy(c.l.store[i:], c.l.store[i+1:])
+			c.l.store[len(c.l.store)-1] = nil
+			return
+		}
+	}
+}
+
+func (c lruCache) Len() (n int) {
+	for _, e := range c.l.store {
+		if e == nil {
+			break
+		}
+		n++
+	}
+	return
+}
+
+// LRUCache implements a Cache that evicts the least recently used entry.
+type LRUCache[K comparable, V any] struct {
+	cap   int
+	items map[K]*centry[K, V]
+	ll    clist[K, V]
+}
+
+// NewLRUCache creates a LRU cache with given capacity.
+func NewLRUCache[K comparable, V any](capacity int) *LRUCache[K, V] {
+	return &LRUCache[K, V]{
+		cap:   capacity,
+		items: make(map[K]*centry[K, V], capacity),
+	}
+}
+
+// Get returns the cached value of key and reports whether it was found.
+func (c *LRUCache[K, V]) Get(key K) (v V, ok bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.ll.moveToFront(e)
+	return e.v, true
+}
+
+// Put stores the value by given key.
+func (c *LRUCache[K, V]) Put(key K, value V) {
+	if e, ok := c.items[key]; ok {
+		e.v = value
+		c.ll.moveToFront(e)
+		return
+	}
+	if c.cap <= 0 {
+		return
+	}
+	if c.ll.len >= c.cap {
+		e := c.ll.back()
+		c.ll.remove(e)
+		delete(c.items, e.k)
+	}
+	e := &centry[K, V]{k: key, v: value}
+	c.ll.pushFront(e)
+	c.items[key] = e
+}
+
+// Del deletes the stored value by given key.
+func (c *LRUCache[K, V]) Del(key K) {
+	if e, ok := c.items[key]; ok {
+		c.ll.remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *LRUCache[K, V]) Len() int {
+	return c.ll.len
+}
@@ -32,3 +32,27 @@ func TestLRU(t *testing.T) {
 		t.Fatalf("want 4")
 	}
 }
+
+func TestLRUCacheView(t *testing.T) {
+	lru := ds.NewLRU(2)
+	c := lru.Cache()
+	c.Put(1, -1)
+	c.Put(2, 2)
+	if v, ok := c.Get(1); !ok || v != -1 { // 1, 2
+		t.Fatalf("want -1, got %v, %v", v, ok)
+	}
+	c.Put(3, 3) // 3, 1
+	if _, ok := c.Get(2); ok {
+		t.Fatalf("want 2 evicted")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("want 2, got %v", c.Len())
+	}
+	c.Del(3) // 1
+	if _, ok := c.Get(3); ok || c.Len() != 1 {
+		t.Fatalf("want 3 deleted")
+	}
+	if lru.Get(1) != -1 {
+		t.Fatalf("view does not share the entries of lru")
+	}
+}
@@ -0,0 +1,684 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+// Errors of PagedBTree.
+var (
+	ErrTooLarge    = errors.New("key or value is too large")
+	ErrCorrupted   = errors.New("file is corrupted")
+	ErrBadPageSize = errors.New("page size must be a power of 2 in [512, 65536]")
+)
+
+// page layout of PagedBTree. Page 0 is the meta page, and every other
+// page is a node or a free page:
+//
+//	meta:     magic [4]byte, page size, root, number of pages,
+//	          head of free pages uint32, number of keys uint64
+//	leaf:     type byte, count uint16, next leaf uint32,
+//	          count * (key len uint16, value len uint16, key, value)
+//	internal: type byte, count uint16, first child uint32,
+//	          count * (key len uint16, key, child uint32)
+//	free:     type byte, unused uint16, next free page uint32
+const (
+	pagedMagic  = "PBT1"
+	pageHeader  = 7
+	pageLeaf    = 1
+	pageInner   = 2
+	pageFree    = 3
+	defaultPage = 4096
+	defaultPool = 256
+)
+
+// PagedBTree is a sorted map of byte strings that is stored in a file.
+// It is a B+ tree of fixed-size pages, and the pages are cached in a
+// buffer pool of bounded size, so that the map can be much larger than
+// the memory. Keys are compared by bytes.Compare.
+//
+// Modifications are written back when pages are evicted from the pool
+// and by Sync, the file is not crash safe between calls of Sync.
+// A PagedBTree is not safe for concurrent use.
+type PagedBTree struct {
+	pool     *bufferPool
+	pageSize int
+	root     uint32
+	npages   uint32
+	free     uint32 // head of free pages, 0 if none
+	len      uint64
+}
+
+// PagedBTreeOption sets an option of a PagedBTree.
+type PagedBTreeOption func(*PagedBTree)
+
+// WithPageSize sets the page size of a new file, the default is
+// 4096. The page size of an existing file is kept.
+func WithPageSize(size int) PagedBTreeOption {
+	return func(t *PagedBTree) {
+		t.pageSize = size
+	}
+}
+
+// WithPoolSize sets the number of pages that the buffer pool caches,
+// the default is 256.
+func WithPoolSize(pages int) PagedBTreeOption {
+	return func(t *PagedBTree) {
+		t.pool.cap = pages
+	}
+}
+
+// OpenPagedBTree opens the B-tree stored in the file of given path,
+// the file is created if it does not exist.
+func OpenPagedBTree(path string, opts ...PagedBTreeOption) (*PagedBTree, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	t := &PagedBTree{
+		pageSize: defaultPage,
+		pool:     &bufferPool{f: f, cap: defaultPool, frames: map[uint32]*list.Element{}, lru: list.New()},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.pool.cap < 1 {
+		t.pool.cap = 1
+	}
+
+	st, err := f.Stat()
+	if err == nil {
+		if st.Size() == 0 {
+			err = t.init()
+		} else {
+			err = t.readMeta()
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func validPageSize(size int) bool {
+	return size >= 512 && size <= 1<<16 && size&(size-1) == 0
+}
+
+func (t *PagedBTree) init() error {
+	if !validPageSize(t.pageSize) {
+		return ErrBadPageSize
+	}
+	t.pool.pageSize = t.pageSize
+	t.root, t.npages = 1, 2
+	t.pool.put(1, encodePage(&pnode{leaf: true}, t.pageSize))
+	return t.Sync()
+}
+
+func (t *PagedBTree) readMeta() error {
+	var b [28]byte
+	if _, err := t.pool.f.ReadAt(b[:], 0); err != nil {
+		return ErrCorrupted
+	}
+	if string(b[:4]) != pagedMagic {
+		return ErrCorrupted
+	}
+	t.pageSize = int(binary.BigEndian.Uint32(b[4:]))
+	t.root = binary.BigEndian.Uint32(b[8:])
+	t.npages = binary.BigEndian.Uint32(b[12:])
+	t.free = binary.BigEndian.Uint32(b[16:])
+	t.len = binary.BigEndian.Uint64(b[20:])
+	if !validPageSize(t.pageSize) || t.root == 0 || t.root >= t.npages || t.free >= t.npages {
+		return ErrCorrupted
+	}
+	t.pool.pageSize = t.pageSize
+	return nil
+}
+
+func (t *PagedBTree) writeMeta() {
+	b := make([]byte, t.pageSize)
+	copy(b, pagedMagic)
+	binary.BigEndian.PutUint32(b[4:], uint32(t.pageSize))
+	binary.BigEndian.PutUint32(b[8:], t.root)
+	binary.BigEndian.PutUint32(b[12:], t.npages)
+	binary.BigEndian.PutUint32(b[16:], t.free)
+	binary.BigEndian.PutUint64(b[20:], t.len)
+	t.pool.put(0, b)
+}
+
+// Len returns the number of keys.
+func (t *PagedBTree) Len() int {
+	return int(t.len)
+}
+
+// Sync writes all modified pages to the file and commits the file
+// to stable storage.
+func (t *PagedBTree) Sync() error {
+	t.writeMeta()
+	return t.pool.flush()
+}
+
+// Close syncs and closes the file.
+func (t *PagedBTree) Close() error {
+	err := t.Sync()
+	if cerr := t.pool.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// pnode is a decoded page of a node.
+type pnode struct {
+	id       uint32
+	leaf     bool
+	keys     [][]byte
+	vals     [][]byte // of leaves
+	children []uint32 // of internal nodes, len(keys)+1
+	next     uint32   // next leaf, 0 if none
+}
+
+func (n *pnode) size() int {
+	s := pageHeader
+	for i, k := range n.keys {
+		if n.leaf {
+			s += 4 + len(k) + len(n.vals[i])
+		} else {
+			s += 6 + len(k)
+		}
+	}
+	return s
+}
+
+func encodePage(n *pnode, pageSize int) []byte {
+	b := make([]byte, pageSize)
+	binary.BigEndian.PutUint16(b[1:], uint16(len(n.keys)))
+	off := pageHeader
+	if n.leaf {
+		b[0] = pageLeaf
+		binary.BigEndian.PutUint32(b[3:], n.next)
+		for i, k := range n.keys {
+			binary.BigEndian.PutUint16(b[off:], uint16(len(k)))
+			binary.BigEndian.PutUint16(b[off+2:], uint16(len(n.vals[i])))
+			off += 4
+			off += copy(b[off:], k)
+			off += copy(b[off:], n.vals[i])
+		}
+		return b
+	}
+	b[0] = pageInner
+	binary.BigEndian.PutUint32(b[3:], n.children[0])
+	for i, k := range n.keys {
+		binary.BigEndian.PutUint16(b[off:], uint16(len(k)))
+		off += 2
+		off += copy(b[off:], k)
+		binary.BigEndian.PutUint32(b[off:], n.children[i+1])
+		off += 4
+	}
+	return b
+}
+
+func decodePage(id uint32, b []byte) (*pnode, error) {
+	n := &pnode{id: id}
+	count := int(binary.BigEndian.Uint16(b[1:]))
+	off := pageHeader
+	next := func(l int) ([]byte, bool) {
+		if off+l > len(b) {
+			return nil, false
+		}
+		off += l
+		return b[off-l : off : off], true
+	}
+	switch b[0] {
+	case pageLeaf:
+		n.leaf = true
+		n.next = binary.BigEndian.Uint32(b[3:])
+		n.keys, n.vals = make([][]byte, count), make([][]byte, count)
+		for i := 0; i < count; i++ {
+			h, ok := next(4)
+			if !ok {
+				return nil, ErrCorrupted
+			}
+			k, ok1 := next(int(binary.BigEndian.Uint16(h)))
+			v, ok2 := next(int(binary.BigEndian.Uint16(h[2:])))
+			if !ok1 || !ok2 {
+				return nil, ErrCorrupted
+			}
+			n.keys[i], n.vals[i] = k, v
+		}
+	case pageInner:
+		n.keys, n.children = make([][]byte, count), make([]uint32, count+1)
+		n.children[0] = binary.BigEndian.Uint32(b[3:])
+		for i := 0; i < count; i++ {
+			h, ok := next(2)
+			if !ok {
+				return nil, ErrCorrupted
+			}
+			k, ok1 := next(int(binary.BigEndian.Uint16(h)))
+			c, ok2 := next(4)
+			if !ok1 || !ok2 {
+				return nil, ErrCorrupted
+			}
+			n.keys[i], n.children[i+1] = k, binary.BigEndian.Uint32(c)
+		}
+	default:
+		return nil, ErrCorrupted
+	}
+	return n, nil
+}
+
+func (t *PagedBTree) read(id uint32) (*pnode, error) {
+	if id == 0 || id >= t.npages {
+		return nil, ErrCorrupted
+	}
+	b, err := t.pool.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return decodePage(id, b)
+}
+
+func (t *PagedBTree) write(n *pnode) {
+	t.pool.put(n.id, encodePage(n, t.pageSize))
+}
+
+// alloc allocates a page for n, from the free pages if any.
+func (t *PagedBTree) alloc(n *pnode) error {
+	if t.free == 0 {
+		n.id = t.npages
+		t.npages++
+		return nil
+	}
+	b, err := t.pool.get(t.free)
+	if err != nil {
+		return err
+	}
+	if b[0] != pageFree {
+		return ErrCorrupted
+	}
+	n.id, t.free = t.free, binary.BigEndian.Uint32(b[3:])
+	return nil
+}
+
+func (t *PagedBTree) release(id uint32) {
+	b := make([]byte, t.pageSize)
+	b[0] = pageFree
+	binary.BigEndian.PutUint32(b[3:], t.free)
+	t.pool.put(id, b)
+	t.free = id
+}
+
+// maxEntry returns the maximum size of an entry, so that a page that
+// overflows can always be split into two pages.
+func (t *PagedBTree) maxEntry() int {
+	return (t.pageSize - pageHeader) / 4
+}
+
+// search returns the position of the first key that is not less
+// than k, and whether the key equals k.
+func (n *pnode) search(k []byte) (int, bool) {
+	i := sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(n.keys[i], k) >= 0
+	})
+	return i, i < len(n.keys) && bytes.Equal(n.keys[i], k)
+}
+
+// childIndex returns the child of an internal node that covers k,
+// i.e. the child i that keys[i-1] <= k < keys[i].
+func (n *pnode) childIndex(k []byte) int {
+	return sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(k, n.keys[i]) < 0
+	})
+}
+
+// leafOf returns the leaf that covers k.
+func (t *PagedBTree) leafOf(k []byte) (*pnode, error) {
+	n, err := t.read(t.root)
+	for err == nil && !n.leaf {
+		n, err = t.read(n.children[n.childIndex(k)])
+	}
+	return n, err
+}
+
+// Get returns a copy of the value of key.
+func (t *PagedBTree) Get(key []byte) (v []byte, ok bool, err error) {
+	n, err := t.leafOf(key)
+	if err != nil {
+		return nil, false, err
+	}
+	i, found := n.search(key)
+	if !found {
+		return nil, false, nil
+	}
+	return append([]byte{}, n.vals[i]...), true, nil
+}
+
+// Put stores a copy of the value by a copy of key. An error of
+// writing back the pages that Put evicts from the buffer pool is
+// returned as well, the pages are kept in the pool and the put still
+// takes effect.
+func (t *PagedBTree) Put(key, value []byte) error {
+	if 4+len(key)+len(value) > t.maxEntry() {
+		return ErrTooLarge
+	}
+	key, value = append([]byte{}, key...), append([]byte{}, value...)
+	sep, right, err := t.insert(t.root, key, value)
+	if err != nil {
+		return err
+	}
+	if right != 0 {
+		root := &pnode{keys: [][]byte{sep}, children: []uint32{t.root, right}}
+		if err := t.alloc(root); err != nil {
+			return err
+		}
+		t.write(root)
+		t.root = root.id
+	}
+	return t.pool.takeErr()
+}
+
+// insert inserts into the subtree of id. If the node splits, it
+// returns the page of the new right node and its separator key.
+func (t *PagedBTree) insert(id uint32, key, value []byte) (sep []byte, right uint32, err error) {
+	n, err := t.read(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n.leaf {
+		i, found := n.search(key)
+		if found {
+			n.vals[i] = value
+		} else {
+			n.keys = insertAt(n.keys, i, key)
+			n.vals = insertAt(n.vals, i, value)
+			t.len++
+		}
+	} else {
+		i := n.childIndex(key)
+		csep, cright, err := t.insert(n.children[i], key, value)
+		if err != nil {
+			return nil, 0, err
+		}
+		if cright == 0 {
+			return nil, 0, nil
+		}
+		n.keys = insertAt(n.keys, i, csep)
+		n.children = insertAt(n.children, i+1, cright)
+	}
+	if n.size() <= t.pageSize {
+		t.write(n)
+		return nil, 0, nil
+	}
+	return t.split(n)
+}
+
+// split splits an overflowed node into halves of about the same size.
+func (t *PagedBTree) split(n *pnode) (sep []byte, right uint32, err error) {
+	r := &pnode{leaf: n.leaf}
+	if err := t.alloc(r); err != nil {
+		return nil, 0, err
+	}
+	if n.leaf {
+		r.next, n.next = n.next, r.id
+	}
+	sep = divide(n, r)
+	t.write(n)
+	t.write(r)
+	return sep, r.id, nil
+}
+
+// divide moves the upper half in size of n to r, which is the right
+// sibling of n, and returns the separator key of n and r.
+func divide(n, r *pnode) (sep []byte) {
+	half, m, s := n.size()/2, 0, pageHeader
+	for m < len(n.keys)-1 && s < half {
+		if n.leaf {
+			s += 4 + len(n.keys[m]) + len(n.vals[m])
+		} else {
+			s += 6 + len(n.keys[m])
+		}
+		m++
+	}
+	if n.leaf {
+		r.keys = append(r.keys[:0], n.keys[m:]...)
+		r.vals = append(r.vals[:0], n.vals[m:]...)
+		n.keys, n.vals = n.keys[:m], n.vals[:m]
+		return r.keys[0]
+	}
+	// the middle key moves up rather than to the right.
+	sep = n.keys[m]
+	r.keys = append(r.keys[:0], n.keys[m+1:]...)
+	r.children = append(r.children[:0], n.children[m+1:]...)
+	n.keys, n.children = n.keys[:m], n.children[:m+1]
+	return sep
+}
+
+// Del deletes the value of key. Like Put, it returns the error of
+// writing back the pages that it evicts from the buffer pool.
+func (t *PagedBTree) Del(key []byte) error {
+	removed, _, err := t.delete(t.root, key)
+	if err != nil {
+		return err
+	}
+	if removed {
+		t.len--
+		root, err := t.read(t.root)
+		if err != nil {
+			return err
+		}
+		if !root.leaf && len(root.keys) == 0 {
+			t.release(root.id)
+			t.root = root.children[0]
+		}
+	}
+	return t.pool.takeErr()
+}
+
+// delete deletes key from the subtree of id, and reports whether the
+// node becomes less than a quarter full.
+func (t *PagedBTree) delete(id uint32, key []byte) (removed, underflow bool, err error) {
+	n, err := t.read(id)
+	if err != nil {
+		return false, false, err
+	}
+	if n.leaf {
+		i, found := n.search(key)
+		if !found {
+			return false, false, nil
+		}
+		n.keys = removeAt(n.keys, i)
+		n.vals = removeAt(n.vals, i)
+		t.write(n)
+		return true, n.size() < t.pageSize/4, nil
+	}
+
+	i := n.childIndex(key)
+	removed, underflow, err = t.delete(n.children[i], key)
+	if err != nil || !underflow || len(n.children) < 2 {
+		return removed, false, err
+	}
+	// merge the child with a sibling if they fit in a page, otherwise
+	// redistribute their entries evenly.
+	if i == len(n.children)-1 {
+		i--
+	}
+	left, err := t.read(n.children[i])
+	if err != nil {
+		return removed, false, err
+	}
+	right, err := t.read(n.children[i+1])
+	if err != nil {
+		return removed, false, err
+	}
+	if left.leaf {
+		left.keys = append(left.keys, right.keys...)
+		left.vals = append(left.vals, right.vals...)
+	} else {
+		left.keys = append(append(left.keys, n.keys[i]), right.keys...)
+		left.children = append(left.children, right.children...)
+	}
+	if left.size() > t.pageSize {
+		// a longer separator may not fit in n, then the child is
+		// left as it is.
+		sep := divide(left, right)
+		if n.size()-len(n.keys[i])+len(sep) > t.pageSize {
+			return removed, false, nil
+		}
+		n.keys[i] = sep
+		t.write(left)
+		t.write(right)
+		t.write(n)
+		return removed, false, nil
+	}
+	if left.leaf {
+		left.next = right.next
+	}
+	t.write(left)
+	t.release(right.id)
+	n.keys = removeAt(n.keys, i)
+	n.children = removeAt(n.children, i+1)
+	t.write(n)
+	return removed, n.size() < t.pageSize/4, nil
+}
+
+// Range iterates all keys k that from <= k < to in ascending order
+// with op, a nil to means no upper bound. The slices passed to op
+// must not be retained or modified. The iteration stops if op
+// returns false.
+func (t *PagedBTree) Range(from, to []byte, op func(k, v []byte) bool) error {
+	n, err := t.leafOf(from)
+	if err != nil {
+		return err
+	}
+	i, _ := n.search(from)
+	for {
+		for ; i < len(n.keys); i++ {
+			if to != nil && bytes.Compare(n.keys[i], to) >= 0 {
+				return nil
+			}
+			if !op(n.keys[i], n.vals[i]) {
+				return nil
+			}
+		}
+		if n.next == 0 {
+			return nil
+		}
+		if n, err = t.read(n.next); err != nil {
+			return err
+		}
+		i = 0
+	}
+}
+
+// bufferPool caches the pages of a file with the LRU policy. Dirty
+// pages are written back when they are evicted or flushed.
+type bufferPool struct {
+	f        *os.File
+	pageSize int
+	cap      int
+	frames   map[uint32]*list.Element
+	lru      *list.List // of *frame, the front is the most recent
+	err      error      // the first error of a write back since taken
+}
+
+type frame struct {
+	id    uint32
+	data  []byte
+	dirty bool
+}
+
+// get returns the page id, which must not be modified.
+func (p *bufferPool) get(id uint32) ([]byte, error) {
+	if e, ok := p.frames[id]; ok {
+		p.lru.MoveToFront(e)
+		return e.Value.(*frame).data, nil
+	}
+	b := make([]byte, p.pageSize)
+	if _, err := p.f.ReadAt(b, int64(id)*int64(p.pageSize)); err != nil {
+		if err == io.EOF {
+			err = ErrCorrupted
+		}
+		return nil, err
+	}
+	p.add(&frame{id: id, data: b})
+	return b, nil
+}
+
+// put replaces the page id by b.
+func (p *bufferPool) put(id uint32, b []byte) {
+	if e, ok := p.frames[id]; ok {
+		f := e.Value.(*frame)
+		f.data, f.dirty = b, true
+		p.lru.MoveToFront(e)
+		return
+	}
+	p.add(&frame{id: id, data: b, dirty: true})
+}
+
+// add adds f to the pool and evicts pages if the pool is full. A dirty
+// page that fails to be written back stays in the pool and dirty, so
+// that it is not lost and the write back is retried by a later
+// eviction or flush.
+func (p *bufferPool) add(f *frame) {
+	p.frames[f.id] = p.lru.PushFront(f)
+	p.evict()
+}
+
+// evict evicts the least recently used pages until the pool is not
+// over its capacity, or only the pages that fail to be written back
+// remain.
+func (p *bufferPool) evict() {
+	for e := p.lru.Back(); e != nil && p.lru.Len() > p.cap; {
+		victim, prev := e.Value.(*frame), e.Prev()
+		if !victim.dirty || p.writeBack(victim) == nil {
+			p.lru.Remove(e)
+			delete(p.frames, victim.id)
+		}
+		e = prev
+	}
+}
+
+func (p *bufferPool) writeBack(f *frame) error {
+	_, err := p.f.WriteAt(f.data, int64(f.id)*int64(p.pageSize))
+	if err != nil {
+		if p.err == nil {
+			p.err = err
+		}
+		return err
+	}
+	f.dirty = false
+	return nil
+}
+
+// takeErr returns the first error of all write backs since the last
+// call, and clears it.
+func (p *bufferPool) takeErr() error {
+	err := p.err
+	p.err = nil
+	return err
+}
+
+// flush writes back all dirty pages, evicts the pages that are kept
+// over the capacity, and syncs the file. It returns
+// the first error of all write backs since the last flush.
+func (p *bufferPool) flush() error {
+	for e := p.lru.Front(); e != nil; e = e.Next() {
+		if f := e.Value.(*frame); f.dirty {
+			p.writeBack(f)
+		}
+	}
+	p.evict()
+	err := p.takeErr()
+	if serr := p.f.Sync(); err == nil {
+		err = serr
+	}
+	return err
+}
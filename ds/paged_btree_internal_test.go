@@ -0,0 +1,105 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPagedBTreeWriteBackFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btree")
+	tree, err := OpenPagedBTree(path, WithPageSize(512), WithPoolSize(4))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer tree.Close()
+
+	// a read only file fails all write backs.
+	rw := tree.pool.f
+	ro, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer ro.Close()
+	tree.pool.f = ro
+
+	failed := false
+	for i := 0; i < 500; i++ {
+		if err := tree.Put([]byte(fmt.Sprintf("key-%05d", i)), make([]byte, 32)); err != nil {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Fatalf("want a write back error")
+	}
+	dirty := 0
+	for _, e := range tree.pool.frames {
+		if e.Value.(*frame).dirty {
+			dirty++
+		}
+	}
+	if dirty <= tree.pool.cap {
+		t.Fatalf("want the pages that failed to be written back in the pool, got %v dirty pages", dirty)
+	}
+	if err := tree.Sync(); err == nil {
+		t.Fatalf("want a sync error")
+	}
+
+	tree.pool.f = rw
+	if err := tree.Sync(); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if len(tree.pool.frames) > tree.pool.cap+1 {
+		t.Fatalf("want the pool shrinks after a write back, got %v pages", len(tree.pool.frames))
+	}
+	for i := 0; i < 500; i++ {
+		k := fmt.Sprintf("key-%05d", i)
+		if _, ok, err := tree.Get([]byte(k)); !ok || err != nil {
+			t.Fatalf("get %v failed: %v, %v", k, ok, err)
+		}
+	}
+}
+
+func TestPagedBTreeRedistribute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btree")
+	tree, err := OpenPagedBTree(path, WithPageSize(512), WithPoolSize(16))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer tree.Close()
+
+	// random insertions leave the leaves partly full, then random
+	// deletions make some leaves underflow next to siblings that are
+	// too full to merge with.
+	r := rand.New(rand.NewSource(1))
+	keys := r.Perm(2000)
+	for _, i := range keys {
+		tree.Put([]byte(fmt.Sprintf("key-%05d", i)), make([]byte, 24))
+	}
+	for _, i := range keys[:1200] {
+		if err := tree.Del([]byte(fmt.Sprintf("key-%05d", i))); err != nil {
+			t.Fatalf("del failed: %v", err)
+		}
+	}
+
+	var check func(id uint32, root bool)
+	check = func(id uint32, root bool) {
+		n, err := tree.read(id)
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if !root && n.size() < tree.pageSize/4 {
+			t.Fatalf("page %v is less than a quarter full: %v", id, n.size())
+		}
+		for _, c := range n.children {
+			check(c, false)
+		}
+	}
+	check(tree.root, true)
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestPagedBTree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btree")
+	tree, err := ds.OpenPagedBTree(path, ds.WithPageSize(512), ds.WithPoolSize(4))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	want := map[string]string{}
+	for i := 0; i < 20000; i++ {
+		k := fmt.Sprintf("key-%05d", r.Intn(5000))
+		if r.Intn(3) == 0 {
+			if err := tree.Del([]byte(k)); err != nil {
+				t.Fatalf("del failed: %v", err)
+			}
+			delete(want, k)
+		} else {
+			v := fmt.Sprintf("value-%d", i)
+			if err := tree.Put([]byte(k), []byte(v)); err != nil {
+				t.Fatalf("put failed: %v", err)
+			}
+			want[k] = v
+		}
+		if tree.Len() != len(want) {
+			t.Fatalf("want len %v, got %v", len(want), tree.Len())
+		}
+	}
+	if err := tree.Put(make([]byte, 200), nil); !errors.Is(err, ds.ErrTooLarge) {
+		t.Fatalf("want %v, got %v", ds.ErrTooLarge, err)
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	// reopen with a different page size, which is ignored.
+	tree, err = ds.OpenPagedBTree(path, ds.WithPageSize(4096))
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer tree.Close()
+	if tree.Len() != len(want) {
+		t.Fatalf("want len %v after reopen, got %v", len(want), tree.Len())
+	}
+	for i := 0; i < 5000; i++ {
+		k := fmt.Sprintf("key-%05d", i)
+		v, ok, err := tree.Get([]byte(k))
+		if err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+		if wv, wok := want[k]; ok != wok || string(v) != wv {
+			t.Fatalf("get %v, want %q, got %q", k, wv, v)
+		}
+	}
+
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var got []string
+	err = tree.Range([]byte("key-01000"), []byte("key-02000"), func(k, v []byte) bool {
+		if want[string(k)] != string(v) {
+			t.Fatalf("range %s, want %q, got %q", k, want[string(k)], v)
+		}
+		got = append(got, string(k))
+		return true
+	})
+	if err != nil {
+		t.Fatalf("range failed: %v", err)
+	}
+	lo, hi := sort.SearchStrings(keys, "key-01000"), sort.SearchStrings(keys, "key-02000")
+	if len(got) != hi-lo || (len(got) > 0 && got[0] != keys[lo]) {
+		t.Fatalf("range, want %v keys, got %v", hi-lo, len(got))
+	}
+}
+
+func TestPagedBTreeReusePages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btree")
+	tree, err := ds.OpenPagedBTree(path, ds.WithPageSize(512))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer tree.Close()
+
+	fill := func() {
+		for i := 0; i < 2000; i++ {
+			tree.Put([]byte(fmt.Sprint(i)), bytes.Repeat([]byte{'x'}, 32))
+		}
+	}
+	fill()
+	tree.Sync()
+	st, _ := os.Stat(path)
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 2000; i++ {
+			if err := tree.Del([]byte(fmt.Sprint(i))); err != nil {
+				t.Fatalf("del failed: %v", err)
+			}
+		}
+		if tree.Len() != 0 {
+			t.Fatalf("want empty tree, got %v", tree.Len())
+		}
+		fill()
+	}
+	tree.Sync()
+	if st2, _ := os.Stat(path); st2.Size() > st.Size()*3/2 {
+		t.Fatalf("freed pages are not reused: %v, then %v", st.Size(), st2.Size())
+	}
+}
+
+func TestPagedBTreeCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btree")
+	os.WriteFile(path, []byte("not a btree"), 0o644)
+	if _, err := ds.OpenPagedBTree(path); !errors.Is(err, ds.ErrCorrupted) {
+		t.Fatalf("want %v, got %v", ds.ErrCorrupted, err)
+	}
+}
+
+func TestPagedBTreeRangeAfterDelete(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		path := filepath.Join(t.TempDir(), "btree")
+		tree, err := ds.OpenPagedBTree(path, ds.WithPageSize(512), ds.WithPoolSize(4))
+		if err != nil {
+			t.Fatalf("open failed: %v", err)
+		}
+
+		r := rand.New(rand.NewSource(seed))
+		for i := 0; i < 2000; i++ {
+			k := []byte(fmt.Sprintf("key-%04d", r.Intn(400)))
+			if r.Intn(2) == 0 {
+				err = tree.Del(k)
+			} else {
+				err = tree.Put(k, bytes.Repeat([]byte{'v'}, r.Intn(40)))
+			}
+			if err != nil {
+				t.Fatalf("seed %v: op %v failed: %v", seed, i, err)
+			}
+			n := 0
+			if err := tree.Range(nil, nil, func(k, v []byte) bool {
+				n++
+				return true
+			}); err != nil {
+				t.Fatalf("range failed: %v", err)
+			}
+			if n != tree.Len() {
+				t.Fatalf("seed %v: op %v: range visits %v keys, want %v", seed, i, n, tree.Len())
+			}
+		}
+		tree.Close()
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// PairingHeap implements a pairing heap. Push and Merge are O(1),
+// Pop and Remove are amortized O(log n), and DecreaseKey is amortized
+// o(log n), which makes it faster than a binary heap on workloads that
+// are dominated by decrease-key, e.g. Dijkstra's shortest paths.
+// Paper: Fredman, Michael L. et al. (1986). "The pairing heap: A new
+// form of self-adjusting heap". Algorithmica 1 (1): 111–129
+type PairingHeap[T any] struct {
+	root *PairingHeapNode[T]
+	len  int
+	less func(a, b T) bool
+}
+
+// PairingHeapNode is a handle of a value in a PairingHeap. It
+// can be used for DecreaseKey and Remove.
+type PairingHeapNode[T any] struct {
+	v       T
+	child   *PairingHeapNode[T]
+	sibling *PairingHeapNode[T]
+	prev    *PairingHeapNode[T] // parent if first child, else previous sibling
+}
+
+// Value returns the value of the node.
+func (n *PairingHeapNode[T]) Value() T {
+	return n.v
+}
+
+// NewPairingHeap creates a pairing heap.
+func NewPairingHeap[T any](less func(a, b T) bool) *PairingHeap[T] {
+	return &PairingHeap[T]{less: less}
+}
+
+// Len returns the number of elements in the heap.
+func (h *PairingHeap[T]) Len() int {
+	return h.len
+}
+
+// Push pushes x into the heap and returns its handle.
+func (h *PairingHeap[T]) Push(x T) *PairingHeapNode[T] {
+	n := &PairingHeapNode[T]{v: x}
+	h.root = h.meld(h.root, n)
+	h.len++
+	return n
+}
+
+// Peek returns the least element without removing it.
+// It returns false if the heap is empty.
+func (h *PairingHeap[T]) Peek() (x T, ok bool) {
+	if h.root == nil {
+		return
+	}
+	return h.root.v, true
+}
+
+// Pop removes and returns the least element.
+// It returns false if the heap is empty.
+func (h *PairingHeap[T]) Pop() (x T, ok bool) {
+	if h.root == nil {
+		return
+	}
+	n := h.root
+	h.root = h.pair(n.child)
+	n.child = nil
+	h.len--
+	return n.v, true
+}
+
+// DecreaseKey replaces the value of n by x, which must not be greater
+// than the current value. n must belong to h.
+func (h *PairingHeap[T]) DecreaseKey(n *PairingHeapNode[T], x T) {
+	if h.less(n.v, x) {
+		panic("ds: new value is greater than the current value")
+	}
+	n.v = x
+	if n == h.root {
+		return
+	}
+	h.detach(n)
+	h.root = h.meld(h.root, n)
+}
+
+// Remove removes n from the heap. n must belong to h.
+func (h *PairingHeap[T]) Remove(n *PairingHeapNode[T]) {
+	if n == h.root {
+		h.Pop()
+		return
+	}
+	h.detach(n)
+	sub := h.pair(n.child)
+	n.child = nil
+	h.root = h.meld(h.root, sub)
+	h.len--
+}
+
+// Merge moves all elements of o into h, o becomes empty.
+func (h *PairingHeap[T]) Merge(o *PairingHeap[T]) {
+	h.root = h.meld(h.root, o.root)
+	h.len += o.len
+	o.root, o.len = nil, 0
+}
+
+// detach detaches the subtree of n from its parent.
+func (h *PairingHeap[T]) detach(n *PairingHeapNode[T]) {
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+}
+
+// meld links two roots, the greater root becomes the first child of
+// the lesser one.
+func (h *PairingHeap[T]) meld(a, b *PairingHeapNode[T]) *PairingHeapNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.less(b.v, a.v) {
+		a, b = b, a
+	}
+	b.prev = a
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	a.prev, a.sibling = nil, nil
+	return a
+}
+
+// pair melds a list of siblings into a single root by the two-pass
+// pairing: siblings are melded in pairs from left to right, then the
+// pairs are melded from right to left.
+func (h *PairingHeap[T]) pair(first *PairingHeapNode[T]) *PairingHeapNode[T] {
+	var pairs *PairingHeapNode[T] // linked by sibling in reverse order
+	for first != nil {
+		a, b := first, first.sibling
+		if b != nil {
+			first = b.sibling
+			b.prev, b.sibling = nil, nil
+		} else {
+			first = nil
+		}
+		a.prev, a.sibling = nil, nil
+		m := h.meld(a, b)
+		m.sibling = pairs
+		pairs = m
+	}
+
+	var root *PairingHeapNode[T]
+	for pairs != nil {
+		next := pairs.sibling
+		pairs.sibling = nil
+		root = h.meld(root, pairs)
+		pairs = next
+	}
+	return root
+}
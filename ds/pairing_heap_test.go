@@ -0,0 +1,75 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestPairingHeap(t *testing.T) {
+	h := ds.NewPairingHeap(intLess)
+	if _, ok := h.Pop(); ok {
+		t.Fatalf("pop empty heap succeeded")
+	}
+
+	nodes := []*ds.PairingHeapNode[int]{}
+	for i := 0; i < 1000; i++ {
+		nodes = append(nodes, h.Push(rand.Intn(10000)))
+	}
+	// decrease half of the keys, and remove a quarter of the nodes
+	for _, i := range rand.Perm(len(nodes))[:500] {
+		h.DecreaseKey(nodes[i], nodes[i].Value()-rand.Intn(10000))
+	}
+	removed := map[int]bool{}
+	for _, i := range rand.Perm(len(nodes))[:250] {
+		h.Remove(nodes[i])
+		removed[i] = true
+	}
+
+	o := ds.NewPairingHeap(intLess)
+	for i := 0; i < 100; i++ {
+		nodes = append(nodes, o.Push(rand.Intn(10000)))
+	}
+	h.Merge(o)
+	if o.Len() != 0 {
+		t.Fatalf("merged heap is not empty: %v", o.Len())
+	}
+
+	want := []int{}
+	for i, n := range nodes {
+		if !removed[i] {
+			want = append(want, n.Value())
+		}
+	}
+	sort.Ints(want)
+	if h.Len() != len(want) {
+		t.Fatalf("want %v, got %v", len(want), h.Len())
+	}
+	if v, ok := h.Peek(); !ok || v != want[0] {
+		t.Fatalf("peek: want %v, got %v", want[0], v)
+	}
+	for _, w := range want {
+		if v, ok := h.Pop(); !ok || v != w {
+			t.Fatalf("pop: want %v, got %v", w, v)
+		}
+	}
+	if _, ok := h.Peek(); ok {
+		t.Fatalf("peek empty heap succeeded")
+	}
+}
+
+func TestPairingHeap_DecreaseKeyPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("increase key does not panic")
+		}
+	}()
+	h := ds.NewPairingHeap(intLess)
+	h.DecreaseKey(h.Push(1), 2)
+}
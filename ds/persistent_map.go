@@ -0,0 +1,254 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import "math/bits"
+
+// PersistentMap is an immutable hash map. Updates return a new version
+// of the map and leave the old version valid, the versions share the
+// unchanged parts of their structure.
+//
+// It is a hash array mapped trie that consumes 5 bits of the hash per
+// level, all operations are O(log32 n).
+// Paper: Bagwell, Phil (2001). "Ideal Hash Trees". EPFL Technical Report
+type PersistentMap[K comparable, V any] struct {
+	root *hamtnode[K, V]
+	len  int
+	hash func(K) uint64
+}
+
+type hamtnode[K comparable, V any] struct {
+	bitmap uint32
+	slots  []hamtslot[K, V]
+}
+
+// hamtslot is either a sub-trie, or a leaf of the entries that share
+// the same hash.
+type hamtslot[K comparable, V any] struct {
+	node *hamtnode[K, V]
+	hash uint64
+	kvs  []hamtkv[K, V]
+}
+
+type hamtkv[K comparable, V any] struct {
+	k K
+	v V
+}
+
+// NewPersistentMap returns an empty persistent map. If hash is nil,
+// keys are hashed by their fmt representation, which is correct but slow.
+func NewPersistentMap[K comparable, V any](hash func(K) uint64) *PersistentMap[K, V] {
+	if hash == nil {
+		hash = fmtHasher[K]()
+	}
+	return &PersistentMap[K, V]{hash: hash}
+}
+
+// Len returns the number of entries in the map.
+func (m *PersistentMap[K, V]) Len() int {
+	return m.len
+}
+
+func hamtIndex(h uint64, shift uint) (bit uint32) {
+	return 1 << ((h >> shift) & 31)
+}
+
+func (n *hamtnode[K, V]) pos(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// Get returns the value of k.
+func (m *PersistentMap[K, V]) Get(k K) (v V, ok bool) {
+	h := m.hash(k)
+	n := m.root
+	for shift := uint(0); n != nil; shift += 5 {
+		bit := hamtIndex(h, shift)
+		if n.bitmap&bit == 0 {
+			return
+		}
+		s := n.slots[n.pos(bit)]
+		if s.node != nil {
+			n = s.node
+			continue
+		}
+		if s.hash != h {
+			return
+		}
+		for _, kv := range s.kvs {
+			if kv.k == k {
+				return kv.v, true
+			}
+		}
+		return
+	}
+	return
+}
+
+// Put returns a new version of the map that stores v by k.
+func (m *PersistentMap[K, V]) Put(k K, v V) *PersistentMap[K, V] {
+	root, added := m.put(m.root, m.hash(k), 0, k, v)
+	n := *m
+	n.root = root
+	if added {
+		n.len++
+	}
+	return &n
+}
+
+func (m *PersistentMap[K, V]) put(n *hamtnode[K, V], h uint64, shift uint, k K, v V) (*hamtnode[K, V], bool) {
+	leaf := hamtslot[K, V]{hash: h, kvs: []hamtkv[K, V]{{k, v}}}
+	if n == nil {
+		return &hamtnode[K, V]{bitmap: hamtIndex(h, shift), slots: []hamtslot[K, V]{leaf}}, true
+	}
+
+	bit := hamtIndex(h, shift)
+	pos := n.pos(bit)
+	if n.bitmap&bit == 0 {
+		c := &hamtnode[K, V]{bitmap: n.bitmap | bit, slots: make([]hamtslot[K, V], len(n.slots)+1)}
+		copy(c.slots, n.slots[:pos])
+		c.slots[pos] = leaf
+		copy(c.slots[pos+1:], n.slots[pos:])
+		return c, true
+	}
+
+	s := n.slots[pos]
+	added := true
+	switch {
+	case s.node != nil:
+		s.node, added = m.put(s.node, h, shift+5, k, v)
+	case s.hash == h:
+		kvs := append([]hamtkv[K, V](nil), s.kvs...)
+		for i := range kvs {
+			if kvs[i].k == k {
+				kvs[i].v = v
+				added = false
+				break
+			}
+		}
+		if added {
+			kvs = append(kvs, hamtkv[K, V]{k, v})
+		}
+		s.kvs = kvs
+	default:
+		s = hamtslot[K, V]{node: mergeHAMTLeaves(shift+5, s, leaf)}
+	}
+	c := n.clone()
+	c.slots[pos] = s
+	return c, added
+}
+
+// mergeHAMTLeaves creates a sub-trie of two leaves of different hashes.
+func mergeHAMTLeaves[K comparable, V any](shift uint, a, b hamtslot[K, V]) *hamtnode[K, V] {
+	ba, bb := hamtIndex(a.hash, shift), hamtIndex(b.hash, shift)
+	if ba == bb {
+		return &hamtnode[K, V]{
+			bitmap: ba,
+			slots:  []hamtslot[K, V]{{node: mergeHAMTLeaves(shift+5, a, b)}},
+		}
+	}
+	if bb < ba {
+		a, b = b, a
+	}
+	return &hamtnode[K, V]{bitmap: ba | bb, slots: []hamtslot[K, V]{a, b}}
+}
+
+// Del returns a new version of the map that k is deleted.
+func (m *PersistentMap[K, V]) Del(k K) *PersistentMap[K, V] {
+	root, removed := m.del(m.root, m.hash(k), 0, k)
+	if !removed {
+		return m
+	}
+	n := *m
+	n.root = root
+	n.len--
+	return &n
+}
+
+func (m *PersistentMap[K, V]) del(n *hamtnode[K, V], h uint64, shift uint, k K) (*hamtnode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	bit := hamtIndex(h, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	pos := n.pos(bit)
+	s := n.slots[pos]
+	switch {
+	case s.node != nil:
+		child, removed := m.del(s.node, h, shift+5, k)
+		if !removed {
+			return n, false
+		}
+		switch {
+		case child == nil:
+			return n.without(bit, pos), true
+		case len(child.slots) == 1 && child.slots[0].node == nil:
+			s = child.slots[0] // collapse a single leaf into its parent
+		default:
+			s.node = child
+		}
+	case s.hash == h:
+		i := 0
+		for ; i < len(s.kvs) && s.kvs[i].k != k; i++ {
+		}
+		if i == len(s.kvs) {
+			return n, false
+		}
+		if len(s.kvs) == 1 {
+			return n.without(bit, pos), true
+		}
+		kvs := make([]hamtkv[K, V], 0, len(s.kvs)-1)
+		kvs = append(kvs, s.kvs[:i]...)
+		s.kvs = append(kvs, s.kvs[i+1:]...)
+	default:
+		return n, false
+	}
+	c := n.clone()
+	c.slots[pos] = s
+	return c, true
+}
+
+// without returns a copy of n without the slot at pos, or nil if
+// the copy is empty.
+func (n *hamtnode[K, V]) without(bit uint32, pos int) *hamtnode[K, V] {
+	if len(n.slots) == 1 {
+		return nil
+	}
+	c := &hamtnode[K, V]{bitmap: n.bitmap &^ bit, slots: make([]hamtslot[K, V], 0, len(n.slots)-1)}
+	c.slots = append(c.slots, n.slots[:pos]...)
+	c.slots = append(c.slots, n.slots[pos+1:]...)
+	return c
+}
+
+func (n *hamtnode[K, V]) clone() *hamtnode[K, V] {
+	return &hamtnode[K, V]{bitmap: n.bitmap, slots: append([]hamtslot[K, V](nil), n.slots...)}
+}
+
+// Range iterates all entries in an unspecified order with op.
+// The iteration stops if op returns false.
+func (m *PersistentMap[K, V]) Range(op func(k K, v V) bool) {
+	m.root.each(op)
+}
+
+func (n *hamtnode[K, V]) each(op func(k K, v V) bool) bool {
+	if n == nil {
+		return true
+	}
+	for _, s := range n.slots {
+		if s.node != nil {
+			if !s.node.each(op) {
+				return false
+			}
+			continue
+		}
+		for _, kv := range s.kvs {
+			if !op(kv.k, kv.v) {
+				return false
+			}
+		}
+	}
+	return true
+}
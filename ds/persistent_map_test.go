@@ -0,0 +1,97 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func checkMap(t *testing.T, m *ds.PersistentMap[int, int], want map[int]int) {
+	t.Helper()
+	if m.Len() != len(want) {
+		t.Fatalf("len: want %v, got %v", len(want), m.Len())
+	}
+	for k, w := range want {
+		if v, ok := m.Get(k); !ok || v != w {
+			t.Fatalf("get %v: want %v, got %v", k, w, v)
+		}
+	}
+	n := 0
+	m.Range(func(k, v int) bool {
+		if want[k] != v {
+			t.Fatalf("range %v: want %v, got %v", k, want[k], v)
+		}
+		n++
+		return true
+	})
+	if n != len(want) {
+		t.Fatalf("range visits %v entries, want %v", n, len(want))
+	}
+}
+
+func TestPersistentMap(t *testing.T) {
+	hashes := map[string]func(int) uint64{
+		"identity": func(k int) uint64 { return uint64(k) },
+		"collide":  func(k int) uint64 { return uint64(k % 7) },
+		"fmt":      nil,
+	}
+	for name, hash := range hashes {
+		t.Run(name, func(t *testing.T) {
+			m := ds.NewPersistentMap[int, int](hash)
+			want := map[int]int{}
+			type version struct {
+				m    *ds.PersistentMap[int, int]
+				want map[int]int
+			}
+			versions := []version{}
+			for i := 0; i < 3000; i++ {
+				k := rand.Intn(1000)
+				if rand.Intn(3) == 0 {
+					m = m.Del(k)
+					delete(want, k)
+				} else {
+					m = m.Put(k, i)
+					want[k] = i
+				}
+				if _, ok := m.Get(-1); ok {
+					t.Fatalf("get absent key succeeded")
+				}
+				if i%500 == 0 {
+					snapshot := map[int]int{}
+					for k, v := range want {
+						snapshot[k] = v
+					}
+					versions = append(versions, version{m, snapshot})
+				}
+			}
+			checkMap(t, m, want)
+			for _, v := range versions {
+				checkMap(t, v.m, v.want)
+			}
+			for k := range want {
+				m = m.Del(k)
+			}
+			checkMap(t, m, map[int]int{})
+		})
+	}
+}
+
+func BenchmarkPersistentMap_Put(b *testing.B) {
+	for _, size := range []int{1 << 10, 1 << 16} {
+		b.Run(fmt.Sprintf("size-%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			m := ds.NewPersistentMap[int, int](func(k int) uint64 {
+				return uint64(k) * 0x9e3779b97f4a7c15
+			})
+			for i := 0; i < b.N; i++ {
+				m = m.Put(i%size, i)
+			}
+		})
+	}
+}
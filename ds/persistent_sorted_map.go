@@ -0,0 +1,305 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// PersistentSortedMap is an immutable sorted map. Updates return a new
+// version of the map and leave the old version valid, the versions
+// share the unchanged parts of their structure.
+//
+// It is a red-black tree with path copying, which keeps the same
+// invariants as RBTree but rebalances without parent pointers, so
+// that all operations are O(log n). It cannot share the nodes and the
+// rotations of RBTree: a node is shared by many versions, each of
+// which may give it a different parent, and the rotations of RBTree
+// relink the parent pointers in place. Instead, the copied path is
+// rebuilt bottom up with the rebalanced nodes, only the colors are
+// shared with RBTree.
+// Paper: Kahrs, Stefan (2001). "Red-black trees with types". Journal
+// of Functional Programming 11 (4): 425–432
+type PersistentSortedMap[K any, V any] struct {
+	root *prbnode[K, V]
+	len  int
+	less func(a, b K) bool
+}
+
+// prbnode is a node of PersistentSortedMap, it is never modified once
+// it is reachable from a version.
+type prbnode[K any, V any] struct {
+	c           color
+	left, right *prbnode[K, V]
+	k           K
+	v           V
+}
+
+func (n *prbnode[K, V]) color() color {
+	if n == nil {
+		return black
+	}
+	return n.c
+}
+
+func (n *prbnode[K, V]) isRed() bool {
+	return n.color() == red
+}
+
+func newPRBNode[K, V any](c color, l *prbnode[K, V], k K, v V, r *prbnode[K, V]) *prbnode[K, V] {
+	return &prbnode[K, V]{c: c, left: l, k: k, v: v, right: r}
+}
+
+// NewPersistentSortedMap returns an empty persistent sorted map.
+func NewPersistentSortedMap[K any, V any](less func(a, b K) bool) *PersistentSortedMap[K, V] {
+	return &PersistentSortedMap[K, V]{less: less}
+}
+
+// Len returns the number of entries in the map.
+func (m *PersistentSortedMap[K, V]) Len() int {
+	return m.len
+}
+
+func (m *PersistentSortedMap[K, V]) find(k K) *prbnode[K, V] {
+	n := m.root
+	for n != nil {
+		switch {
+		case m.less(k, n.k):
+			n = n.left
+		case m.less(n.k, k):
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// Get returns the value of k.
+func (m *PersistentSortedMap[K, V]) Get(k K) (v V, ok bool) {
+	n := m.find(k)
+	if n == nil {
+		return
+	}
+	return n.v, true
+}
+
+// Put returns a new version of the map that stores v by k.
+func (m *PersistentSortedMap[K, V]) Put(k K, v V) *PersistentSortedMap[K, V] {
+	n := *m
+	if m.find(k) == nil {
+		n.len++
+	}
+	n.root = blacken(m.ins(m.root, k, v))
+	return &n
+}
+
+func (m *PersistentSortedMap[K, V]) ins(n *prbnode[K, V], k K, v V) *prbnode[K, V] {
+	if n == nil {
+		return newPRBNode(red, nil, k, v, nil)
+	}
+	switch {
+	case m.less(k, n.k):
+		if n.c == black {
+			return prbBalance(m.ins(n.left, k, v), n.k, n.v, n.right)
+		}
+		return newPRBNode(red, m.ins(n.left, k, v), n.k, n.v, n.right)
+	case m.less(n.k, k):
+		if n.c == black {
+			return prbBalance(n.left, n.k, n.v, m.ins(n.right, k, v))
+		}
+		return newPRBNode(red, n.left, n.k, n.v, m.ins(n.right, k, v))
+	default:
+		return newPRBNode(n.c, n.left, k, v, n.right)
+	}
+}
+
+// Del returns a new version of the map that k is deleted.
+func (m *PersistentSortedMap[K, V]) Del(k K) *PersistentSortedMap[K, V] {
+	if m.find(k) == nil {
+		return m
+	}
+	n := *m
+	n.len--
+	n.root = blacken(m.del(m.root, k))
+	return &n
+}
+
+func (m *PersistentSortedMap[K, V]) del(n *prbnode[K, V], k K) *prbnode[K, V] {
+	switch {
+	case n == nil:
+		return nil
+	case m.less(k, n.k):
+		if n.left.color() == black {
+			return prbBalLeft(m.del(n.left, k), n.k, n.v, n.right)
+		}
+		return newPRBNode(red, m.del(n.left, k), n.k, n.v, n.right)
+	case m.less(n.k, k):
+		if n.right.color() == black {
+			return prbBalRight(n.left, n.k, n.v, m.del(n.right, k))
+		}
+		return newPRBNode(red, n.left, n.k, n.v, m.del(n.right, k))
+	default:
+		return prbFuse(n.left, n.right)
+	}
+}
+
+func blacken[K, V any](n *prbnode[K, V]) *prbnode[K, V] {
+	if n == nil || n.c == black {
+		return n
+	}
+	return newPRBNode(black, n.left, n.k, n.v, n.right)
+}
+
+// prbBalance builds a black node of l, k, v and r, and fixes a red
+// node that has a red child below it.
+func prbBalance[K, V any](l *prbnode[K, V], k K, v V, r *prbnode[K, V]) *prbnode[K, V] {
+	switch {
+	case l.isRed() && r.isRed():
+		return newPRBNode(red, blacken(l), k, v, blacken(r))
+	case l.isRed() && l.left.isRed():
+		return newPRBNode(red, blacken(l.left), l.k, l.v, newPRBNode(black, l.right, k, v, r))
+	case l.isRed() && l.right.isRed():
+		return newPRBNode(red,
+			newPRBNode(black, l.left, l.k, l.v, l.right.left), l.right.k, l.right.v,
+			newPRBNode(black, l.right.right, k, v, r))
+	case r.isRed() && r.right.isRed():
+		return newPRBNode(red, newPRBNode(black, l, k, v, r.left), r.k, r.v, blacken(r.right))
+	case r.isRed() && r.left.isRed():
+		return newPRBNode(red,
+			newPRBNode(black, l, k, v, r.left.left), r.left.k, r.left.v,
+			newPRBNode(black, r.left.right, r.k, r.v, r.right))
+	default:
+		return newPRBNode(black, l, k, v, r)
+	}
+}
+
+// redden turns a black node with black children into red, it is used
+// when the black height of the other side has been reduced by one.
+func redden[K, V any](n *prbnode[K, V]) *prbnode[K, V] {
+	if n == nil || n.c != black {
+		panic("ds: invariant violation of red-black tree")
+	}
+	return newPRBNode(red, n.left, n.k, n.v, n.right)
+}
+
+// prbBalLeft rebalances a node whose left subtree lost one black height.
+func prbBalLeft[K, V any](l *prbnode[K, V], k K, v V, r *prbnode[K, V]) *prbnode[K, V] {
+	switch {
+	case l.isRed():
+		return newPRBNode(red, blacken(l), k, v, r)
+	case r.color() == black && r != nil:
+		return prbBalance(l, k, v, redden(r))
+	case r.isRed() && r.left.color() == black && r.left != nil:
+		return newPRBNode(red,
+			newPRBNode(black, l, k, v, r.left.left), r.left.k, r.left.v,
+			prbBalance(r.left.right, r.k, r.v, redden(r.right)))
+	default:
+		panic("ds: invariant violation of red-black tree")
+	}
+}
+
+// prbBalRight rebalances a node whose right subtree lost one black height.
+func prbBalRight[K, V any](l *prbnode[K, V], k K, v V, r *prbnode[K, V]) *prbnode[K, V] {
+	switch {
+	case r.isRed():
+		return newPRBNode(red, l, k, v, blacken(r))
+	case l.color() == black && l != nil:
+		return prbBalance(redden(l), k, v, r)
+	case l.isRed() && l.right.color() == black && l.right != nil:
+		return newPRBNode(red,
+			prbBalance(redden(l.left), l.k, l.v, l.right.left), l.right.k, l.right.v,
+			newPRBNode(black, l.right.right, k, v, r))
+	default:
+		panic("ds: invariant violation of red-black tree")
+	}
+}
+
+// prbFuse joins two subtrees of a deleted node.
+func prbFuse[K, V any](l, r *prbnode[K, V]) *prbnode[K, V] {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.isRed() && r.isRed():
+		m := prbFuse(l.right, r.left)
+		if m.isRed() {
+			return newPRBNode(red,
+				newPRBNode(red, l.left, l.k, l.v, m.left), m.k, m.v,
+				newPRBNode(red, m.right, r.k, r.v, r.right))
+		}
+		return newPRBNode(red, l.left, l.k, l.v, newPRBNode(red, m, r.k, r.v, r.right))
+	case l.color() == black && r.color() == black:
+		m := prbFuse(l.right, r.left)
+		if m.isRed() {
+			return newPRBNode(red,
+				newPRBNode(black, l.left, l.k, l.v, m.left), m.k, m.v,
+				newPRBNode(black, m.right, r.k, r.v, r.right))
+		}
+		return prbBalLeft(l.left, l.k, l.v, newPRBNode(black, m, r.k, r.v, r.right))
+	case r.isRed():
+		return newPRBNode(red, prbFuse(l, r.left), r.k, r.v, r.right)
+	default: // l is red
+		return newPRBNode(red, l.left, l.k, l.v, prbFuse(l.right, r))
+	}
+}
+
+// Min returns the smallest key and its value.
+// It returns false if the map is empty.
+func (m *PersistentSortedMap[K, V]) Min() (k K, v V, ok bool) {
+	n := m.root
+	if n == nil {
+		return
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.k, n.v, true
+}
+
+// Max returns the largest key and its value.
+// It returns false if the map is empty.
+func (m *PersistentSortedMap[K, V]) Max() (k K, v V, ok bool) {
+	n := m.root
+	if n == nil {
+		return
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.k, n.v, true
+}
+
+// Range iterates all keys k that from <= k < to in ascending order
+// with op. The iteration stops if op returns false.
+func (m *PersistentSortedMap[K, V]) Range(from, to K, op func(k K, v V) bool) {
+	m.rangeNode(m.root, from, to, op)
+}
+
+func (m *PersistentSortedMap[K, V]) rangeNode(n *prbnode[K, V], from, to K, op func(k K, v V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if m.less(from, n.k) && !m.rangeNode(n.left, from, to, op) {
+		return false
+	}
+	if !m.less(n.k, from) && m.less(n.k, to) && !op(n.k, n.v) {
+		return false
+	}
+	if m.less(n.k, to) {
+		return m.rangeNode(n.right, from, to, op)
+	}
+	return true
+}
+
+// Each iterates all entries in ascending order with op.
+// The iteration stops if op returns false.
+func (m *PersistentSortedMap[K, V]) Each(op func(k K, v V) bool) {
+	m.each(m.root, op)
+}
+
+func (m *PersistentSortedMap[K, V]) each(n *prbnode[K, V], op func(k K, v V) bool) bool {
+	if n == nil {
+		return true
+	}
+	return m.each(n.left, op) && op(n.k, n.v) && m.each(n.right, op)
+}
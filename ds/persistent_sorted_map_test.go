@@ -0,0 +1,110 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func checkSortedMap(t *testing.T, m *ds.PersistentSortedMap[int, int], want map[int]int) {
+	t.Helper()
+	if m.Len() != len(want) {
+		t.Fatalf("len: want %v, got %v", len(want), m.Len())
+	}
+	keys := []int{}
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	i := 0
+	m.Each(func(k, v int) bool {
+		if k != keys[i] || v != want[k] {
+			t.Fatalf("each: want %v:%v, got %v:%v", keys[i], want[keys[i]], k, v)
+		}
+		i++
+		return true
+	})
+	if i != len(keys) {
+		t.Fatalf("each visits %v entries, want %v", i, len(keys))
+	}
+	if len(keys) == 0 {
+		return
+	}
+	if k, _, _ := m.Min(); k != keys[0] {
+		t.Fatalf("min: want %v, got %v", keys[0], k)
+	}
+	if k, _, _ := m.Max(); k != keys[len(keys)-1] {
+		t.Fatalf("max: want %v, got %v", keys[len(keys)-1], k)
+	}
+}
+
+func TestPersistentSortedMap(t *testing.T) {
+	m := ds.NewPersistentSortedMap[int, int](intLess)
+	if _, _, ok := m.Min(); ok {
+		t.Fatalf("min of empty map succeeded")
+	}
+	if _, _, ok := m.Max(); ok {
+		t.Fatalf("max of empty map succeeded")
+	}
+
+	want := map[int]int{}
+	var snapshot *ds.PersistentSortedMap[int, int]
+	var snapshotWant map[int]int
+	for i := 0; i < 5000; i++ {
+		k := rand.Intn(1000)
+		if rand.Intn(3) == 0 {
+			m = m.Del(k)
+			delete(want, k)
+		} else {
+			m = m.Put(k, i)
+			want[k] = i
+		}
+		if i == 2500 {
+			snapshot = m
+			snapshotWant = map[int]int{}
+			for k, v := range want {
+				snapshotWant[k] = v
+			}
+		}
+	}
+	checkSortedMap(t, m, want)
+	checkSortedMap(t, snapshot, snapshotWant)
+	for k, w := range want {
+		if v, ok := m.Get(k); !ok || v != w {
+			t.Fatalf("get %v: want %v, got %v", k, w, v)
+		}
+	}
+
+	got := []int{}
+	m.Range(100, 200, func(k, v int) bool {
+		got = append(got, k)
+		return true
+	})
+	expect := []int{}
+	for k := range want {
+		if k >= 100 && k < 200 {
+			expect = append(expect, k)
+		}
+	}
+	sort.Ints(expect)
+	if len(got) != len(expect) {
+		t.Fatalf("range: want %v, got %v", expect, got)
+	}
+	for i := range got {
+		if got[i] != expect[i] {
+			t.Fatalf("range: want %v, got %v", expect, got)
+		}
+	}
+
+	for _, k := range rand.Perm(1000) {
+		m = m.Del(k)
+	}
+	checkSortedMap(t, m, map[int]int{})
+	checkSortedMap(t, snapshot, snapshotWant)
+}
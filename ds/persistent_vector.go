@@ -0,0 +1,217 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+const (
+	pvBits  = 5
+	pvWidth = 1 << pvBits
+	pvMask  = pvWidth - 1
+)
+
+// PersistentVector is an immutable vector. Updates return a new
+// version of the vector and leave the old version valid, the versions
+// share the unchanged parts of their structure.
+//
+// It is a 32-way trie of values with a tail buffer, as introduced by
+// Clojure. Get and Set are O(log32 n), Append and Pop are amortized O(1).
+type PersistentVector[T any] struct {
+	len   int
+	shift uint
+	root  *pvnode[T]
+	tail  []T
+}
+
+type pvnode[T any] struct {
+	children []*pvnode[T] // internal nodes
+	values   []T          // leaves
+}
+
+// NewPersistentVector returns an empty persistent vector.
+func NewPersistentVector[T any]() *PersistentVector[T] {
+	return &PersistentVector[T]{shift: pvBits, root: &pvnode[T]{}}
+}
+
+// Len returns the length of the vector.
+func (pv *PersistentVector[T]) Len() int {
+	return pv.len
+}
+
+// tailoff returns the index of the first element in the tail.
+func (pv *PersistentVector[T]) tailoff() int {
+	if pv.len < pvWidth {
+		return 0
+	}
+	return ((pv.len - 1) >> pvBits) << pvBits
+}
+
+// leaf returns the leaf that holds the i-th element.
+func (pv *PersistentVector[T]) leaf(i int) []T {
+	if i >= pv.tailoff() {
+		return pv.tail
+	}
+	n := pv.root
+	for level := pv.shift; level > 0; level -= pvBits {
+		n = n.children[(i>>level)&pvMask]
+	}
+	return n.values
+}
+
+// Get returns the i-th element of the vector.
+// It returns false if i is out of range.
+func (pv *PersistentVector[T]) Get(i int) (v T, ok bool) {
+	if i < 0 || i >= pv.len {
+		return
+	}
+	return pv.leaf(i)[i&pvMask], true
+}
+
+// Set returns a new version of the vector that its i-th element is v.
+// It panics if i is out of range.
+func (pv *PersistentVector[T]) Set(i int, v T) *PersistentVector[T] {
+	if i < 0 || i >= pv.len {
+		panic("ds: index out of range")
+	}
+	n := *pv
+	if i >= pv.tailoff() {
+		n.tail = append([]T(nil), pv.tail...)
+		n.tail[i&pvMask] = v
+		return &n
+	}
+	n.root = pv.set(pv.shift, pv.root, i, v)
+	return &n
+}
+
+func (pv *PersistentVector[T]) set(level uint, n *pvnode[T], i int, v T) *pvnode[T] {
+	c := n.clone()
+	if level == 0 {
+		c.values[i&pvMask] = v
+		return c
+	}
+	idx := (i >> level) & pvMask
+	c.children[idx] = pv.set(level-pvBits, n.children[idx], i, v)
+	return c
+}
+
+// Append returns a new version of the vector that v is appended.
+func (pv *PersistentVector[T]) Append(v T) *PersistentVector[T] {
+	n := *pv
+	n.len++
+	if pv.len-pv.tailoff() < pvWidth {
+		n.tail = make([]T, len(pv.tail)+1, pvWidth)
+		copy(n.tail, pv.tail)
+		n.tail[len(pv.tail)] = v
+		return &n
+	}
+
+	// the tail is full, push it into the trie.
+	full := &pvnode[T]{values: pv.tail}
+	if (pv.len >> pvBits) > (1 << pv.shift) { // root overflows
+		n.root = &pvnode[T]{children: []*pvnode[T]{pv.root, newPVPath(pv.shift, full)}}
+		n.shift += pvBits
+	} else {
+		n.root = pv.pushTail(pv.shift, pv.root, full)
+	}
+	n.tail = make([]T, 1, pvWidth)
+	n.tail[0] = v
+	return &n
+}
+
+func (pv *PersistentVector[T]) pushTail(level uint, parent, tail *pvnode[T]) *pvnode[T] {
+	idx := ((pv.len - 1) >> level) & pvMask
+	c := parent.clone()
+	var child *pvnode[T]
+	if level == pvBits {
+		child = tail
+	} else if idx < len(parent.children) {
+		child = pv.pushTail(level-pvBits, parent.children[idx], tail)
+	} else {
+		child = newPVPath(level-pvBits, tail)
+	}
+	if idx < len(c.children) {
+		c.children[idx] = child
+	} else {
+		c.children = append(c.children, child)
+	}
+	return c
+}
+
+func newPVPath[T any](level uint, n *pvnode[T]) *pvnode[T] {
+	if level == 0 {
+		return n
+	}
+	return &pvnode[T]{children: []*pvnode[T]{newPVPath(level-pvBits, n)}}
+}
+
+// Pop returns a new version of the vector that the last element is
+// removed. It panics if the vector is empty.
+func (pv *PersistentVector[T]) Pop() *PersistentVector[T] {
+	switch {
+	case pv.len == 0:
+		panic("ds: pop from empty vector")
+	case pv.len == 1:
+		return NewPersistentVector[T]()
+	}
+	n := *pv
+	n.len--
+	if pv.len-pv.tailoff() > 1 {
+		n.tail = pv.tail[: len(pv.tail)-1 : len(pv.tail)-1]
+		return &n
+	}
+
+	// the tail becomes empty, pull the last leaf out of the trie.
+	n.tail = pv.leaf(pv.len - 2)
+	n.root = pv.popTail(pv.shift, pv.root)
+	if n.root == nil {
+		n.root = &pvnode[T]{}
+	}
+	if pv.shift > pvBits && len(n.root.children) < 2 {
+		n.root = n.root.children[0]
+		n.shift -= pvBits
+	}
+	return &n
+}
+
+func (pv *PersistentVector[T]) popTail(level uint, n *pvnode[T]) *pvnode[T] {
+	idx := ((pv.len - 2) >> level) & pvMask
+	if level > pvBits {
+		child := pv.popTail(level-pvBits, n.children[idx])
+		if child == nil && idx == 0 {
+			return nil
+		}
+		c := n.clone()
+		if child == nil {
+			c.children = c.children[:idx]
+		} else {
+			c.children[idx] = child
+		}
+		return c
+	}
+	if idx == 0 {
+		return nil
+	}
+	c := n.clone()
+	c.children = c.children[:idx]
+	return c
+}
+
+// Range iterates all elements in order with op.
+// The iteration stops if op returns false.
+func (pv *PersistentVector[T]) Range(op func(i int, v T) bool) {
+	for i := 0; i < pv.len; i += pvWidth {
+		leaf := pv.leaf(i)
+		for j, v := range leaf {
+			if !op(i+j, v) {
+				return
+			}
+		}
+	}
+}
+
+func (n *pvnode[T]) clone() *pvnode[T] {
+	return &pvnode[T]{
+		children: append([]*pvnode[T](nil), n.children...),
+		values:   append([]T(nil), n.values...),
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func checkVector(t *testing.T, pv *ds.PersistentVector[int], want []int) {
+	t.Helper()
+	if pv.Len() != len(want) {
+		t.Fatalf("len: want %v, got %v", len(want), pv.Len())
+	}
+	for i, w := range want {
+		if v, ok := pv.Get(i); !ok || v != w {
+			t.Fatalf("get %v: want %v, got %v", i, w, v)
+		}
+	}
+	if _, ok := pv.Get(len(want)); ok {
+		t.Fatalf("get out of range succeeded")
+	}
+	n := 0
+	pv.Range(func(i int, v int) bool {
+		if v != want[i] || i != n {
+			t.Fatalf("range %v: want %v, got %v", i, want[i], v)
+		}
+		n++
+		return true
+	})
+	if n != len(want) {
+		t.Fatalf("range visits %v elements, want %v", n, len(want))
+	}
+}
+
+func TestPersistentVector(t *testing.T) {
+	const n = 40000 // three levels of the trie
+	versions := []*ds.PersistentVector[int]{ds.NewPersistentVector[int]()}
+	for i := 0; i < n; i++ {
+		versions = append(versions, versions[i].Append(i))
+	}
+	for _, i := range []int{0, 1, 31, 32, 33, 1023, 1024, 1057, n} {
+		want := make([]int, i)
+		for j := range want {
+			want[j] = j
+		}
+		checkVector(t, versions[i], want)
+	}
+
+	// set does not modify the old version
+	pv := versions[n]
+	want := make([]int, n)
+	for i := range want {
+		want[i] = i
+	}
+	set := pv
+	for _, i := range rand.Perm(n)[:1000] {
+		set = set.Set(i, -i)
+		want[i] = -i
+	}
+	checkVector(t, set, want)
+	for i := range want {
+		want[i] = i
+	}
+	checkVector(t, pv, want)
+
+	// pop everything back and forth
+	popped := pv
+	for i := n; i > 0; i-- {
+		popped = popped.Pop()
+		if popped.Len() != i-1 {
+			t.Fatalf("pop: want %v, got %v", i-1, popped.Len())
+		}
+		if i%997 == 0 {
+			checkVector(t, popped, want[:i-1])
+			checkVector(t, popped.Append(-1), append(want[:i-1:i-1], -1))
+		}
+	}
+	checkVector(t, pv, want)
+}
+
+func TestPersistentVector_Panic(t *testing.T) {
+	for name, f := range map[string]func(){
+		"set": func() { ds.NewPersistentVector[int]().Set(0, 1) },
+		"pop": func() { ds.NewPersistentVector[int]().Pop() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("%v on empty vector does not panic", name)
+				}
+			}()
+			f()
+		})
+	}
+}
+
+func BenchmarkPersistentVector_Append(b *testing.B) {
+	b.ReportAllocs()
+	pv := ds.NewPersistentVector[int]()
+	for i := 0; i < b.N; i++ {
+		pv = pv.Append(i)
+	}
+}
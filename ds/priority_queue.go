@@ -0,0 +1,250 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// PriorityQueue implements a priority queue on a d-ary heap. The
+// element that is less than all others is popped first.
+type PriorityQueue[T any] struct {
+	h dheap[T]
+}
+
+// NewPriorityQueue creates a priority queue on a binary heap.
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return NewDaryPriorityQueue(2, less)
+}
+
+// NewDaryPriorityQueue creates a priority queue on a d-ary heap. A
+// larger d makes pushes cheaper and pops more expensive, a 4-ary
+// heap is usually faster than a binary heap due to cache locality.
+func NewDaryPriorityQueue[T any](d int, less func(a, b T) bool) *PriorityQueue[T] {
+	if d < 2 {
+		panic("ds: arity of a heap must be at least 2")
+	}
+	return &PriorityQueue[T]{h: dheap[T]{d: d, less: less}}
+}
+
+// Len returns the number of elements in the queue.
+func (pq *PriorityQueue[T]) Len() int {
+	return len(pq.h.items)
+}
+
+// Push pushes x into the queue in O(log n).
+func (pq *PriorityQueue[T]) Push(x T) {
+	pq.h.push(x)
+}
+
+// Pop removes and returns the least element in O(log n).
+// It returns false if the queue is empty.
+func (pq *PriorityQueue[T]) Pop() (x T, ok bool) {
+	if len(pq.h.items) == 0 {
+		return
+	}
+	return pq.h.remove(0), true
+}
+
+// Peek returns the least element without removing it.
+// It returns false if the queue is empty.
+func (pq *PriorityQueue[T]) Peek() (x T, ok bool) {
+	if len(pq.h.items) == 0 {
+		return
+	}
+	return pq.h.items[0], true
+}
+
+// IndexedPriorityQueue implements a priority queue of values that are
+// identified by unique keys. Besides the PriorityQueue operations, it
+// supports lookup, update and removal of a value by its key.
+type IndexedPriorityQueue[K comparable, V any] struct {
+	h     dheap[*ipqentry[K, V]]
+	index map[K]*ipqentry[K, V]
+}
+
+type ipqentry[K comparable, V any] struct {
+	k   K
+	v   V
+	pos int // position in the heap
+}
+
+// NewIndexedPriorityQueue creates an indexed priority queue on a binary heap.
+func NewIndexedPriorityQueue[K comparable, V any](less func(a, b V) bool) *IndexedPriorityQueue[K, V] {
+	return NewDaryIndexedPriorityQueue[K](2, less)
+}
+
+// NewDaryIndexedPriorityQueue creates an indexed priority queue on a d-ary heap.
+func NewDaryIndexedPriorityQueue[K comparable, V any](d int, less func(a, b V) bool) *IndexedPriorityQueue[K, V] {
+	if d < 2 {
+		panic("ds: arity of a heap must be at least 2")
+	}
+	return &IndexedPriorityQueue[K, V]{
+		h: dheap[*ipqentry[K, V]]{
+			d:    d,
+			less: func(a, b *ipqentry[K, V]) bool { return less(a.v, b.v) },
+			moved: func(e *ipqentry[K, V], pos int) {
+				e.pos = pos
+			},
+		},
+		index: map[K]*ipqentry[K, V]{},
+	}
+}
+
+// Len returns the number of elements in the queue.
+func (pq *IndexedPriorityQueue[K, V]) Len() int {
+	return len(pq.h.items)
+}
+
+// Push pushes the value of key into the queue in O(log n). If key
+// is already in the queue, its value is updated.
+func (pq *IndexedPriorityQueue[K, V]) Push(key K, v V) {
+	if e, ok := pq.index[key]; ok {
+		e.v = v
+		pq.h.fix(e.pos)
+		return
+	}
+	e := &ipqentry[K, V]{k: key, v: v}
+	pq.index[key] = e
+	pq.h.push(e)
+}
+
+// Pop removes and returns the least value and its key in O(log n).
+// It returns false if the queue is empty.
+func (pq *IndexedPriorityQueue[K, V]) Pop() (key K, v V, ok bool) {
+	if len(pq.h.items) == 0 {
+		return
+	}
+	e := pq.h.remove(0)
+	delete(pq.index, e.k)
+	return e.k, e.v, true
+}
+
+// Peek returns the least value and its key without removing it.
+// It returns false if the queue is empty.
+func (pq *IndexedPriorityQueue[K, V]) Peek() (key K, v V, ok bool) {
+	if len(pq.h.items) == 0 {
+		return
+	}
+	e := pq.h.items[0]
+	return e.k, e.v, true
+}
+
+// Get returns the value of key in O(1).
+func (pq *IndexedPriorityQueue[K, V]) Get(key K) (v V, ok bool) {
+	e, ok := pq.index[key]
+	if !ok {
+		return
+	}
+	return e.v, true
+}
+
+// Update updates the value of key and restores the heap order in
+// O(log n). It returns false if key is not in the queue.
+func (pq *IndexedPriorityQueue[K, V]) Update(key K, v V) bool {
+	e, ok := pq.index[key]
+	if !ok {
+		return false
+	}
+	e.v = v
+	pq.h.fix(e.pos)
+	return true
+}
+
+// Remove removes the value of key in O(log n).
+// It returns false if key is not in the queue.
+func (pq *IndexedPriorityQueue[K, V]) Remove(key K) (v V, ok bool) {
+	e, ok := pq.index[key]
+	if !ok {
+		return
+	}
+	pq.h.remove(e.pos)
+	delete(pq.index, key)
+	return e.v, true
+}
+
+// dheap is a d-ary min heap. moved is called, if not nil, whenever
+// an element is placed at a new position.
+type dheap[T any] struct {
+	items []T
+	d     int
+	less  func(a, b T) bool
+	moved func(x T, pos int)
+}
+
+func (h *dheap[T]) set(i int, x T) {
+	h.items[i] = x
+	if h.moved != nil {
+		h.moved(x, i)
+	}
+}
+
+func (h *dheap[T]) push(x T) {
+	h.items = append(h.items, x)
+	h.set(len(h.items)-1, x)
+	h.up(len(h.items) - 1)
+}
+
+// remove removes and returns the element at position i.
+func (h *dheap[T]) remove(i int) T {
+	x := h.items[i]
+	last := len(h.items) - 1
+	if i != last {
+		h.set(i, h.items[last])
+	}
+	var zero T
+	h.items[last] = zero
+	h.items = h.items[:last]
+	if i != last {
+		h.fix(i)
+	}
+	return x
+}
+
+// fix restores the heap order after the element at i has changed.
+func (h *dheap[T]) fix(i int) {
+	if !h.up(i) {
+		h.down(i)
+	}
+}
+
+// up moves the element at i towards the root, and reports whether
+// it was moved.
+func (h *dheap[T]) up(i int) bool {
+	x := h.items[i]
+	start := i
+	for i > 0 {
+		parent := (i - 1) / h.d
+		if !h.less(x, h.items[parent]) {
+			break
+		}
+		h.set(i, h.items[parent])
+		i = parent
+	}
+	if i == start {
+		return false
+	}
+	h.set(i, x)
+	return true
+}
+
+func (h *dheap[T]) down(i int) {
+	x := h.items[i]
+	n := len(h.items)
+	for {
+		first := h.d*i + 1
+		if first >= n {
+			break
+		}
+		min := first
+		for c := first + 1; c < first+h.d && c < n; c++ {
+			if h.less(h.items[c], h.items[min]) {
+				min = c
+			}
+		}
+		if !h.less(h.items[min], x) {
+			break
+		}
+		h.set(i, h.items[min])
+		i = min
+	}
+	h.set(i, x)
+}
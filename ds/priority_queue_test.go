@@ -0,0 +1,142 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func intLess(a, b int) bool { return a < b }
+
+func TestPriorityQueue(t *testing.T) {
+	for _, d := range []int{2, 3, 4, 8} {
+		t.Run(fmt.Sprintf("%d-ary", d), func(t *testing.T) {
+			pq := ds.NewDaryPriorityQueue(d, intLess)
+			if _, ok := pq.Pop(); ok {
+				t.Fatalf("pop empty queue succeeded")
+			}
+			nums := make([]int, 1000)
+			for i := range nums {
+				nums[i] = rand.Intn(100)
+				pq.Push(nums[i])
+			}
+			sort.Ints(nums)
+			if v, ok := pq.Peek(); !ok || v != nums[0] || pq.Len() != len(nums) {
+				t.Fatalf("peek: want %v, got %v", nums[0], v)
+			}
+			for _, want := range nums {
+				if v, ok := pq.Pop(); !ok || v != want {
+					t.Fatalf("pop: want %v, got %v", want, v)
+				}
+			}
+			if _, ok := pq.Peek(); ok {
+				t.Fatalf("peek empty queue succeeded")
+			}
+		})
+	}
+}
+
+func TestIndexedPriorityQueue(t *testing.T) {
+	for _, d := range []int{2, 4} {
+		t.Run(fmt.Sprintf("%d-ary", d), func(t *testing.T) {
+			pq := ds.NewDaryIndexedPriorityQueue[string](d, intLess)
+			want := map[string]int{}
+			for i := 0; i < 1000; i++ {
+				k := fmt.Sprintf("%d", rand.Intn(300))
+				v := rand.Intn(1000)
+				switch rand.Intn(4) {
+				case 0:
+					ok := pq.Update(k, v)
+					if _, exist := want[k]; exist != ok {
+						t.Fatalf("update %v: want %v, got %v", k, exist, ok)
+					}
+					if ok {
+						want[k] = v
+					}
+				case 1:
+					got, ok := pq.Remove(k)
+					if w, exist := want[k]; exist != ok || got != w {
+						t.Fatalf("remove %v: want %v, got %v", k, w, got)
+					}
+					delete(want, k)
+				default:
+					pq.Push(k, v)
+					want[k] = v
+				}
+			}
+			if pq.Len() != len(want) {
+				t.Fatalf("want %v, got %v", len(want), pq.Len())
+			}
+			for k, w := range want {
+				if v, ok := pq.Get(k); !ok || v != w {
+					t.Fatalf("get %v: want %v, got %v", k, w, v)
+				}
+			}
+			prev := -1
+			for pq.Len() > 0 {
+				k, v, _ := pq.Pop()
+				if v < prev || want[k] != v {
+					t.Fatalf("pop out of order: %v:%v after %v", k, v, prev)
+				}
+				delete(want, k)
+				prev = v
+			}
+			if len(want) != 0 {
+				t.Fatalf("missing elements: %v", want)
+			}
+			if _, _, ok := pq.Peek(); ok {
+				t.Fatalf("peek empty queue succeeded")
+			}
+		})
+	}
+}
+
+// BenchmarkDecreaseKey pushes n elements and then decreases keys
+// randomly before draining the queue, which is the access pattern of
+// Dijkstra's shortest paths.
+func BenchmarkDecreaseKey(b *testing.B) {
+	const n = 1 << 12
+	for _, d := range []int{2, 4} {
+		b.Run(fmt.Sprintf("%d-ary", d), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pq := ds.NewDaryIndexedPriorityQueue[int](d, intLess)
+				prio := make([]int, n)
+				for k := 0; k < n; k++ {
+					prio[k] = n * 4
+					pq.Push(k, prio[k])
+				}
+				for j := 0; j < 4*n; j++ {
+					k := j % n
+					prio[k]--
+					pq.Update(k, prio[k])
+				}
+				for pq.Len() > 0 {
+					pq.Pop()
+				}
+			}
+		})
+	}
+	b.Run("pairing", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			h := ds.NewPairingHeap(intLess)
+			nodes := make([]*ds.PairingHeapNode[int], n)
+			for k := 0; k < n; k++ {
+				nodes[k] = h.Push(n * 4)
+			}
+			for j := 0; j < 4*n; j++ {
+				k := j % n
+				h.DecreaseKey(nodes[k], nodes[k].Value()-1)
+			}
+			for h.Len() > 0 {
+				h.Pop()
+			}
+		}
+	})
+}
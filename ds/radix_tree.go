@@ -0,0 +1,306 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"sort"
+	"strings"
+)
+
+// RadixTree is a compressed trie of string keys. Every edge is
+// labeled by a string, and a node that has a single child and no
+// value is merged with its child. Put, Get and Del are O(k) for keys
+// of length k, and keys are iterated in lexical order.
+//
+// Besides exact lookup, it finds the longest key that is a prefix of
+// a string, the keys that share a prefix, and the key that matches a
+// URL path, see Match.
+type RadixTree[V any] struct {
+	root radixNode[V]
+	len  int
+}
+
+type radixNode[V any] struct {
+	label    string
+	children []*radixNode[V] // sorted by the first byte of labels
+	leaf     bool            // whether the node stores a value
+	key      string
+	v        V
+}
+
+// child returns the position of the child whose label starts with b,
+// or the position to insert such a child.
+func (n *radixNode[V]) child(b byte) (int, *radixNode[V]) {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].label[0] >= b
+	})
+	if i < len(n.children) && n.children[i].label[0] == b {
+		return i, n.children[i]
+	}
+	return i, nil
+}
+
+func (n *radixNode[V]) insert(i int, c *radixNode[V]) {
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = c
+}
+
+// NewRadixTree creates an empty radix tree.
+func NewRadixTree[V any]() *RadixTree[V] {
+	return &RadixTree[V]{}
+}
+
+// Len returns the number of keys in the tree.
+func (t *RadixTree[V]) Len() int {
+	return t.len
+}
+
+// Put stores the value by given key.
+func (t *RadixTree[V]) Put(key string, v V) {
+	n, search := &t.root, key
+	for search != "" {
+		i, c := n.child(search[0])
+		if c == nil {
+			n.insert(i, &radixNode[V]{label: search, leaf: true, key: key, v: v})
+			t.len++
+			return
+		}
+		l := commonPrefixLen(search, c.label)
+		if l < len(c.label) { // split the edge
+			mid := &radixNode[V]{label: c.label[:l], children: []*radixNode[V]{c}}
+			c.label = c.label[l:]
+			n.children[i] = mid
+			c = mid
+		}
+		n, search = c, search[l:]
+	}
+	if !n.leaf {
+		t.len++
+	}
+	n.leaf, n.key, n.v = true, key, v
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// Get returns the value of key.
+func (t *RadixTree[V]) Get(key string) (v V, ok bool) {
+	n, search := &t.root, key
+	for search != "" {
+		_, c := n.child(search[0])
+		if c == nil || !strings.HasPrefix(search, c.label) {
+			return
+		}
+		n, search = c, search[len(c.label):]
+	}
+	if !n.leaf {
+		return
+	}
+	return n.v, true
+}
+
+// Del deletes the value of key.
+func (t *RadixTree[V]) Del(key string) {
+	var (
+		parent *radixNode[V]
+		pos    int // position of n in parent
+	)
+	n, search := &t.root, key
+	for search != "" {
+		i, c := n.child(search[0])
+		if c == nil || !strings.HasPrefix(search, c.label) {
+			return
+		}
+		parent, pos = n, i
+		n, search = c, search[len(c.label):]
+	}
+	if !n.leaf {
+		return
+	}
+	var zero V
+	n.leaf, n.key, n.v = false, "", zero
+	t.len--
+	if parent == nil {
+		return // the root is never removed or merged
+	}
+
+	switch len(n.children) {
+	case 0:
+		parent.children = append(parent.children[:pos], parent.children[pos+1:]...)
+		if parent != &t.root && !parent.leaf && len(parent.children) == 1 {
+			parent.mergeChild()
+		}
+	case 1:
+		n.mergeChild()
+	}
+}
+
+// mergeChild merges the only child of n into n.
+func (n *radixNode[V]) mergeChild() {
+	c := n.children[0]
+	n.label += c.label
+	n.children, n.leaf, n.key, n.v = c.children, c.leaf, c.key, c.v
+}
+
+// LongestPrefix returns the longest key that is a prefix of s, and
+// its value. It returns false if there is no such key.
+func (t *RadixTree[V]) LongestPrefix(s string) (key string, v V, ok bool) {
+	n, search := &t.root, s
+	for {
+		if n.leaf {
+			key, v, ok = n.key, n.v, true
+		}
+		if search == "" {
+			return
+		}
+		_, c := n.child(search[0])
+		if c == nil || !strings.HasPrefix(search, c.label) {
+			return
+		}
+		n, search = c, search[len(c.label):]
+	}
+}
+
+// WalkPrefix iterates all keys that start with prefix in lexical
+// order with op. The iteration stops if op returns false.
+func (t *RadixTree[V]) WalkPrefix(prefix string, op func(key string, v V) bool) {
+	n, search := &t.root, prefix
+	for search != "" {
+		_, c := n.child(search[0])
+		switch {
+		case c == nil:
+			return
+		case strings.HasPrefix(search, c.label):
+			search = search[len(c.label):]
+		case strings.HasPrefix(c.label, search):
+			search = "" // the prefix ends inside the label
+		default:
+			return
+		}
+		n = c
+	}
+	n.walk(op)
+}
+
+func (n *radixNode[V]) walk(op func(key string, v V) bool) bool {
+	if n.leaf && !op(n.key, n.v) {
+		return false
+	}
+	for _, c := range n.children {
+		if !c.walk(op) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match finds the key that matches a URL path, where the keys are
+// patterns of segments separated by '/'. A segment ":name" of a key
+// matches any nonempty segment of the path, and a segment "*name"
+// matches the rest of the path. Static segments take precedence over
+// ":" segments, which take precedence over "*" segments. The matched
+// segments of the path are returned in params by their names.
+//
+// For instance, the key "/users/:id/*file" matches the path
+// "/users/42/docs/a.txt" with params id=42 and file=docs/a.txt.
+func (t *RadixTree[V]) Match(path string) (key string, v V, params map[string]string, ok bool) {
+	m := &radixMatcher[V]{path: path}
+	n := m.match(&t.root, "", 0, 0)
+	if n == nil {
+		return
+	}
+	if len(m.params) > 0 {
+		params = make(map[string]string, len(m.params)/2)
+		for i := 0; i < len(m.params); i += 2 {
+			params[m.params[i]] = m.params[i+1]
+		}
+	}
+	return n.key, n.v, params, true
+}
+
+type radixMatcher[V any] struct {
+	path   string
+	params []string // pairs of names and values
+}
+
+// isWildcard reports whether c starts a wildcard segment, i.e. c is
+// ':' or '*' and follows '/' or starts a key.
+func isWildcard(c, prev byte) bool {
+	return (c == ':' || c == '*') && (prev == '/' || prev == 0)
+}
+
+// match matches the rest label of n and the subtree of n with
+// path[i:], prev is the byte of the key before label. It returns the
+// node of the matched key, or nil.
+func (m *radixMatcher[V]) match(n *radixNode[V], label string, i int, prev byte) *radixNode[V] {
+	for ; label != ""; label, i = label[1:], i+1 {
+		c := label[0]
+		if isWildcard(c, prev) {
+			j := len(m.path)
+			if c == ':' {
+				if k := strings.IndexByte(m.path[i:], '/'); k >= 0 {
+					j = i + k
+				}
+				if j == i {
+					return nil // empty segment
+				}
+			}
+			return m.skipName(n, label[1:], "", m.path[i:j], j, c)
+		}
+		if i == len(m.path) || m.path[i] != c {
+			return nil
+		}
+		prev = c
+	}
+	if i == len(m.path) && n.leaf {
+		return n
+	}
+
+	// static children first, then ':', then '*'.
+	for _, wc := range [3]byte{0, ':', '*'} {
+		for _, c := range n.children {
+			first := c.label[0]
+			if (wc == 0 && !isWildcard(first, prev)) || (wc == first && isWildcard(first, prev)) {
+				if r := m.match(c, c.label, i, prev); r != nil {
+					return r
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// skipName skips the name of a wildcard segment that matched value,
+// which starts from the rest label of n, and continues to match the
+// rest of the key with path[j:].
+func (m *radixMatcher[V]) skipName(n *radixNode[V], label, name, value string, j int, wc byte) *radixNode[V] {
+	if k := strings.IndexByte(label, '/'); k >= 0 {
+		m.params = append(m.params, name+label[:k], value)
+		if r := m.match(n, label[k:], j, wc); r != nil {
+			return r
+		}
+		m.params = m.params[:len(m.params)-2]
+		return nil
+	}
+
+	// the name continues to the end of label.
+	name += label
+	if j == len(m.path) && n.leaf {
+		m.params = append(m.params, name, value)
+		return n
+	}
+	for _, c := range n.children {
+		if r := m.skipName(c, c.label, name, value, j, wc); r != nil {
+			return r
+		}
+	}
+	return nil
+}
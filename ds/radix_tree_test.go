@@ -0,0 +1,123 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestRadixTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randKey := func() string {
+		b := make([]byte, r.Intn(6))
+		for i := range b {
+			b[i] = "abc/"[r.Intn(4)]
+		}
+		return string(b)
+	}
+
+	tree := ds.NewRadixTree[int]()
+	want := map[string]int{}
+	for i := 0; i < 5000; i++ {
+		k := randKey()
+		if r.Intn(3) == 0 {
+			tree.Del(k)
+			delete(want, k)
+		} else {
+			tree.Put(k, i)
+			want[k] = i
+		}
+		if tree.Len() != len(want) {
+			t.Fatalf("want len %v, got %v", len(want), tree.Len())
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		k := randKey()
+		v, ok := tree.Get(k)
+		if wv, wok := want[k]; ok != wok || v != wv {
+			t.Fatalf("get %q, want %v, %v, got %v, %v", k, wv, wok, v, ok)
+		}
+
+		wantKey, wantOK := "", false
+		for key := range want {
+			if strings.HasPrefix(k, key) && (!wantOK || len(key) > len(wantKey)) {
+				wantKey, wantOK = key, true
+			}
+		}
+		key, v, ok := tree.LongestPrefix(k)
+		if ok != wantOK || key != wantKey || (ok && v != want[key]) {
+			t.Fatalf("longest prefix of %q, want %q, got %q", k, wantKey, key)
+		}
+
+		var wantKeys, keys []string
+		for key := range want {
+			if strings.HasPrefix(key, k) {
+				wantKeys = append(wantKeys, key)
+			}
+		}
+		sort.Strings(wantKeys)
+		tree.WalkPrefix(k, func(key string, v int) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if !reflect.DeepEqual(keys, wantKeys) {
+			t.Fatalf("walk prefix %q, want %v, got %v", k, wantKeys, keys)
+		}
+	}
+}
+
+func TestRadixTreeMatch(t *testing.T) {
+	tree := ds.NewRadixTree[int]()
+	routes := []string{
+		"/",
+		"/users",
+		"/users/new",
+		"/users/:id",
+		"/users/:id/posts",
+		"/users/:idx/comments",
+		"/files/*path",
+		"/static/*",
+		"/a/:x/c",
+		"/a/b/:y",
+	}
+	for i, r := range routes {
+		tree.Put(r, i)
+	}
+	tests := []struct {
+		path   string
+		key    string
+		params map[string]string
+	}{
+		{"/", "/", nil},
+		{"/users", "/users", nil},
+		{"/users/new", "/users/new", nil},
+		{"/users/42", "/users/:id", map[string]string{"id": "42"}},
+		{"/users/42/posts", "/users/:id/posts", map[string]string{"id": "42"}},
+		{"/users/42/comments", "/users/:idx/comments", map[string]string{"idx": "42"}},
+		{"/files/a/b.txt", "/files/*path", map[string]string{"path": "a/b.txt"}},
+		{"/files/", "/files/*path", map[string]string{"path": ""}},
+		{"/static/x", "/static/*", map[string]string{"": "x"}},
+		{"/a/b/c", "/a/b/:y", map[string]string{"y": "c"}},
+		{"/a/z/c", "/a/:x/c", map[string]string{"x": "z"}},
+		{"/users/", "", nil},
+		{"/users/42/x", "", nil},
+		{"/nothing", "", nil},
+	}
+	for _, tt := range tests {
+		key, v, params, ok := tree.Match(tt.path)
+		if ok != (tt.key != "") || key != tt.key {
+			t.Fatalf("match %q, want %q, got %q", tt.path, tt.key, key)
+		}
+		if ok && (routes[v] != key || !reflect.DeepEqual(params, tt.params)) {
+			t.Fatalf("match %q, want params %v, got %v", tt.path, tt.params, params)
+		}
+	}
+}
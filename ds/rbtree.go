@@ -23,6 +23,7 @@ type rbnode struct {
 	right  *rbnode
 	parent *rbnode
 	k, v   interface{}
+	size   int // number of nodes in the subtree rooted at this node
 }
 
 func (n *rbnode) color() color {
@@ -57,11 +58,63 @@ func (n *rbnode) maximumNode() *rbnode {
 	return n
 }
 
+func (n *rbnode) minimumNode() *rbnode {
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func (n *rbnode) successor() *rbnode {
+	if n.right != nil {
+		return n.right.minimumNode()
+	}
+	p := n.parent
+	for p != nil && n == p.right {
+		n, p = p, p.parent
+	}
+	return p
+}
+
+func (n *rbnode) predecessor() *rbnode {
+	if n.left != nil {
+		return n.left.maximumNode()
+	}
+	p := n.parent
+	for p != nil && n == p.left {
+		n, p = p, p.parent
+	}
+	return p
+}
+
+func (n *rbnode) subtreeSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *rbnode) resize() {
+	n.size = n.left.subtreeSize() + n.right.subtreeSize() + 1
+}
+
 // RBTree is a red-black tree
 type RBTree struct {
 	root *rbnode
 	len  int
 	less common.Less
+
+	// augment, if not nil, recomputes the augmented data of a node
+	// from its children whenever the subtree of the node changes.
+	augment func(n *rbnode)
+}
+
+// update recomputes the size and the augmented data of n.
+func (t *RBTree) update(n *rbnode) {
+	n.resize()
+	if t.augment != nil {
+		t.augment(n)
+	}
 }
 
 // NewRBTree creates a red-black tree
@@ -78,7 +131,10 @@ func (t *RBTree) Len() int {
 func (t *RBTree) Put(key, value interface{}) {
 	var insertedNode *rbnode
 
-	new := &rbnode{k: key, v: value, c: red}
+	new := &rbnode{k: key, v: value, c: red, size: 1}
+	if t.augment != nil {
+		t.augment(new)
+	}
 	if t.root != nil {
 		node := t.root
 	LOOP:
@@ -101,10 +157,18 @@ func (t *RBTree) Put(key, value interface{}) {
 			default: // =
 				node.k = key
 				node.v = value
+				if t.augment != nil {
+					for p := node; p != nil; p = p.parent {
+						t.augment(p)
+					}
+				}
 				return
 			}
 		}
 		insertedNode.parent = node
+		for p := node; p != nil; p = p.parent {
+			t.update(p)
+		}
 	} else {
 		t.root = new
 		insertedNode = t.root
@@ -182,6 +246,8 @@ func (t *RBTree) rotateLeft(n *rbnode) {
 	}
 	right.left = n
 	n.parent = right
+	t.update(n)
+	t.update(right)
 }
 func (t *RBTree) rotateRight(n *rbnode) {
 	left := n.left
@@ -192,6 +258,8 @@ func (t *RBTree) rotateRight(n *rbnode) {
 	}
 	left.right = n
 	n.parent = left
+	t.update(n)
+	t.update(left)
 }
 
 // Get returns the stored value by given key
@@ -249,6 +317,9 @@ func (t *RBTree) Del(key interface{}) {
 		if n.parent == nil && child != nil {
 			child.c = black
 		}
+		for p := n.parent; p != nil; p = p.parent {
+			t.update(p)
+		}
 	}
 	t.len--
 }
@@ -329,6 +400,185 @@ func (t *RBTree) delCase6(n *rbnode) {
 	t.rotateRight(n.parent)
 }
 
+// Min returns the smallest key and its value.
+// It returns false if the tree is empty.
+func (t *RBTree) Min() (key, value interface{}, ok bool) {
+	if t.root == nil {
+		return nil, nil, false
+	}
+	n := t.root.minimumNode()
+	return n.k, n.v, true
+}
+
+// Max returns the largest key and its value.
+// It returns false if the tree is empty.
+func (t *RBTree) Max() (key, value interface{}, ok bool) {
+	if t.root == nil {
+		return nil, nil, false
+	}
+	n := t.root.maximumNode()
+	return n.k, n.v, true
+}
+
+// Floor returns the largest key that is less than or equal to
+// the given key. It returns false if there is no such key.
+func (t *RBTree) Floor(key interface{}) (k, v interface{}, ok bool) {
+	n := t.floor(key)
+	if n == nil {
+		return nil, nil, false
+	}
+	return n.k, n.v, true
+}
+
+// Ceiling returns the smallest key that is greater than or equal to
+// the given key. It returns false if there is no such key.
+func (t *RBTree) Ceiling(key interface{}) (k, v interface{}, ok bool) {
+	n := t.ceiling(key)
+	if n == nil {
+		return nil, nil, false
+	}
+	return n.k, n.v, true
+}
+
+func (t *RBTree) floor(key interface{}) (found *rbnode) {
+	n := t.root
+	for n != nil {
+		switch {
+		case t.less(key, n.k):
+			n = n.left
+		case t.less(n.k, key):
+			found = n
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return
+}
+
+func (t *RBTree) ceiling(key interface{}) (found *rbnode) {
+	n := t.root
+	for n != nil {
+		switch {
+		case t.less(key, n.k):
+			found = n
+			n = n.left
+		case t.less(n.k, key):
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return
+}
+
+// Range iterates all keys k that from <= k < to in ascending order
+// with op. The iteration stops if op returns false.
+func (t *RBTree) Range(from, to interface{}, op func(k, v interface{}) bool) {
+	for n := t.ceiling(from); n != nil && t.less(n.k, to); n = n.successor() {
+		if !op(n.k, n.v) {
+			return
+		}
+	}
+}
+
+// Rank returns the number of keys that are less than the given key.
+func (t *RBTree) Rank(key interface{}) int {
+	rank := 0
+	n := t.root
+	for n != nil {
+		switch {
+		case t.less(key, n.k):
+			n = n.left
+		case t.less(n.k, key):
+			rank += n.left.subtreeSize() + 1
+			n = n.right
+		default:
+			return rank + n.left.subtreeSize()
+		}
+	}
+	return rank
+}
+
+// Select returns the i-th smallest key and its value, i starts from 0.
+// It returns false if i is out of range.
+func (t *RBTree) Select(i int) (key, value interface{}, ok bool) {
+	if i < 0 || i >= t.len {
+		return nil, nil, false
+	}
+	n := t.root
+	for n != nil {
+		l := n.left.subtreeSize()
+		switch {
+		case i < l:
+			n = n.left
+		case i > l:
+			i -= l + 1
+			n = n.right
+		default:
+			return n.k, n.v, true
+		}
+	}
+	return nil, nil, false
+}
+
+// RBTreeIterator iterates over the key value pairs of a RBTree.
+// Modifying the tree invalidates the iterator.
+type RBTreeIterator struct {
+	next    *rbnode
+	cur     *rbnode
+	reverse bool
+}
+
+// Iterator returns an iterator that visits keys in ascending order.
+func (t *RBTree) Iterator() *RBTreeIterator {
+	it := &RBTreeIterator{}
+	if t.root != nil {
+		it.next = t.root.minimumNode()
+	}
+	return it
+}
+
+// ReverseIterator returns an iterator that visits keys in descending order.
+func (t *RBTree) ReverseIterator() *RBTreeIterator {
+	it := &RBTreeIterator{reverse: true}
+	if t.root != nil {
+		it.next = t.root.maximumNode()
+	}
+	return it
+}
+
+// Seek returns an ascending iterator that starts from the smallest
+// key that is greater than or equal to the given key.
+func (t *RBTree) Seek(key interface{}) *RBTreeIterator {
+	return &RBTreeIterator{next: t.ceiling(key)}
+}
+
+// Next advances the iterator, it returns false if there are no more elements.
+func (it *RBTreeIterator) Next() bool {
+	if it.next == nil {
+		it.cur = nil
+		return false
+	}
+	it.cur = it.next
+	if it.reverse {
+		it.next = it.next.predecessor()
+	} else {
+		it.next = it.next.successor()
+	}
+	return true
+}
+
+// Key returns the key of current element.
+func (it *RBTreeIterator) Key() interface{} {
+	return it.cur.k
+}
+
+// Value returns the value of current element.
+func (it *RBTreeIterator) Value() interface{} {
+	return it.cur.v
+}
+
 func (t *RBTree) String() string {
 	str := "RBTree\n"
 	if t.Len() != 0 {
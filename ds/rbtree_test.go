@@ -7,6 +7,7 @@ package ds_test
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 	"testing"
 
 	"changkun.de/x/pkg/ds"
@@ -141,6 +142,113 @@ func TestRBTreeNoEqual(t *testing.T) {
 	}
 }
 
+func TestRBTreeOrdered(t *testing.T) {
+	tree := ds.NewRBTree(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+	if _, _, ok := tree.Min(); ok {
+		t.Fatalf("min of empty tree succeeded")
+	}
+	if _, _, ok := tree.Max(); ok {
+		t.Fatalf("max of empty tree succeeded")
+	}
+	if tree.Iterator().Next() || tree.ReverseIterator().Next() {
+		t.Fatalf("iterate empty tree succeeded")
+	}
+
+	// put even numbers and delete some of them randomly
+	keys := map[int]bool{}
+	for _, k := range rand.Perm(500) {
+		tree.Put(2*k, 2*k)
+		keys[2*k] = true
+	}
+	for _, k := range rand.Perm(500)[:200] {
+		tree.Del(2 * k)
+		delete(keys, 2*k)
+	}
+	sorted := []int{}
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Ints(sorted)
+
+	it := tree.Iterator()
+	for i := range sorted {
+		if !it.Next() || it.Key() != sorted[i] || it.Value() != sorted[i] {
+			t.Fatalf("iterator: want %v, got %v", sorted[i], it.Key())
+		}
+	}
+	if it.Next() {
+		t.Fatalf("iterator: want end, got %v", it.Key())
+	}
+	it = tree.ReverseIterator()
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if !it.Next() || it.Key() != sorted[i] {
+			t.Fatalf("reverse iterator: want %v, got %v", sorted[i], it.Key())
+		}
+	}
+	if it.Next() {
+		t.Fatalf("reverse iterator: want end, got %v", it.Key())
+	}
+
+	if k, _, _ := tree.Min(); k != sorted[0] {
+		t.Fatalf("min: want %v, got %v", sorted[0], k)
+	}
+	if k, _, _ := tree.Max(); k != sorted[len(sorted)-1] {
+		t.Fatalf("max: want %v, got %v", sorted[len(sorted)-1], k)
+	}
+
+	for i, k := range sorted {
+		if r := tree.Rank(k); r != i {
+			t.Fatalf("rank of %v: want %v, got %v", k, i, r)
+		}
+		if r := tree.Rank(k + 1); r != i+1 {
+			t.Fatalf("rank of %v: want %v, got %v", k+1, i+1, r)
+		}
+		if s, _, ok := tree.Select(i); !ok || s != k {
+			t.Fatalf("select %v: want %v, got %v", i, k, s)
+		}
+		if f, _, ok := tree.Floor(k + 1); !ok || f != k {
+			t.Fatalf("floor of %v: want %v, got %v", k+1, k, f)
+		}
+		if c, _, ok := tree.Ceiling(k - 1); !ok || c != k {
+			t.Fatalf("ceiling of %v: want %v, got %v", k-1, k, c)
+		}
+	}
+	if _, _, ok := tree.Select(len(sorted)); ok {
+		t.Fatalf("select out of range succeeded")
+	}
+	if _, _, ok := tree.Floor(sorted[0] - 1); ok {
+		t.Fatalf("floor below min succeeded")
+	}
+	if _, _, ok := tree.Ceiling(sorted[len(sorted)-1] + 1); ok {
+		t.Fatalf("ceiling above max succeeded")
+	}
+
+	from, to := sorted[10]-1, sorted[50]
+	got := []int{}
+	tree.Range(from, to, func(k, v interface{}) bool {
+		got = append(got, k.(int))
+		return true
+	})
+	if len(got) != 40 || got[0] != sorted[10] || got[39] != sorted[49] {
+		t.Fatalf("range [%v, %v): got %v", from, to, got)
+	}
+	count := 0
+	tree.Range(from, to, func(k, v interface{}) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Fatalf("range does not stop: %v", count)
+	}
+
+	it = tree.Seek(sorted[100] - 1)
+	if !it.Next() || it.Key() != sorted[100] {
+		t.Fatalf("seek: want %v, got %v", sorted[100], it.Key())
+	}
+}
+
 func BenchmarkRBTree_Put(b *testing.B) {
 	count := 0
 	grow := 1
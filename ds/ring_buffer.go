@@ -4,46 +4,141 @@
 
 package ds
 
-// RingBuffer implements ring buffer queue
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Errors
+var (
+	ErrEmpty  = errors.New("container is empty")
+	ErrFull   = errors.New("container is full")
+	ErrClosed = errors.New("container is closed")
+)
+
+type ringBufferMode int
+
+const (
+	ringBufferBounded ringBufferMode = iota
+	ringBufferOverwrite
+	ringBufferGrowing
+)
+
+// RingBufferOption sets an option on the ring buffer.
+type RingBufferOption func(*RingBuffer)
+
+// WithOverwrite makes a full ring buffer overwrite its oldest element
+// on Put, which is useful for keeping the tail of a telemetry stream.
+func WithOverwrite() RingBufferOption {
+	return func(rb *RingBuffer) {
+		rb.mode = ringBufferOverwrite
+	}
+}
+
+// WithGrowth makes a full ring buffer double its capacity on Put.
+func WithGrowth() RingBufferOption {
+	return func(rb *RingBuffer) {
+		rb.mode = ringBufferGrowing
+	}
+}
+
+// RingBuffer implements ring buffer queue. By default, Put fails if
+// the buffer is full, see WithOverwrite and WithGrowth for other modes.
+// RingBuffer is not safe for concurrent use, see BlockingRingBuffer.
 type RingBuffer struct {
 	buf        []interface{}
 	head, tail int
 	len, cap   int
+	mode       ringBufferMode
 }
 
 // NewRingBuffer creates a ring buffer with given capacity
-func NewRingBuffer(capacity int) *RingBuffer {
-	return &RingBuffer{
+func NewRingBuffer(capacity int, opts ...RingBufferOption) *RingBuffer {
+	rb := &RingBuffer{
 		buf: make([]interface{}, capacity),
 		cap: capacity,
 	}
+	for _, opt := range opts {
+		opt(rb)
+	}
+	return rb
 }
 
-// Put puts x into ring buffer
+// Put puts x into ring buffer, it returns false if the buffer is full.
 func (rb *RingBuffer) Put(x interface{}) (ok bool) {
+	return rb.TryPut(x) == nil
+}
+
+// TryPut puts x into ring buffer, it returns ErrFull if the buffer is
+// full and neither overwrites nor grows.
+func (rb *RingBuffer) TryPut(x interface{}) error {
 	if rb.len == rb.cap {
-		return
+		switch {
+		case rb.mode == ringBufferGrowing:
+			rb.grow()
+		case rb.mode == ringBufferOverwrite && rb.cap > 0:
+			rb.buf[rb.head] = nil
+			rb.head = (rb.head + 1) % rb.cap
+			rb.len--
+		default:
+			return ErrFull
+		}
 	}
 
 	rb.buf[rb.tail] = x
-	rb.tail++
-	if rb.tail > rb.cap-1 {
-		rb.tail = 0
-	}
+	rb.tail = (rb.tail + 1) % rb.cap
 	rb.len++
-	ok = true
-	return
+	return nil
+}
+
+func (rb *RingBuffer) grow() {
+	n := 2 * rb.cap
+	if n == 0 {
+		n = 1
+	}
+	buf := make([]interface{}, n)
+	for i := 0; i < rb.len; i++ {
+		buf[i] = rb.buf[(rb.head+i)%rb.cap]
+	}
+	rb.buf = buf
+	rb.head, rb.tail = 0, rb.len
+	rb.cap = n
 }
 
-// Get gets the first element from queue
+// Get gets the first element from queue, it returns nil if the
+// buffer is empty.
 func (rb *RingBuffer) Get() (x interface{}) {
-	x = rb.buf[rb.head]
-	rb.head++
-	if rb.head > rb.cap-1 {
-		rb.head = 0
+	x, _ = rb.TryGet()
+	return
+}
+
+// TryGet gets the first element from queue, it returns ErrEmpty if
+// the buffer is empty.
+func (rb *RingBuffer) TryGet() (interface{}, error) {
+	if rb.len == 0 {
+		return nil, ErrEmpty
 	}
+	x := rb.buf[rb.head]
+	rb.buf[rb.head] = nil
+	rb.head = (rb.head + 1) % rb.cap
 	rb.len--
-	return
+	return x, nil
+}
+
+// Len returns the number of elements in the ring buffer
+func (rb *RingBuffer) Len() int {
+	return rb.len
+}
+
+// Cap returns the capacity of the ring buffer
+func (rb *RingBuffer) Cap() int {
+	return rb.cap
+}
+
+// IsEmpty checks if the ring buffer is empty
+func (rb *RingBuffer) IsEmpty() bool {
+	return rb.len == 0
 }
 
 // IsFull checks if the ring buffer is full
@@ -55,16 +150,134 @@ func (rb *RingBuffer) IsFull() bool {
 // this method doesn't consume all elements
 func (rb *RingBuffer) LookAll() []interface{} {
 	all := make([]interface{}, rb.len)
-	j := 0
-	for i := rb.head; ; i++ {
-		if i > rb.cap-1 {
-			i = 0
+	for i := range all {
+		all[i] = rb.buf[(rb.head+i)%rb.cap]
+	}
+	return all
+}
+
+// BlockingRingBuffer is a ring buffer that is safe for concurrent use.
+// PutCtx and GetCtx block until the operation can proceed, which makes
+// it suitable for producer consumer pipelines.
+type BlockingRingBuffer struct {
+	mu      sync.Mutex
+	rb      *RingBuffer
+	changed chan struct{} // closed and replaced on every state change
+	closed  bool
+}
+
+// NewBlockingRingBuffer creates a blocking ring buffer with given capacity.
+// With WithOverwrite or WithGrowth, PutCtx never blocks.
+func NewBlockingRingBuffer(capacity int, opts ...RingBufferOption) *BlockingRingBuffer {
+	return &BlockingRingBuffer{
+		rb:      NewRingBuffer(capacity, opts...),
+		changed: make(chan struct{}),
+	}
+}
+
+// notify wakes up all waiters, it must be called with b.mu held.
+func (b *BlockingRingBuffer) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// TryPut puts x into the buffer without blocking. It returns ErrFull
+// if the buffer is full, or ErrClosed if the buffer is closed.
+func (b *BlockingRingBuffer) TryPut(x interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if err := b.rb.TryPut(x); err != nil {
+		return err
+	}
+	b.notify()
+	return nil
+}
+
+// PutCtx puts x into the buffer, it blocks until there is room for x,
+// the buffer is closed, or ctx is done.
+func (b *BlockingRingBuffer) PutCtx(ctx context.Context, x interface{}) error {
+	for {
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return ErrClosed
 		}
-		if i == rb.tail && j > 0 {
-			break
+		if err := b.rb.TryPut(x); err == nil {
+			b.notify()
+			b.mu.Unlock()
+			return nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		all[j] = rb.buf[i]
-		j++
 	}
-	return all
+}
+
+// TryGet gets the first element without blocking. It returns ErrEmpty
+// if the buffer is empty, or ErrClosed if the buffer is closed and
+// drained.
+func (b *BlockingRingBuffer) TryGet() (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	x, err := b.rb.TryGet()
+	if err != nil {
+		if b.closed {
+			return nil, ErrClosed
+		}
+		return nil, err
+	}
+	b.notify()
+	return x, nil
+}
+
+// GetCtx gets the first element, it blocks until an element is
+// available, the buffer is closed and drained, or ctx is done.
+func (b *BlockingRingBuffer) GetCtx(ctx context.Context) (interface{}, error) {
+	for {
+		b.mu.Lock()
+		if x, err := b.rb.TryGet(); err == nil {
+			b.notify()
+			b.mu.Unlock()
+			return x, nil
+		}
+		if b.closed {
+			b.mu.Unlock()
+			return nil, ErrClosed
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close closes the buffer. Puts fail after Close, and Gets fail after
+// the remaining elements are drained. Blocked callers are woken up.
+func (b *BlockingRingBuffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	b.notify()
+}
+
+// Len returns the number of elements in the buffer
+func (b *BlockingRingBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rb.Len()
 }
@@ -5,8 +5,11 @@
 package ds_test
 
 import (
+	"context"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"changkun.de/x/pkg/ds"
 )
@@ -42,3 +45,117 @@ func TestRingBuffer(t *testing.T) {
 		t.Errorf("not equal")
 	}
 }
+
+func TestRingBuffer_Empty(t *testing.T) {
+	rb := ds.NewRingBuffer(2)
+	if v := rb.Get(); v != nil {
+		t.Fatalf("get from empty buffer returns %v", v)
+	}
+	if _, err := rb.TryGet(); err != ds.ErrEmpty {
+		t.Fatalf("want %v, got %v", ds.ErrEmpty, err)
+	}
+	if rb.Len() != 0 || !rb.IsEmpty() || len(rb.LookAll()) != 0 {
+		t.Fatalf("empty buffer has length %v", rb.Len())
+	}
+	rb.Put(1)
+	rb.Put(2)
+	if err := rb.TryPut(3); err != ds.ErrFull {
+		t.Fatalf("want %v, got %v", ds.ErrFull, err)
+	}
+	if v := rb.Get(); v != 1 {
+		t.Fatalf("want 1, got %v", v)
+	}
+}
+
+func TestRingBuffer_Overwrite(t *testing.T) {
+	rb := ds.NewRingBuffer(3, ds.WithOverwrite())
+	for i := 0; i < 10; i++ {
+		if err := rb.TryPut(i); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	want := []interface{}{7, 8, 9}
+	if v := rb.LookAll(); !reflect.DeepEqual(v, want) {
+		t.Fatalf("want %v, got %v", want, v)
+	}
+	if v := rb.Get(); v != 7 || rb.Len() != 2 {
+		t.Fatalf("want 7, got %v", v)
+	}
+}
+
+func TestRingBuffer_Growth(t *testing.T) {
+	rb := ds.NewRingBuffer(0, ds.WithGrowth())
+	want := []interface{}{}
+	for i := 0; i < 10; i++ {
+		if i%3 == 2 {
+			rb.Get()
+			want = want[1:]
+		}
+		if !rb.Put(i) {
+			t.Fatalf("put failed")
+		}
+		want = append(want, i)
+	}
+	if v := rb.LookAll(); !reflect.DeepEqual(v, want) {
+		t.Fatalf("want %v, got %v", want, v)
+	}
+	if rb.Cap() < rb.Len() || rb.Cap() != 8 {
+		t.Fatalf("unexpected capacity %v, length %v", rb.Cap(), rb.Len())
+	}
+}
+
+func TestBlockingRingBuffer(t *testing.T) {
+	b := ds.NewBlockingRingBuffer(4)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.GetCtx(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	n := 1000
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	for p := 0; p < 2; p++ {
+		go func(p int) {
+			defer wg.Done()
+			for i := p; i < n; i += 2 {
+				if err := b.PutCtx(context.Background(), i); err != nil {
+					panic(err)
+				}
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		b.Close()
+	}()
+
+	var mu sync.Mutex
+	seen := map[int]bool{}
+	cg := sync.WaitGroup{}
+	cg.Add(3)
+	for c := 0; c < 3; c++ {
+		go func() {
+			defer cg.Done()
+			for {
+				v, err := b.GetCtx(context.Background())
+				if err == ds.ErrClosed {
+					return
+				}
+				mu.Lock()
+				seen[v.(int)] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	cg.Wait()
+	if len(seen) != n {
+		t.Fatalf("want %v elements, got %v", n, len(seen))
+	}
+	if err := b.PutCtx(context.Background(), 1); err != ds.ErrClosed {
+		t.Fatalf("want %v, got %v", ds.ErrClosed, err)
+	}
+	if _, err := b.TryGet(); err != ds.ErrClosed {
+		t.Fatalf("want %v, got %v", ds.ErrClosed, err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// SegmentTree maintains an array and answers the aggregate of any
+// range of the array. The aggregate is defined by combine, which
+// must be associative, and identity, which must satisfy
+// combine(identity, x) == combine(x, identity) == x. For instance,
+// sum with 0, min with +Inf, or matrix product with the identity.
+// Set and Query are O(log n).
+type SegmentTree[T any] struct {
+	n        int
+	tree     []T // tree[1] is the root, leaves start from tree[n]
+	combine  func(a, b T) T
+	identity T
+}
+
+// NewSegmentTree creates a segment tree of the array xs.
+func NewSegmentTree[T any](xs []T, combine func(a, b T) T, identity T) *SegmentTree[T] {
+	n := len(xs)
+	st := &SegmentTree[T]{n: n, tree: make([]T, 2*n), combine: combine, identity: identity}
+	copy(st.tree[n:], xs)
+	for i := n - 1; i > 0; i-- {
+		st.tree[i] = combine(st.tree[2*i], st.tree[2*i+1])
+	}
+	return st
+}
+
+// Len returns the length of the array.
+func (st *SegmentTree[T]) Len() int {
+	return st.n
+}
+
+// Get returns the i-th element of the array.
+func (st *SegmentTree[T]) Get(i int) T {
+	return st.tree[st.n+i]
+}
+
+// Set sets the i-th element of the array to x.
+func (st *SegmentTree[T]) Set(i int, x T) {
+	if i < 0 || i >= st.n {
+		panic("ds: index out of range")
+	}
+	i += st.n
+	st.tree[i] = x
+	for i > 1 {
+		i /= 2
+		st.tree[i] = st.combine(st.tree[2*i], st.tree[2*i+1])
+	}
+}
+
+// Query returns the aggregate of the elements in [l, r), or the
+// identity if the range is empty.
+func (st *SegmentTree[T]) Query(l, r int) T {
+	if l < 0 || r > st.n {
+		panic("ds: index out of range")
+	}
+	// aggregate from both ends, in order, for non-commutative combine.
+	left, right := st.identity, st.identity
+	for l, r = l+st.n, r+st.n; l < r; l, r = l/2, r/2 {
+		if l&1 == 1 {
+			left = st.combine(left, st.tree[l])
+			l++
+		}
+		if r&1 == 1 {
+			r--
+			right = st.combine(st.tree[r], right)
+		}
+	}
+	return st.combine(left, right)
+}
@@ -0,0 +1,46 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestSegmentTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 7, 64, 100} {
+		// string concatenation is associative but not commutative.
+		xs := make([]string, n)
+		for i := range xs {
+			xs[i] = strconv.Itoa(i) + ","
+		}
+		st := ds.NewSegmentTree(xs, func(a, b string) string { return a + b }, "")
+		if st.Len() != n {
+			t.Fatalf("want len %v, got %v", n, st.Len())
+		}
+		for i := 0; i < 200 && n > 0; i++ {
+			j := r.Intn(n)
+			xs[j] = strconv.Itoa(r.Intn(100)) + ","
+			st.Set(j, xs[j])
+			if st.Get(j) != xs[j] {
+				t.Fatalf("get %v, want %v, got %v", j, xs[j], st.Get(j))
+			}
+
+			l := r.Intn(n + 1)
+			h := l + r.Intn(n+1-l)
+			want := ""
+			for _, x := range xs[l:h] {
+				want += x
+			}
+			if got := st.Query(l, h); got != want {
+				t.Fatalf("query [%v, %v), want %q, got %q", l, h, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Errors of the probabilistic sketches.
+var (
+	ErrIncompatible = errors.New("sketches are incompatible")
+	ErrBadEncoding  = errors.New("bad encoding of sketch")
+)
+
+// kinds of the binary encoding of sketches, every encoding starts
+// with its kind and the version of the format.
+const (
+	sketchBloom byte = iota + 1
+	sketchCountingBloom
+	sketchCountMin
+	sketchHyperLogLog
+	sketchTDigest
+
+	sketchVersion byte = 1
+)
+
+// sketchHash hashes data by 64-bit FNV-1a followed by the finalizer of
+// MurmurHash3. Unlike maphash, it is stable across processes, which
+// is required for merging sketches that are built elsewhere.
+func sketchHash(data []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, c := range data {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+	return fmix64(h)
+}
+
+func fmix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// sketchReader decodes the binary encoding of a sketch, the first
+// error is kept and all later reads return zero.
+type sketchReader struct {
+	b   []byte
+	err error
+}
+
+func newSketchReader(b []byte, kind byte) *sketchReader {
+	r := &sketchReader{b: b}
+	if r.byte() != kind || r.byte() != sketchVersion {
+		r.err = ErrBadEncoding
+	}
+	return r
+}
+
+func (r *sketchReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.b) < n {
+		r.err = ErrBadEncoding
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *sketchReader) byte() byte {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *sketchReader) uint32() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *sketchReader) uint64() uint64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (r *sketchReader) float64() float64 {
+	return math.Float64frombits(r.uint64())
+}
+
+// length reads a length whose payload has at least size bytes per
+// element, and rejects lengths that exceed the remaining input.
+func (r *sketchReader) length(size int) int {
+	n := r.uint64()
+	if r.err == nil && n > uint64(len(r.b)/size) {
+		r.err = ErrBadEncoding
+		return 0
+	}
+	return int(n)
+}
+
+// done reports the first error, or an error if the input is not
+// entirely consumed.
+func (r *sketchReader) done() error {
+	if r.err == nil && len(r.b) != 0 {
+		r.err = ErrBadEncoding
+	}
+	return r.err
+}
+
+func appendSketchHeader(b []byte, kind byte) []byte {
+	return append(b, kind, sketchVersion)
+}
+
+func appendFloat64(b []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(b, math.Float64bits(f))
+}
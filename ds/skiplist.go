@@ -41,7 +41,7 @@ func (s *SkipList) Set(k interface{}, v interface{}) {
 	update := make([]*skiplistitem, s.level()+1, s.effectiveMaxLevel()+1) // make(type, len, cap)
 
 	x := s.path(s.header, update, k)
-	if x != nil && (s.less(x.k, k) || s.less(x.k, k)) { // if key exist, update
+	if x != nil && !s.less(k, x.k) { // if key exist, update
 		x.v = v
 		return
 	}
@@ -89,7 +89,7 @@ func (s *SkipList) randomLevel() (n int) {
 // Get returns corresponding v with given k.
 func (s *SkipList) Get(k interface{}) (v interface{}, ok bool) {
 	x := s.path(s.header, nil, k)
-	if x == nil || (s.less(x.k, k) || s.less(x.k, k)) {
+	if x == nil || s.less(k, x.k) {
 		return nil, false
 	}
 	return x.v, true
@@ -98,7 +98,7 @@ func (s *SkipList) Get(k interface{}) (v interface{}, ok bool) {
 // Search returns true if k is founded in the skiplist.
 func (s *SkipList) Search(k interface{}) (ok bool) {
 	x := s.path(s.header, nil, k)
-	if x != nil {
+	if x != nil && !s.less(k, x.k) {
 		ok = true
 		return
 	}
@@ -121,7 +121,7 @@ func (s *SkipList) Del(k interface{}) (v interface{}, ok bool) {
 	update := make([]*skiplistitem, s.level()+1, s.effectiveMaxLevel())
 
 	x := s.path(s.header, update, k)
-	if x == nil || (s.less(x.k, k) || s.less(x.k, k)) {
+	if x == nil || s.less(k, x.k) {
 		ok = false
 		return
 	}
@@ -0,0 +1,35 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestStack(t *testing.T) {
+	s := ds.NewStack()
+	for i := 0; i < 10; i++ {
+		s.Push(i)
+	}
+	for i := 9; i >= 0; i-- {
+		if v := s.Pop(); v != i {
+			t.Fatalf("want %v, got %v", i, v)
+		}
+	}
+}
+
+func TestStackPopSingle(t *testing.T) {
+	s := ds.NewStack()
+	s.Push("x")
+	if v := s.Pop(); v != "x" {
+		t.Fatalf("want x, got %v", v)
+	}
+	s.Push("y")
+	if v := s.Pop(); v != "y" {
+		t.Fatalf("want y, got %v", v)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// TDigest is a quantile sketch. It summarizes a distribution by
+// weighted centroids, which are small near the tails and large near
+// the median, so that extreme quantiles are estimated accurately.
+// The size of the sketch is O(compression).
+// Paper: Dunning, Ted and Ertl, Otmar (2019). "Computing Extremely
+// Accurate Quantiles Using t-Digests". arXiv:1902.04023
+type TDigest struct {
+	compression float64
+	centroids   []centroid // sorted by mean
+	buffer      []centroid // unmerged
+	count       float64
+	min, max    float64
+}
+
+type centroid struct {
+	mean, weight float64
+}
+
+// NewTDigest creates a t-digest of given compression, a larger
+// compression is more accurate and uses more memory, 100 is a
+// common choice.
+func NewTDigest(compression float64) *TDigest {
+	if compression < 10 {
+		panic("ds: compression of t-digest must be at least 10")
+	}
+	return &TDigest{compression: compression, min: math.Inf(1), max: math.Inf(-1)}
+}
+
+// Add adds x to the sketch.
+func (t *TDigest) Add(x float64) {
+	t.AddWeighted(x, 1)
+}
+
+// AddWeighted adds x with weight w to the sketch.
+func (t *TDigest) AddWeighted(x, w float64) {
+	if math.IsNaN(x) || w <= 0 {
+		return
+	}
+	t.buffer = append(t.buffer, centroid{x, w})
+	t.count += w
+	t.min = math.Min(t.min, x)
+	t.max = math.Max(t.max, x)
+	if len(t.buffer) >= int(5*t.compression) {
+		t.compress()
+	}
+}
+
+// Count returns the total weight added to the sketch.
+func (t *TDigest) Count() float64 {
+	return t.count
+}
+
+// k1 is the scale function that bounds the weight of centroids by
+// the quantiles they cover, and kinv is its inverse.
+func (t *TDigest) k1(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+func (t *TDigest) kinv(k float64) float64 {
+	if k >= t.compression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/t.compression) + 1) / 2
+}
+
+// compress merges the buffer into the centroids.
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := append(t.buffer, t.centroids...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, len(t.centroids)+1)
+	cur, sofar := all[0], 0.0
+	limit := t.count * t.kinv(t.k1(0)+1)
+	for _, c := range all[1:] {
+		if sofar+cur.weight+c.weight <= limit {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+			continue
+		}
+		sofar += cur.weight
+		merged = append(merged, cur)
+		limit = t.count * t.kinv(t.k1(sofar/t.count)+1)
+		cur = c
+	}
+	t.centroids = append(merged, cur)
+	t.buffer = t.buffer[:0]
+}
+
+// points iterates the piecewise linear approximation of the cumulative
+// distribution, every centroid is placed at the middle of its weight.
+func (t *TDigest) points(op func(x, w float64) bool) {
+	if !op(t.min, 0) {
+		return
+	}
+	sofar := 0.0
+	for _, c := range t.centroids {
+		if !op(c.mean, sofar+c.weight/2) {
+			return
+		}
+		sofar += c.weight
+	}
+	op(t.max, t.count)
+}
+
+// Quantile estimates the value at quantile q in [0, 1].
+// It returns NaN if the sketch is empty.
+func (t *TDigest) Quantile(q float64) float64 {
+	if t.count == 0 || q < 0 || q > 1 {
+		return math.NaN()
+	}
+	t.compress()
+	target := q * t.count
+	var x0, w0 float64
+	ret := t.max
+	t.points(func(x, w float64) bool {
+		if w >= target {
+			if w == w0 {
+				ret = x
+			} else {
+				ret = x0 + (x-x0)*(target-w0)/(w-w0)
+			}
+			return false
+		}
+		x0, w0 = x, w
+		return true
+	})
+	return ret
+}
+
+// CDF estimates the fraction of the distribution that is not greater
+// than x. It returns NaN if the sketch is empty.
+func (t *TDigest) CDF(x float64) float64 {
+	if t.count == 0 {
+		return math.NaN()
+	}
+	switch {
+	case x < t.min:
+		return 0
+	case x >= t.max:
+		return 1
+	}
+	t.compress()
+	x0, w0 := t.min, 0.0
+	ret := 1.0
+	t.points(func(xi, w float64) bool {
+		if xi > x {
+			ret = (w0 + (w-w0)*(x-x0)/(xi-x0)) / t.count
+			return false
+		}
+		x0, w0 = xi, w
+		return true
+	})
+	return ret
+}
+
+// Merge merges o into t, and t becomes the sketch of both
+// distributions. Both sketches must have the same compression.
+func (t *TDigest) Merge(o *TDigest) error {
+	if t.compression != o.compression {
+		return ErrIncompatible
+	}
+	if o.count == 0 {
+		return nil
+	}
+	t.buffer = append(t.buffer, o.centroids...)
+	t.buffer = append(t.buffer, o.buffer...)
+	t.count += o.count
+	t.min = math.Min(t.min, o.min)
+	t.max = math.Max(t.max, o.max)
+	t.compress()
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (t *TDigest) MarshalBinary() ([]byte, error) {
+	t.compress()
+	b := make([]byte, 0, 2+40+16*len(t.centroids))
+	b = appendSketchHeader(b, sketchTDigest)
+	b = appendFloat64(b, t.compression)
+	b = appendFloat64(b, t.min)
+	b = appendFloat64(b, t.max)
+	b = binary.BigEndian.AppendUint64(b, uint64(len(t.centroids)))
+	for _, c := range t.centroids {
+		b = appendFloat64(b, c.mean)
+		b = appendFloat64(b, c.weight)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (t *TDigest) UnmarshalBinary(data []byte) error {
+	r := newSketchReader(data, sketchTDigest)
+	o := &TDigest{compression: r.float64(), min: r.float64(), max: r.float64()}
+	n := r.length(16)
+	if r.err != nil {
+		return r.err
+	}
+	if !(o.compression >= 10) {
+		return ErrBadEncoding
+	}
+	o.centroids = make([]centroid, n)
+	for i := range o.centroids {
+		c := centroid{r.float64(), r.float64()}
+		if math.IsNaN(c.mean) || !(c.weight > 0) || (i > 0 && c.mean < o.centroids[i-1].mean) {
+			return ErrBadEncoding
+		}
+		o.centroids[i] = c
+		o.count += c.weight
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	*t = *o
+	return nil
+}
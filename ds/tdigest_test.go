@@ -0,0 +1,57 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"changkun.de/x/pkg/ds"
+)
+
+func TestTDigest(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a, b := ds.NewTDigest(100), ds.NewTDigest(100)
+	xs := make([]float64, 100000)
+	for i := range xs {
+		xs[i] = r.NormFloat64()
+		if i%2 == 0 {
+			a.Add(xs[i])
+		} else {
+			b.Add(xs[i])
+		}
+	}
+	sort.Float64s(xs)
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	data, _ := a.MarshalBinary()
+	d := &ds.TDigest{}
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if d.Count() != float64(len(xs)) {
+		t.Fatalf("want count %v, got %v", len(xs), d.Count())
+	}
+
+	for _, q := range []float64{0, 0.001, 0.01, 0.1, 0.5, 0.9, 0.99, 0.999, 1} {
+		want := xs[int(q*float64(len(xs)-1))]
+		got := d.Quantile(q)
+		// compare the quantiles of the estimates, the error bound
+		// is tighter at the tails.
+		rank := float64(sort.SearchFloat64s(xs, got)) / float64(len(xs))
+		if math.Abs(rank-q) > 0.01*math.Min(1, 10*q*(1-q)+0.05) {
+			t.Fatalf("quantile %v, want %v, got %v at %v", q, want, got, rank)
+		}
+		if cdf := d.CDF(want); math.Abs(cdf-q) > 0.01 {
+			t.Fatalf("cdf of %v, want %v, got %v", want, q, cdf)
+		}
+	}
+	if !math.IsNaN(ds.NewTDigest(100).Quantile(0.5)) {
+		t.Fatalf("quantile of empty sketch is not NaN")
+	}
+}
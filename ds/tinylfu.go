@@ -0,0 +1,214 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ds
+
+// list segments of TinyLFUCache
+const (
+	tinyWindow uint8 = iota
+	tinyProbation
+	tinyProtected
+)
+
+// TinyLFUCache implements the W-TinyLFU cache. New entries are
+// admitted into a small LRU window, and entries that leave the window
+// compete with the eviction victim of the main segmented LRU. A
+// count-min sketch estimates access frequencies for the competition,
+// so that one-hit wonders of a scan cannot flush frequent entries.
+// Paper: Einziger, Gil and Friedman, Roy and Manes, Ben (2017).
+// "TinyLFU: A Highly Efficient Cache Admission Policy". ACM
+// Transactions on Storage 13 (4): 35:1–35:31
+type TinyLFUCache[K comparable, V any] struct {
+	items map[K]*centry[K, V]
+	hash  func(K) uint64
+
+	window, probation, protected clist[K, V]
+	windowCap, protectedCap      int
+	mainCap                      int
+
+	sketch *cmsketch
+}
+
+// NewTinyLFUCache creates a W-TinyLFU cache with given capacity.
+// The hash function is used by the frequency sketch, if it is nil,
+// keys are hashed by their fmt representation, which is correct
+// but slow.
+func NewTinyLFUCache[K comparable, V any](capacity int, hash func(K) uint64) *TinyLFUCache[K, V] {
+	if hash == nil {
+		hash = fmtHasher[K]()
+	}
+	windowCap := 0
+	if capacity > 0 {
+		windowCap = maxInt(1, capacity/100)
+	}
+	mainCap := capacity - windowCap
+	return &TinyLFUCache[K, V]{
+		items:        make(map[K]*centry[K, V], capacity),
+		hash:         hash,
+		windowCap:    windowCap,
+		mainCap:      mainCap,
+		protectedCap: mainCap * 8 / 10,
+		sketch:       newCMSketch(capacity),
+	}
+}
+
+// Get returns the cached value of key and reports whether it was found.
+func (c *TinyLFUCache[K, V]) Get(key K) (v V, ok bool) {
+	c.sketch.add(c.hash(key))
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.hit(e)
+	return e.v, true
+}
+
+// Put stores the value by given key.
+func (c *TinyLFUCache[K, V]) Put(key K, value V) {
+	if e, ok := c.items[key]; ok {
+		e.v = value
+		c.hit(e)
+		return
+	}
+	if c.windowCap == 0 {
+		return
+	}
+	c.sketch.add(c.hash(key))
+
+	e := &centry[K, V]{k: key, v: value, seg: tinyWindow}
+	c.window.pushFront(e)
+	c.items[key] = e
+	if c.window.len <= c.windowCap {
+		return
+	}
+
+	// the window overflows, its victim becomes a candidate of
+	// the main cache.
+	candidate := c.window.back()
+	c.window.remove(candidate)
+	if c.probation.len+c.protected.len < c.mainCap {
+		candidate.seg = tinyProbation
+		c.probation.pushFront(candidate)
+		return
+	}
+	victim := c.probation.back()
+	if victim == nil {
+		victim = c.protected.back()
+	}
+	if victim == nil || c.sketch.estimate(c.hash(candidate.k)) <= c.sketch.estimate(c.hash(victim.k)) {
+		delete(c.items, candidate.k)
+		return
+	}
+	c.list(victim.seg).remove(victim)
+	delete(c.items, victim.k)
+	candidate.seg = tinyProbation
+	c.probation.pushFront(candidate)
+}
+
+// Del deletes the stored value by given key.
+func (c *TinyLFUCache[K, V]) Del(key K) {
+	if e, ok := c.items[key]; ok {
+		c.list(e.seg).remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *TinyLFUCache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// hit promotes an accessed entry.
+func (c *TinyLFUCache[K, V]) hit(e *centry[K, V]) {
+	switch e.seg {
+	case tinyWindow:
+		c.window.moveToFront(e)
+	case tinyProtected:
+		c.protected.moveToFront(e)
+	case tinyProbation:
+		c.probation.remove(e)
+		e.seg = tinyProtected
+		c.protected.pushFront(e)
+		if c.protected.len > c.protectedCap {
+			demoted := c.protected.back()
+			c.protected.remove(demoted)
+			demoted.seg = tinyProbation
+			c.probation.pushFront(demoted)
+		}
+	}
+}
+
+func (c *TinyLFUCache[K, V]) list(seg uint8) *clist[K, V] {
+	switch seg {
+	case tinyWindow:
+		return &c.window
+	case tinyProbation:
+		return &c.probation
+	default:
+		return &c.protected
+	}
+}
+
+// cmsketch is a count-min sketch with 4-bit saturating counters that
+// are halved periodically, so that the estimated frequencies follow
+// recent history.
+type cmsketch struct {
+	rows      [4][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+func newCMSketch(capacity int) *cmsketch {
+	width := 16
+	for width < capacity {
+		width <<= 1
+	}
+	s := &cmsketch{
+		mask:    uint64(width - 1),
+		resetAt: 10 * maxInt(capacity, 1),
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+var cmseeds = [4]uint64{0xc3a5c85c97cb3127, 0xb492b66fbe98f273, 0x9ae16a3b2f90404f, 0xcbf29ce484222325}
+
+func (s *cmsketch) index(h uint64, i int) uint64 {
+	h = (h ^ cmseeds[i]) * 0x9e3779b97f4a7c15
+	return (h ^ h>>32) & s.mask
+}
+
+func (s *cmsketch) add(h uint64) {
+	for i := range s.rows {
+		if j := s.index(h, i); s.rows[i][j] < 15 {
+			s.rows[i][j]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *cmsketch) estimate(h uint64) uint8 {
+	min := uint8(15)
+	for i := range s.rows {
+		if v := s.rows[i][s.index(h, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+func (s *cmsketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
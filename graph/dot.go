@@ -0,0 +1,37 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteDOT writes the graph in the DOT language of Graphviz. Vertices
+// are named by their fmt representation, and edges are labeled by
+// their weights.
+func (g *Graph[V]) WriteDOT(w io.Writer) error {
+	kind, arrow := "graph", "--"
+	if g.directed {
+		kind, arrow = "digraph", "->"
+	}
+	name := func(v V) string {
+		return strconv.Quote(fmt.Sprint(v))
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "%s {\n", kind)
+	for _, v := range g.verts {
+		fmt.Fprintf(b, "\t%s;\n", name(v))
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(b, "\t%s %s %s [label=%q];\n", name(e.From), arrow, name(e.To),
+			strconv.FormatFloat(e.Weight, 'g', -1, 64))
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
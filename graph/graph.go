@@ -0,0 +1,249 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package graph implements directed and undirected weighted graphs,
+// and the common algorithms on them: traversals, shortest paths,
+// topological sort, strongly connected components and minimum
+// spanning trees.
+//
+// Vertices and edges are visited in the order they were added, so
+// that all algorithms are deterministic.
+package graph
+
+import "errors"
+
+// Errors of the graph algorithms.
+var (
+	ErrVertexNotFound = errors.New("vertex not found")
+	ErrNegativeWeight = errors.New("negative edge weight")
+	ErrNegativeCycle  = errors.New("negative cycle")
+	ErrDirected       = errors.New("graph is directed")
+	ErrUndirected     = errors.New("graph is undirected")
+)
+
+// Edge is a weighted edge from From to To. An edge of an undirected
+// graph has no direction.
+type Edge[V comparable] struct {
+	From, To V
+	Weight   float64
+}
+
+// Graph is a weighted graph of vertices of type V, stored as
+// adjacency lists. Parallel edges are not allowed, adding an existing
+// edge updates its weight.
+type Graph[V comparable] struct {
+	directed bool
+	index    map[V]int
+	verts    []V
+	adj      [][]halfedge // out edges of every vertex
+	edges    int
+}
+
+type halfedge struct {
+	to     int
+	weight float64
+}
+
+// NewDirected creates an empty directed graph.
+func NewDirected[V comparable]() *Graph[V] {
+	return &Graph[V]{directed: true, index: map[V]int{}}
+}
+
+// NewUndirected creates an empty undirected graph.
+func NewUndirected[V comparable]() *Graph[V] {
+	return &Graph[V]{index: map[V]int{}}
+}
+
+// Directed reports whether the graph is directed.
+func (g *Graph[V]) Directed() bool {
+	return g.directed
+}
+
+// Order returns the number of vertices.
+func (g *Graph[V]) Order() int {
+	return len(g.verts)
+}
+
+// Size returns the number of edges.
+func (g *Graph[V]) Size() int {
+	return g.edges
+}
+
+// AddVertex adds v to the graph if it does not exist.
+func (g *Graph[V]) AddVertex(v V) {
+	g.id(v)
+}
+
+// id returns the index of v, v is added if it does not exist.
+func (g *Graph[V]) id(v V) int {
+	if i, ok := g.index[v]; ok {
+		return i
+	}
+	g.index[v] = len(g.verts)
+	g.verts = append(g.verts, v)
+	g.adj = append(g.adj, nil)
+	return len(g.verts) - 1
+}
+
+// HasVertex reports whether v is in the graph.
+func (g *Graph[V]) HasVertex(v V) bool {
+	_, ok := g.index[v]
+	return ok
+}
+
+// Vertices returns all vertices in the order they were added.
+func (g *Graph[V]) Vertices() []V {
+	return append([]V(nil), g.verts...)
+}
+
+// RemoveVertex removes v and all its edges in O(V+E).
+func (g *Graph[V]) RemoveVertex(v V) {
+	i, ok := g.index[v]
+	if !ok {
+		return
+	}
+	for u := range g.adj {
+		if u == i {
+			continue
+		}
+		if j := g.find(u, i); j >= 0 {
+			g.adj[u] = append(g.adj[u][:j], g.adj[u][j+1:]...)
+			if g.directed {
+				g.edges--
+			}
+		}
+	}
+	g.edges -= len(g.adj[i])
+
+	g.verts = append(g.verts[:i], g.verts[i+1:]...)
+	g.adj = append(g.adj[:i], g.adj[i+1:]...)
+	delete(g.index, v)
+	for j := i; j < len(g.verts); j++ {
+		g.index[g.verts[j]] = j
+	}
+	for u := range g.adj {
+		for j := range g.adj[u] {
+			if g.adj[u][j].to > i {
+				g.adj[u][j].to--
+			}
+		}
+	}
+}
+
+// find returns the position of the edge from u to v in the
+// adjacency list of u, or -1 if there is no such edge.
+func (g *Graph[V]) find(u, v int) int {
+	for j, e := range g.adj[u] {
+		if e.to == v {
+			return j
+		}
+	}
+	return -1
+}
+
+// AddEdge adds an edge from u to v of weight w, the vertices are
+// added if they do not exist. If the edge exists, its weight is
+// updated.
+func (g *Graph[V]) AddEdge(u, v V, w float64) {
+	i, j := g.id(u), g.id(v)
+	if k := g.find(i, j); k >= 0 {
+		g.adj[i][k].weight = w
+		if !g.directed && i != j {
+			g.adj[j][g.find(j, i)].weight = w
+		}
+		return
+	}
+	g.adj[i] = append(g.adj[i], halfedge{j, w})
+	if !g.directed && i != j {
+		g.adj[j] = append(g.adj[j], halfedge{i, w})
+	}
+	g.edges++
+}
+
+// RemoveEdge removes the edge from u to v.
+func (g *Graph[V]) RemoveEdge(u, v V) {
+	i, ok1 := g.index[u]
+	j, ok2 := g.index[v]
+	if !ok1 || !ok2 {
+		return
+	}
+	k := g.find(i, j)
+	if k < 0 {
+		return
+	}
+	g.adj[i] = append(g.adj[i][:k], g.adj[i][k+1:]...)
+	if !g.directed && i != j {
+		k = g.find(j, i)
+		g.adj[j] = append(g.adj[j][:k], g.adj[j][k+1:]...)
+	}
+	g.edges--
+}
+
+// Weight returns the weight of the edge from u to v.
+// It returns false if there is no such edge.
+func (g *Graph[V]) Weight(u, v V) (w float64, ok bool) {
+	i, ok1 := g.index[u]
+	j, ok2 := g.index[v]
+	if !ok1 || !ok2 {
+		return
+	}
+	if k := g.find(i, j); k >= 0 {
+		return g.adj[i][k].weight, true
+	}
+	return
+}
+
+// Neighbors iterates the out edges of u with op, i.e. all edges
+// of u if the graph is undirected. The iteration stops if op
+// returns false.
+func (g *Graph[V]) Neighbors(u V, op func(v V, w float64) bool) {
+	i, ok := g.index[u]
+	if !ok {
+		return
+	}
+	for _, e := range g.adj[i] {
+		if !op(g.verts[e.to], e.weight) {
+			return
+		}
+	}
+}
+
+// Edges returns all edges. An edge of an undirected graph is returned
+// once, from the vertex that was added earlier.
+func (g *Graph[V]) Edges() []Edge[V] {
+	edges := make([]Edge[V], 0, g.edges)
+	for u := range g.adj {
+		for _, e := range g.adj[u] {
+			if g.directed || u <= e.to {
+				edges = append(edges, Edge[V]{g.verts[u], g.verts[e.to], e.weight})
+			}
+		}
+	}
+	return edges
+}
+
+// Reverse returns a copy of the graph that all edges are reversed.
+// The copy of an undirected graph is identical to the graph.
+func (g *Graph[V]) Reverse() *Graph[V] {
+	r := &Graph[V]{
+		directed: g.directed,
+		index:    make(map[V]int, len(g.verts)),
+		verts:    append([]V(nil), g.verts...),
+		adj:      make([][]halfedge, len(g.adj)),
+		edges:    g.edges,
+	}
+	for v, i := range g.index {
+		r.index[v] = i
+	}
+	for u := range g.adj {
+		for _, e := range g.adj[u] {
+			if g.directed {
+				r.adj[e.to] = append(r.adj[e.to], halfedge{u, e.weight})
+			} else {
+				r.adj[u] = append(r.adj[u], e)
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"changkun.de/x/pkg/graph"
+)
+
+func TestGraph(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		g := graph.NewUndirected[string]()
+		if directed {
+			g = graph.NewDirected[string]()
+		}
+		g.AddEdge("a", "b", 1)
+		g.AddEdge("b", "c", 2)
+		g.AddEdge("c", "a", 3)
+		g.AddEdge("c", "c", 4)
+		g.AddEdge("a", "b", 5) // update
+		g.AddVertex("d")
+		if g.Order() != 4 || g.Size() != 4 {
+			t.Fatalf("want 4 vertices and 4 edges, got %v and %v", g.Order(), g.Size())
+		}
+		if w, ok := g.Weight("a", "b"); !ok || w != 5 {
+			t.Fatalf("want weight 5, got %v", w)
+		}
+		if _, ok := g.Weight("b", "a"); ok != !directed {
+			t.Fatalf("reverse edge exists: %v, directed: %v", ok, directed)
+		}
+		if w, ok := g.Reverse().Weight("b", "a"); !ok || w != 5 {
+			t.Fatalf("want weight 5 of reversed edge, got %v", w)
+		}
+
+		g.RemoveVertex("b")
+		if !reflect.DeepEqual(g.Vertices(), []string{"a", "c", "d"}) || g.Size() != 2 {
+			t.Fatalf("remove vertex, got %v and %v edges", g.Vertices(), g.Size())
+		}
+		want := []graph.Edge[string]{{"a", "c", 3}, {"c", "c", 4}}
+		if directed {
+			want = []graph.Edge[string]{{"c", "a", 3}, {"c", "c", 4}}
+		}
+		if got := g.Edges(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want edges %v, got %v", want, got)
+		}
+		g.RemoveEdge("c", "a")
+		g.RemoveEdge("c", "c")
+		if g.Size() != 0 {
+			t.Fatalf("remove edges, got %v edges", g.Size())
+		}
+	}
+}
+
+func TestTraversal(t *testing.T) {
+	g := graph.NewDirected[int]()
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(1, 3, 1)
+	g.AddEdge(2, 4, 1)
+	g.AddEdge(3, 4, 1)
+	g.AddEdge(4, 1, 1)
+	g.AddEdge(5, 1, 1)
+
+	collect := func(it *graph.Iterator[int]) (vs, depths []int) {
+		for it.Next() {
+			vs = append(vs, it.Vertex())
+			depths = append(depths, it.Depth())
+		}
+		return
+	}
+	vs, depths := collect(g.BFS(1))
+	if !reflect.DeepEqual(vs, []int{1, 2, 3, 4}) || !reflect.DeepEqual(depths, []int{0, 1, 1, 2}) {
+		t.Fatalf("bfs, got %v at %v", vs, depths)
+	}
+	vs, depths = collect(g.DFS(1))
+	if !reflect.DeepEqual(vs, []int{1, 2, 4, 3}) || !reflect.DeepEqual(depths, []int{0, 1, 2, 1}) {
+		t.Fatalf("dfs, got %v at %v", vs, depths)
+	}
+	if vs, _ = collect(g.BFS(42)); vs != nil {
+		t.Fatalf("traversal from a missing vertex, got %v", vs)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := graph.NewDirected[string]()
+	g.AddEdge("a", `b"c`, 1.5)
+	var b strings.Builder
+	if err := g.WriteDOT(&b); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	want := `digraph {
+	"a";
+	"b\"c";
+	"a" -> "b\"c" [label="1.5"];
+}
+`
+	if b.String() != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph
+
+import "sort"
+
+// MinimumSpanningTree returns the edges of a minimum spanning forest
+// of an undirected graph and their total weight in O(E log E). The
+// forest has a tree for every connected component.
+// Paper: Kruskal, Joseph B. (1956). "On the shortest spanning subtree
+// of a graph and the traveling salesman problem". Proceedings of the
+// American Mathematical Society 7 (1): 48–50
+func MinimumSpanningTree[V comparable](g *Graph[V]) ([]Edge[V], float64, error) {
+	if g.directed {
+		return nil, 0, ErrDirected
+	}
+	edges := g.Edges()
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].Weight < edges[j].Weight })
+
+	parent := make([]int, len(g.verts))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]] // path halving
+			x = parent[x]
+		}
+		return x
+	}
+
+	var (
+		tree  []Edge[V]
+		total float64
+	)
+	for _, e := range edges {
+		a, b := find(g.index[e.From]), find(g.index[e.To])
+		if a == b {
+			continue
+		}
+		parent[a] = b
+		tree = append(tree, e)
+		total += e.Weight
+	}
+	return tree, total, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph
+
+// StronglyConnectedComponents returns the strongly connected
+// components of the graph in O(V+E). The components are in reverse
+// topological order of the condensation of the graph, i.e. no edge
+// goes from a component to a later one. The components of an
+// undirected graph are its connected components.
+// Paper: Tarjan, Robert E. (1972). "Depth-first search and linear graph
+// algorithms". SIAM Journal on Computing 1 (2): 146–160
+func StronglyConnectedComponents[V comparable](g *Graph[V]) [][]V {
+	type frame struct{ v, next int }
+
+	n := len(g.verts)
+	index, low := make([]int, n), make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var (
+		counter int
+		stack   []int
+		path    []frame
+		comps   [][]V
+	)
+	push := func(v int) {
+		index[v], low[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		path = append(path, frame{v, 0})
+	}
+	for s := 0; s < n; s++ {
+		if index[s] >= 0 {
+			continue
+		}
+		push(s)
+		for len(path) > 0 {
+			f := &path[len(path)-1]
+			if f.next < len(g.adj[f.v]) {
+				w := g.adj[f.v][f.next].to
+				f.next++
+				if index[w] < 0 {
+					push(w)
+				} else if onStack[w] && index[w] < low[f.v] {
+					low[f.v] = index[w]
+				}
+				continue
+			}
+
+			v := f.v
+			path = path[:len(path)-1]
+			if len(path) > 0 {
+				if p := path[len(path)-1].v; low[v] < low[p] {
+					low[p] = low[v]
+				}
+			}
+			if low[v] != index[v] {
+				continue
+			}
+			// v is the root of a component.
+			var comp []V
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp = append(comp, g.verts[w])
+				if w == v {
+					break
+				}
+			}
+			comps = append(comps, comp)
+		}
+	}
+	return comps
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"math"
+
+	"changkun.de/x/pkg/ds"
+)
+
+// ShortestPaths is the shortest paths from a source vertex to all
+// other vertices.
+type ShortestPaths[V comparable] struct {
+	g    *Graph[V]
+	dist []float64 // +Inf if unreachable
+	prev []int     // previous vertex on the path, -1 if none
+}
+
+func newShortestPaths[V comparable](g *Graph[V], src int) *ShortestPaths[V] {
+	sp := &ShortestPaths[V]{
+		g:    g,
+		dist: make([]float64, len(g.verts)),
+		prev: make([]int, len(g.verts)),
+	}
+	for i := range sp.dist {
+		sp.dist[i], sp.prev[i] = math.Inf(1), -1
+	}
+	sp.dist[src] = 0
+	return sp
+}
+
+// Dist returns the distance from the source to v.
+// It returns false if v is not reachable.
+func (sp *ShortestPaths[V]) Dist(v V) (float64, bool) {
+	i, ok := sp.g.index[v]
+	if !ok || math.IsInf(sp.dist[i], 1) {
+		return math.Inf(1), false
+	}
+	return sp.dist[i], true
+}
+
+// PathTo returns the vertices of the shortest path from the source
+// to v, both inclusive. It returns nil if v is not reachable.
+func (sp *ShortestPaths[V]) PathTo(v V) []V {
+	i, ok := sp.g.index[v]
+	if !ok || math.IsInf(sp.dist[i], 1) {
+		return nil
+	}
+	return sp.g.path(sp.prev, i)
+}
+
+// path follows prev from i back to the source and returns the path
+// from the source to i.
+func (g *Graph[V]) path(prev []int, i int) []V {
+	var path []V
+	for ; i >= 0; i = prev[i] {
+		path = append(path, g.verts[i])
+	}
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+	return path
+}
+
+// Dijkstra computes the shortest paths from src in O((V+E) log V).
+// All edge weights must be non-negative.
+func Dijkstra[V comparable](g *Graph[V], src V) (*ShortestPaths[V], error) {
+	s, ok := g.index[src]
+	if !ok {
+		return nil, ErrVertexNotFound
+	}
+	for u := range g.adj {
+		for _, e := range g.adj[u] {
+			if e.weight < 0 {
+				return nil, ErrNegativeWeight
+			}
+		}
+	}
+
+	sp := newShortestPaths(g, s)
+	pq := ds.NewIndexedPriorityQueue[int](func(a, b float64) bool { return a < b })
+	pq.Push(s, 0)
+	for pq.Len() > 0 {
+		u, d, _ := pq.Pop()
+		for _, e := range g.adj[u] {
+			if nd := d + e.weight; nd < sp.dist[e.to] {
+				sp.dist[e.to], sp.prev[e.to] = nd, u
+				pq.Push(e.to, nd)
+			}
+		}
+	}
+	return sp, nil
+}
+
+// AStar computes a shortest path from src to dst, guided by the
+// heuristic h that estimates the distance from a vertex to dst. If h
+// never overestimates, the path is a shortest one. All edge weights
+// must be non-negative. It returns a nil path and +Inf if dst is not
+// reachable from src.
+// Paper: Hart, Peter E. et al. (1968). "A Formal Basis for the Heuristic
+// Determination of Minimum Cost Paths". IEEE Transactions on Systems
+// Science and Cybernetics 4 (2): 100–107
+func AStar[V comparable](g *Graph[V], src, dst V, h func(v V) float64) (path []V, dist float64, err error) {
+	s, ok1 := g.index[src]
+	t, ok2 := g.index[dst]
+	if !ok1 || !ok2 {
+		return nil, math.Inf(1), ErrVertexNotFound
+	}
+
+	sp := newShortestPaths(g, s)
+	pq := ds.NewIndexedPriorityQueue[int](func(a, b float64) bool { return a < b })
+	pq.Push(s, h(src))
+	for pq.Len() > 0 {
+		u, _, _ := pq.Pop()
+		if u == t {
+			return g.path(sp.prev, t), sp.dist[t], nil
+		}
+		for _, e := range g.adj[u] {
+			if e.weight < 0 {
+				return nil, math.Inf(1), ErrNegativeWeight
+			}
+			if nd := sp.dist[u] + e.weight; nd < sp.dist[e.to] {
+				sp.dist[e.to], sp.prev[e.to] = nd, u
+				pq.Push(e.to, nd+h(g.verts[e.to]))
+			}
+		}
+	}
+	return nil, math.Inf(1), nil
+}
+
+// BellmanFord computes the shortest paths from src in O(VE). Edge
+// weights can be negative, but it returns ErrNegativeCycle if a
+// negative cycle is reachable from src.
+func BellmanFord[V comparable](g *Graph[V], src V) (*ShortestPaths[V], error) {
+	s, ok := g.index[src]
+	if !ok {
+		return nil, ErrVertexNotFound
+	}
+	sp := newShortestPaths(g, s)
+	relax := func() (changed bool) {
+		for u := range g.adj {
+			if math.IsInf(sp.dist[u], 1) {
+				continue
+			}
+			for _, e := range g.adj[u] {
+				if nd := sp.dist[u] + e.weight; nd < sp.dist[e.to] {
+					sp.dist[e.to], sp.prev[e.to] = nd, u
+					changed = true
+				}
+			}
+		}
+		return changed
+	}
+	for i := 1; i < len(g.verts); i++ {
+		if !relax() {
+			return sp, nil
+		}
+	}
+	if relax() {
+		return nil, ErrNegativeCycle
+	}
+	return sp, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"changkun.de/x/pkg/graph"
+)
+
+func TestShortestPaths(t *testing.T) {
+	g := graph.NewDirected[string]()
+	g.AddEdge("s", "a", 4)
+	g.AddEdge("s", "b", 1)
+	g.AddEdge("b", "a", 2)
+	g.AddEdge("a", "t", 1)
+	g.AddEdge("b", "t", 5)
+	g.AddVertex("x")
+
+	for name, f := range map[string]func(*graph.Graph[string], string) (*graph.ShortestPaths[string], error){
+		"dijkstra":     graph.Dijkstra[string],
+		"bellman-ford": graph.BellmanFord[string],
+	} {
+		sp, err := f(g, "s")
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if d, ok := sp.Dist("t"); !ok || d != 4 {
+			t.Fatalf("%s: want distance 4, got %v", name, d)
+		}
+		if p := sp.PathTo("t"); !reflect.DeepEqual(p, []string{"s", "b", "a", "t"}) {
+			t.Fatalf("%s: got path %v", name, p)
+		}
+		if _, ok := sp.Dist("x"); ok || sp.PathTo("x") != nil {
+			t.Fatalf("%s: unreachable vertex is reachable", name)
+		}
+		if _, err := f(g, "missing"); !errors.Is(err, graph.ErrVertexNotFound) {
+			t.Fatalf("%s: want %v, got %v", name, graph.ErrVertexNotFound, err)
+		}
+	}
+
+	path, d, err := graph.AStar(g, "s", "t", func(string) float64 { return 0 })
+	if err != nil || d != 4 || !reflect.DeepEqual(path, []string{"s", "b", "a", "t"}) {
+		t.Fatalf("a*: got path %v of %v, err %v", path, d, err)
+	}
+	if path, d, _ = graph.AStar(g, "s", "x", func(string) float64 { return 0 }); path != nil || !math.IsInf(d, 1) {
+		t.Fatalf("a*: unreachable vertex is reachable")
+	}
+
+	g.AddEdge("t", "b", -1)
+	if _, err := graph.Dijkstra(g, "s"); !errors.Is(err, graph.ErrNegativeWeight) {
+		t.Fatalf("want %v, got %v", graph.ErrNegativeWeight, err)
+	}
+	if sp, err := graph.BellmanFord(g, "s"); err != nil {
+		t.Fatalf("bellman-ford failed: %v", err)
+	} else if d, _ := sp.Dist("t"); d != 4 {
+		t.Fatalf("want distance 4, got %v", d)
+	}
+	g.AddEdge("t", "b", -4)
+	if _, err := graph.BellmanFord(g, "s"); !errors.Is(err, graph.ErrNegativeCycle) {
+		t.Fatalf("want %v, got %v", graph.ErrNegativeCycle, err)
+	}
+}
+
+func TestAStarGrid(t *testing.T) {
+	// a grid with random obstacles, A* with the manhattan distance
+	// must agree with Dijkstra.
+	type point struct{ x, y int }
+	const n = 30
+	r := rand.New(rand.NewSource(1))
+	g := graph.NewUndirected[point]()
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			if r.Intn(4) == 0 {
+				continue
+			}
+			if x+1 < n {
+				g.AddEdge(point{x, y}, point{x + 1, y}, 1)
+			}
+			if y+1 < n {
+				g.AddEdge(point{x, y}, point{x, y + 1}, 1)
+			}
+		}
+	}
+	src, dst := point{0, 0}, point{n - 1, n - 1}
+	g.AddVertex(src)
+	g.AddVertex(dst)
+	sp, _ := graph.Dijkstra(g, src)
+	want, _ := sp.Dist(dst)
+	path, got, err := graph.AStar(g, src, dst, func(p point) float64 {
+		return math.Abs(float64(dst.x-p.x)) + math.Abs(float64(dst.y-p.y))
+	})
+	if err != nil || got != want {
+		t.Fatalf("want distance %v, got %v, err %v", want, got, err)
+	}
+	if !math.IsInf(got, 1) && len(path) != int(got)+1 {
+		t.Fatalf("path %v does not match distance %v", path, got)
+	}
+}
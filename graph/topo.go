@@ -0,0 +1,77 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph
+
+import "fmt"
+
+// CycleError is returned by TopologicalSort if the graph has a
+// cycle. Every vertex of Cycle has an edge to the next one, and the
+// last vertex has an edge to the first one.
+type CycleError[V comparable] struct {
+	Cycle []V
+}
+
+func (e *CycleError[V]) Error() string {
+	return fmt.Sprintf("graph has a cycle: %v", e.Cycle)
+}
+
+// TopologicalSort returns the vertices of a directed acyclic graph in
+// an order that every edge goes from an earlier vertex to a later one.
+// If the graph has a cycle, it returns a *CycleError that reports one.
+func TopologicalSort[V comparable](g *Graph[V]) ([]V, error) {
+	if !g.directed {
+		return nil, ErrUndirected
+	}
+
+	const (
+		white = iota // not visited
+		gray         // on the DFS path
+		black        // finished
+	)
+	type frame struct{ v, next int }
+
+	colors := make([]uint8, len(g.verts))
+	post := make([]int, 0, len(g.verts))
+	var path []frame
+	for s := range g.verts {
+		if colors[s] != white {
+			continue
+		}
+		colors[s] = gray
+		path = append(path, frame{s, 0})
+		for len(path) > 0 {
+			f := &path[len(path)-1]
+			if f.next == len(g.adj[f.v]) {
+				colors[f.v] = black
+				post = append(post, f.v)
+				path = path[:len(path)-1]
+				continue
+			}
+			w := g.adj[f.v][f.next].to
+			f.next++
+			switch colors[w] {
+			case white:
+				colors[w] = gray
+				path = append(path, frame{w, 0})
+			case gray: // a back edge closes a cycle on the path
+				i := len(path) - 1
+				for path[i].v != w {
+					i--
+				}
+				cycle := make([]V, 0, len(path)-i)
+				for _, f := range path[i:] {
+					cycle = append(cycle, g.verts[f.v])
+				}
+				return nil, &CycleError[V]{Cycle: cycle}
+			}
+		}
+	}
+
+	order := make([]V, len(post))
+	for i, v := range post {
+		order[len(post)-1-i] = g.verts[v]
+	}
+	return order, nil
+}
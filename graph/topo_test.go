@@ -0,0 +1,90 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"changkun.de/x/pkg/graph"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	g := graph.NewDirected[string]()
+	deps := [][2]string{{"shirt", "tie"}, {"tie", "jacket"}, {"pants", "shoes"},
+		{"pants", "belt"}, {"belt", "jacket"}, {"shirt", "belt"}, {"socks", "shoes"}}
+	for _, d := range deps {
+		g.AddEdge(d[0], d[1], 1)
+	}
+	order, err := graph.TopologicalSort(g)
+	if err != nil {
+		t.Fatalf("sort failed: %v", err)
+	}
+	pos := map[string]int{}
+	for i, v := range order {
+		pos[v] = i
+	}
+	if len(order) != g.Order() {
+		t.Fatalf("want %v vertices, got %v", g.Order(), order)
+	}
+	for _, d := range deps {
+		if pos[d[0]] > pos[d[1]] {
+			t.Fatalf("%v is after %v in %v", d[0], d[1], order)
+		}
+	}
+
+	g.AddEdge("jacket", "pants", 1)
+	_, err = graph.TopologicalSort(g)
+	var ce *graph.CycleError[string]
+	if !errors.As(err, &ce) {
+		t.Fatalf("want a cycle error, got %v", err)
+	}
+	for i, v := range ce.Cycle {
+		if _, ok := g.Weight(v, ce.Cycle[(i+1)%len(ce.Cycle)]); !ok {
+			t.Fatalf("%v is not a cycle", ce.Cycle)
+		}
+	}
+	if _, err := graph.TopologicalSort(graph.NewUndirected[int]()); !errors.Is(err, graph.ErrUndirected) {
+		t.Fatalf("want %v, got %v", graph.ErrUndirected, err)
+	}
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	g := graph.NewDirected[int]()
+	for _, e := range [][2]int{{1, 2}, {2, 3}, {3, 1}, {3, 4}, {4, 5}, {5, 4}, {6, 5}} {
+		g.AddEdge(e[0], e[1], 1)
+	}
+	comps := graph.StronglyConnectedComponents(g)
+	for _, c := range comps {
+		sort.Ints(c)
+	}
+	want := [][]int{{4, 5}, {1, 2, 3}, {6}}
+	if !reflect.DeepEqual(comps, want) {
+		t.Fatalf("want %v, got %v", want, comps)
+	}
+}
+
+func TestMinimumSpanningTree(t *testing.T) {
+	g := graph.NewUndirected[string]()
+	g.AddEdge("a", "b", 4)
+	g.AddEdge("a", "c", 1)
+	g.AddEdge("b", "c", 2)
+	g.AddEdge("b", "d", 5)
+	g.AddEdge("c", "d", 8)
+	g.AddEdge("x", "y", 3) // another component
+	tree, total, err := graph.MinimumSpanningTree(g)
+	if err != nil {
+		t.Fatalf("mst failed: %v", err)
+	}
+	want := []graph.Edge[string]{{"a", "c", 1}, {"b", "c", 2}, {"x", "y", 3}, {"b", "d", 5}}
+	if total != 11 || !reflect.DeepEqual(tree, want) {
+		t.Fatalf("want %v of 11, got %v of %v", want, tree, total)
+	}
+	if _, _, err := graph.MinimumSpanningTree(graph.NewDirected[int]()); !errors.Is(err, graph.ErrDirected) {
+		t.Fatalf("want %v, got %v", graph.ErrDirected, err)
+	}
+}
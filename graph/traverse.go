@@ -0,0 +1,89 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package graph
+
+// Iterator visits the vertices that are reachable from a start
+// vertex. Modifying the graph invalidates the iterator.
+type Iterator[V comparable] struct {
+	g        *Graph[V]
+	bfs      bool
+	frontier []visit // a queue for BFS, a stack for DFS
+	seen     []bool
+	cur      visit
+}
+
+type visit struct {
+	v, depth int
+}
+
+// BFS returns an iterator that visits vertices in breadth-first
+// order from start.
+func (g *Graph[V]) BFS(start V) *Iterator[V] {
+	return g.iterator(start, true)
+}
+
+// DFS returns an iterator that visits vertices in depth-first
+// preorder from start.
+func (g *Graph[V]) DFS(start V) *Iterator[V] {
+	return g.iterator(start, false)
+}
+
+func (g *Graph[V]) iterator(start V, bfs bool) *Iterator[V] {
+	it := &Iterator[V]{g: g, bfs: bfs, seen: make([]bool, len(g.verts))}
+	if i, ok := g.index[start]; ok {
+		it.frontier = append(it.frontier, visit{i, 0})
+		if bfs {
+			it.seen[i] = true
+		}
+	}
+	return it
+}
+
+// Next advances the iterator, it returns false if there are no more
+// vertices.
+func (it *Iterator[V]) Next() bool {
+	if it.bfs {
+		if len(it.frontier) == 0 {
+			return false
+		}
+		it.cur, it.frontier = it.frontier[0], it.frontier[1:]
+		for _, e := range it.g.adj[it.cur.v] {
+			if !it.seen[e.to] {
+				it.seen[e.to] = true
+				it.frontier = append(it.frontier, visit{e.to, it.cur.depth + 1})
+			}
+		}
+		return true
+	}
+
+	for len(it.frontier) > 0 {
+		it.cur, it.frontier = it.frontier[len(it.frontier)-1], it.frontier[:len(it.frontier)-1]
+		if it.seen[it.cur.v] {
+			continue
+		}
+		it.seen[it.cur.v] = true
+		// push in reverse, so that neighbors are visited in order.
+		adj := it.g.adj[it.cur.v]
+		for j := len(adj) - 1; j >= 0; j-- {
+			if !it.seen[adj[j].to] {
+				it.frontier = append(it.frontier, visit{adj[j].to, it.cur.depth + 1})
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Vertex returns the current vertex.
+func (it *Iterator[V]) Vertex() V {
+	return it.g.verts[it.cur.v]
+}
+
+// Depth returns the depth of the current vertex in the traversal
+// tree, the start vertex is of depth 0. The depth of BFS is the
+// number of edges of the shortest path from the start vertex.
+func (it *Iterator[V]) Depth() int {
+	return it.cur.depth
+}
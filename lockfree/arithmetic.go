@@ -7,6 +7,7 @@ package lockfree
 import (
 	"math"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -15,10 +16,192 @@ func AddFloat64(addr *float64, delta float64) (new float64) {
 	var old float64
 	for {
 		old = math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(addr))))
+		new = old + delta
 		if atomic.CompareAndSwapUint64((*uint64)(unsafe.Pointer(addr)),
-			math.Float64bits(old), math.Float64bits(old+delta)) {
+			math.Float64bits(old), math.Float64bits(new)) {
 			break
 		}
 	}
 	return
 }
+
+// Float64 is an atomic float64. The zero value is zero.
+//
+// Values are compared by their bits, thus CompareAndSwap
+// distinguishes 0 from -0, and a NaN equals the same NaN.
+type Float64 struct {
+	v atomic.Uint64
+}
+
+// Load atomically loads the value.
+func (f *Float64) Load() float64 {
+	return math.Float64frombits(f.v.Load())
+}
+
+// Store atomically stores v.
+func (f *Float64) Store(v float64) {
+	f.v.Store(math.Float64bits(v))
+}
+
+// Swap atomically stores new and returns the old value.
+func (f *Float64) Swap(new float64) (old float64) {
+	return math.Float64frombits(f.v.Swap(math.Float64bits(new)))
+}
+
+// CompareAndSwap executes the compare-and-swap operation.
+func (f *Float64) CompareAndSwap(old, new float64) bool {
+	return f.v.CompareAndSwap(math.Float64bits(old), math.Float64bits(new))
+}
+
+// Add atomically adds delta and returns the new value.
+func (f *Float64) Add(delta float64) (new float64) {
+	return f.Update(func(old float64) float64 { return old + delta })
+}
+
+// Max atomically stores v if it is greater than the value, and
+// returns the new value.
+func (f *Float64) Max(v float64) (new float64) {
+	return f.Update(func(old float64) float64 { return math.Max(old, v) })
+}
+
+// Min atomically stores v if it is less than the value, and returns
+// the new value.
+func (f *Float64) Min(v float64) (new float64) {
+	return f.Update(func(old float64) float64 { return math.Min(old, v) })
+}
+
+// Update atomically replaces the value by fn(value), and returns the
+// new value. The function fn may be called multiple times under
+// contention, thus it must be free of side effects.
+func (f *Float64) Update(fn func(old float64) float64) (new float64) {
+	for {
+		old := f.v.Load()
+		new = fn(math.Float64frombits(old))
+		if f.v.CompareAndSwap(old, math.Float64bits(new)) {
+			return new
+		}
+	}
+}
+
+// Float32 is an atomic float32. The zero value is zero.
+//
+// Values are compared by their bits, thus CompareAndSwap
+// distinguishes 0 from -0, and a NaN equals the same NaN.
+type Float32 struct {
+	v atomic.Uint32
+}
+
+// Load atomically loads the value.
+func (f *Float32) Load() float32 {
+	return math.Float32frombits(f.v.Load())
+}
+
+// Store atomically stores v.
+func (f *Float32) Store(v float32) {
+	f.v.Store(math.Float32bits(v))
+}
+
+// Swap atomically stores new and returns the old value.
+func (f *Float32) Swap(new float32) (old float32) {
+	return math.Float32frombits(f.v.Swap(math.Float32bits(new)))
+}
+
+// CompareAndSwap executes the compare-and-swap operation.
+func (f *Float32) CompareAndSwap(old, new float32) bool {
+	return f.v.CompareAndSwap(math.Float32bits(old), math.Float32bits(new))
+}
+
+// Add atomically adds delta and returns the new value.
+func (f *Float32) Add(delta float32) (new float32) {
+	return f.Update(func(old float32) float32 { return old + delta })
+}
+
+// Max atomically stores v if it is greater than the value, and
+// returns the new value.
+func (f *Float32) Max(v float32) (new float32) {
+	return f.Update(func(old float32) float32 { return float32(math.Max(float64(old), float64(v))) })
+}
+
+// Min atomically stores v if it is less than the value, and returns
+// the new value.
+func (f *Float32) Min(v float32) (new float32) {
+	return f.Update(func(old float32) float32 { return float32(math.Min(float64(old), float64(v))) })
+}
+
+// Update atomically replaces the value by fn(value), and returns the
+// new value. The function fn may be called multiple times under
+// contention, thus it must be free of side effects.
+func (f *Float32) Update(fn func(old float32) float32) (new float32) {
+	for {
+		old := f.v.Load()
+		new = fn(math.Float32frombits(old))
+		if f.v.CompareAndSwap(old, math.Float32bits(new)) {
+			return new
+		}
+	}
+}
+
+// Duration is an atomic time.Duration. The zero value is zero.
+type Duration struct {
+	v atomic.Int64
+}
+
+// Load atomically loads the value.
+func (d *Duration) Load() time.Duration {
+	return time.Duration(d.v.Load())
+}
+
+// Store atomically stores v.
+func (d *Duration) Store(v time.Duration) {
+	d.v.Store(int64(v))
+}
+
+// Swap atomically stores new and returns the old value.
+func (d *Duration) Swap(new time.Duration) (old time.Duration) {
+	return time.Duration(d.v.Swap(int64(new)))
+}
+
+// CompareAndSwap executes the compare-and-swap operation.
+func (d *Duration) CompareAndSwap(old, new time.Duration) bool {
+	return d.v.CompareAndSwap(int64(old), int64(new))
+}
+
+// Add atomically adds delta and returns the new value.
+func (d *Duration) Add(delta time.Duration) (new time.Duration) {
+	return time.Duration(d.v.Add(int64(delta)))
+}
+
+// Max atomically stores v if it is greater than the value, and
+// returns the new value.
+func (d *Duration) Max(v time.Duration) (new time.Duration) {
+	for {
+		old := d.v.Load()
+		if int64(v) <= old || d.v.CompareAndSwap(old, int64(v)) {
+			return time.Duration(max(old, int64(v)))
+		}
+	}
+}
+
+// Min atomically stores v if it is less than the value, and returns
+// the new value.
+func (d *Duration) Min(v time.Duration) (new time.Duration) {
+	for {
+		old := d.v.Load()
+		if int64(v) >= old || d.v.CompareAndSwap(old, int64(v)) {
+			return time.Duration(min(old, int64(v)))
+		}
+	}
+}
+
+// Update atomically replaces the value by fn(value), and returns the
+// new value. The function fn may be called multiple times under
+// contention, thus it must be free of side effects.
+func (d *Duration) Update(fn func(old time.Duration) time.Duration) (new time.Duration) {
+	for {
+		old := d.v.Load()
+		new = fn(time.Duration(old))
+		if d.v.CompareAndSwap(old, int64(new)) {
+			return new
+		}
+	}
+}
@@ -7,6 +7,7 @@ package lockfree_test
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"changkun.de/x/pkg/lockfree"
 )
@@ -33,3 +34,84 @@ func TestAddFloat64(t *testing.T) {
 		t.Fatalf("AddFloat64 wrong, expected 55, got %v", sum)
 	}
 }
+
+func TestAddFloat64Return(t *testing.T) {
+	v := 1.5
+	if got := lockfree.AddFloat64(&v, 2); got != 3.5 || v != 3.5 {
+		t.Fatalf("AddFloat64 wrong, expected 3.5, got %v and %v", got, v)
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	var f lockfree.Float64
+	wg := sync.WaitGroup{}
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f.Add(float64(i))
+		}(i)
+	}
+	wg.Wait()
+	if f.Load() != 5050 {
+		t.Fatalf("Add wrong, expected 5050, got %v", f.Load())
+	}
+	if f.Max(100) != 5050 || f.Max(6000) != 6000 {
+		t.Fatalf("Max wrong, got %v", f.Load())
+	}
+	if f.Min(7000) != 6000 || f.Min(-1) != -1 {
+		t.Fatalf("Min wrong, got %v", f.Load())
+	}
+	if f.CompareAndSwap(0, 1) || !f.CompareAndSwap(-1, 2) {
+		t.Fatalf("CompareAndSwap wrong, got %v", f.Load())
+	}
+	if f.Swap(3) != 2 || f.Update(func(x float64) float64 { return x * x }) != 9 {
+		t.Fatalf("Update wrong, got %v", f.Load())
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	var f, g lockfree.Float32
+	wg := sync.WaitGroup{}
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f.Min(float32(-i))
+			g.Max(float32(i))
+		}(i)
+	}
+	wg.Wait()
+	if f.Load() != -100 || g.Load() != 100 {
+		t.Fatalf("Min or Max wrong, expected -100 and 100, got %v and %v", f.Load(), g.Load())
+	}
+	f.Store(0)
+	if f.Add(0.5) != 0.5 || !f.CompareAndSwap(0.5, 1) || f.Load() != 1 {
+		t.Fatalf("Add wrong, got %v", f.Load())
+	}
+}
+
+func TestDuration(t *testing.T) {
+	var d lockfree.Duration
+	wg := sync.WaitGroup{}
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d.Max(time.Duration(i) * time.Second)
+		}(i)
+	}
+	wg.Wait()
+	if d.Load() != 100*time.Second {
+		t.Fatalf("Max wrong, expected 100s, got %v", d.Load())
+	}
+	if d.Min(time.Hour) != 100*time.Second || d.Min(time.Second) != time.Second {
+		t.Fatalf("Min wrong, got %v", d.Load())
+	}
+	if d.Add(time.Second) != 2*time.Second {
+		t.Fatalf("Add wrong, got %v", d.Load())
+	}
+	if d.Update(func(x time.Duration) time.Duration { return x / 2 }) != time.Second {
+		t.Fatalf("Update wrong, got %v", d.Load())
+	}
+}
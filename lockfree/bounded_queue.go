@@ -0,0 +1,124 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import "sync/atomic"
+
+// BoundedQueue implements a lock-free bounded multi-producer
+// multi-consumer FIFO queue on a ring buffer. Unlike Queue, it does
+// not allocate after creation, and TryEnqueue fails instead of
+// growing if the queue is full.
+//
+// Every slot of the ring carries a sequence number that tells the
+// round of the slot, so that a producer or a consumer claims a slot
+// by a single CAS on its index, and publishes the slot by a store.
+// ref: https://www.1024cores.net/home/lock-free-algorithms/queues/bounded-mpmc-queue
+type BoundedQueue[T any] struct {
+	_     cacheLinePad
+	tail  atomic.Uint64 // next position to enqueue
+	_     cacheLinePad
+	head  atomic.Uint64 // next position to dequeue
+	_     cacheLinePad
+	mask  uint64
+	slots []seqslot[T]
+}
+
+type seqslot[T any] struct {
+	seq atomic.Uint64
+	v   T
+}
+
+// newSeqslots creates a ring of n slots, slot i is free for the
+// enqueue at position i.
+func newSeqslots[T any](n uint64) []seqslot[T] {
+	slots := make([]seqslot[T], n)
+	for i := range slots {
+		slots[i].seq.Store(uint64(i))
+	}
+	return slots
+}
+
+// NewBoundedQueue creates a bounded queue that holds at least
+// capacity values. The capacity is rounded up to a power of two.
+func NewBoundedQueue[T any](capacity int) *BoundedQueue[T] {
+	n := ringSize(capacity, 2)
+	return &BoundedQueue[T]{mask: n - 1, slots: newSeqslots[T](n)}
+}
+
+// TryEnqueue puts the given value v at the tail of the queue.
+// It returns false if the queue is full.
+func (q *BoundedQueue[T]) TryEnqueue(v T) bool {
+	return enqueueSeqslots(q.slots, q.mask, &q.tail, v)
+}
+
+// enqueueSeqslots is the enqueue of multiple producers, which is
+// shared by BoundedQueue and MPSCQueue.
+func enqueueSeqslots[T any](slots []seqslot[T], mask uint64, tail *atomic.Uint64, v T) bool {
+	pos := tail.Load()
+	for {
+		s := &slots[pos&mask]
+		switch d := int64(s.seq.Load() - pos); {
+		case d == 0: // the slot is free in this round, try to claim it
+			if tail.CompareAndSwap(pos, pos+1) {
+				s.v = v
+				s.seq.Store(pos + 1) // publish to the consumer
+				return true
+			}
+			pos = tail.Load()
+		case d < 0: // the slot is not consumed since the last round
+			return false
+		default: // another producer claimed the slot, catch up
+			pos = tail.Load()
+		}
+	}
+}
+
+// TryDequeue removes and returns the value at the head of the queue.
+// It returns false if the queue is empty.
+func (q *BoundedQueue[T]) TryDequeue() (v T, ok bool) {
+	pos := q.head.Load()
+	for {
+		s := &q.slots[pos&q.mask]
+		switch d := int64(s.seq.Load() - (pos + 1)); {
+		case d == 0: // the slot is published, try to claim it
+			if q.head.CompareAndSwap(pos, pos+1) {
+				v = s.v
+				var zero T
+				s.v = zero
+				s.seq.Store(pos + q.mask + 1) // free for the next round
+				return v, true
+			}
+			pos = q.head.Load()
+		case d < 0: // the slot is not published yet
+			return
+		default: // another consumer claimed the slot, catch up
+			pos = q.head.Load()
+		}
+	}
+}
+
+// Length returns the length of the queue. The length is a snapshot
+// if the queue is accessed concurrently.
+func (q *BoundedQueue[T]) Length() uint64 {
+	return ringLength(&q.head, &q.tail, q.mask)
+}
+
+// Capacity returns the maximum length of the queue.
+func (q *BoundedQueue[T]) Capacity() uint64 {
+	return q.mask + 1
+}
+
+// ringLength returns tail-head, clamped to the capacity of the ring.
+func ringLength(head, tail *atomic.Uint64, mask uint64) uint64 {
+	h := head.Load()
+	t := tail.Load()
+	switch {
+	case t <= h: // the head moved after it was loaded
+		return 0
+	case t-h > mask+1:
+		return mask + 1
+	}
+	return t - h
+}
@@ -0,0 +1,172 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+type tryQueue interface {
+	TryEnqueue(int) bool
+	TryDequeue() (int, bool)
+	Length() uint64
+	Capacity() uint64
+}
+
+func testTryQueueSequential(t *testing.T, q tryQueue) {
+	if _, ok := q.TryDequeue(); ok {
+		t.Fatalf("dequeue empty queue succeeds")
+	}
+	for round := 0; round < 3; round++ {
+		n := int(q.Capacity())
+		for i := 0; i < n; i++ {
+			if !q.TryEnqueue(i) {
+				t.Fatalf("enqueue %d fails, capacity %d", i, n)
+			}
+		}
+		if q.TryEnqueue(n) {
+			t.Fatalf("enqueue full queue succeeds")
+		}
+		if q.Length() != uint64(n) {
+			t.Fatalf("length wrong, want %d, got %d", n, q.Length())
+		}
+		for i := 0; i < n; i++ {
+			v, ok := q.TryDequeue()
+			if !ok || v != i {
+				t.Fatalf("dequeue wrong, want %d, got %d, %v", i, v, ok)
+			}
+		}
+		if q.Length() != 0 {
+			t.Fatalf("length wrong, want 0, got %d", q.Length())
+		}
+	}
+}
+
+// testTryQueueConcurrent runs producers and consumers on q, and checks
+// that every value is dequeued once, and that values from the same
+// producer are dequeued in order.
+func testTryQueueConcurrent(t *testing.T, q tryQueue, producers, consumers int) {
+	const n = 20000
+	var (
+		wg   sync.WaitGroup
+		done atomic.Int64
+		seen = make([]atomic.Int32, producers*n)
+		errs = make(chan error, consumers)
+	)
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				for !q.TryEnqueue(p*n + i) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := make([]int, producers)
+			for i := range last {
+				last[i] = -1
+			}
+			for done.Load() < int64(producers*n) {
+				v, ok := q.TryDequeue()
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				done.Add(1)
+				seen[v].Add(1)
+				p := v / n
+				if v%n <= last[p] {
+					errs <- fmt.Errorf("producer %d out of order: %d after %d", p, v%n, last[p])
+					return
+				}
+				last[p] = v % n
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	for v := range seen {
+		if c := seen[v].Load(); c != 1 {
+			t.Fatalf("value %d dequeued %d times", v, c)
+		}
+	}
+}
+
+func TestBoundedQueue(t *testing.T) {
+	q := lockfree.NewBoundedQueue[int](5)
+	if q.Capacity() != 8 {
+		t.Fatalf("capacity wrong, want 8, got %d", q.Capacity())
+	}
+	testTryQueueSequential(t, q)
+	testTryQueueSequential(t, lockfree.NewBoundedQueue[int](1))
+	testTryQueueConcurrent(t, lockfree.NewBoundedQueue[int](64), 4, 4)
+}
+
+func ExampleBoundedQueue() {
+	q := lockfree.NewBoundedQueue[string](2)
+
+	fmt.Println(q.TryEnqueue("1st item"))
+	fmt.Println(q.TryEnqueue("2nd item"))
+	fmt.Println(q.TryEnqueue("3rd item"))
+
+	fmt.Println(q.TryDequeue())
+	fmt.Println(q.TryDequeue())
+	fmt.Println(q.TryDequeue())
+
+	// Output:
+	// true
+	// true
+	// false
+	// 1st item true
+	// 2nd item true
+	//  false
+}
+
+type boundedQueue struct {
+	q *lockfree.BoundedQueue[interface{}]
+}
+
+func (q boundedQueue) Enqueue(v interface{}) { q.q.TryEnqueue(v) }
+func (q boundedQueue) Dequeue() interface{} {
+	v, _ := q.q.TryDequeue()
+	return v
+}
+
+// BenchmarkBoundedQueue compares queues under contention, where
+// every goroutine enqueues and dequeues alternately.
+func BenchmarkBoundedQueue(b *testing.B) {
+	for _, q := range [...]queueInterface{
+		lockfree.NewQueue(),
+		newMutexQueue(),
+		boundedQueue{lockfree.NewBoundedQueue[interface{}](1 << 12)},
+	} {
+		b.Run(fmt.Sprintf("%T", q), func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					if i%2 == 0 {
+						q.Enqueue(i)
+					} else {
+						q.Dequeue()
+					}
+				}
+			})
+		})
+	}
+}
@@ -20,3 +20,23 @@ func loaditem(p *unsafe.Pointer) *directItem {
 func casitem(p *unsafe.Pointer, old, new *directItem) bool {
 	return atomic.CompareAndSwapPointer(p, unsafe.Pointer(old), unsafe.Pointer(new))
 }
+
+// cacheLineSize is the common size of cache lines, indices that are
+// updated by different goroutines are padded apart by it to avoid
+// false sharing.
+const cacheLineSize = 64
+
+type cacheLinePad [cacheLineSize]byte
+
+// ringSize returns the smallest power of two that is not less than
+// capacity and min.
+func ringSize(capacity, min int) uint64 {
+	if capacity <= 0 {
+		panic("lockfree: capacity must be positive")
+	}
+	n := uint64(min)
+	for n < uint64(capacity) {
+		n <<= 1
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import (
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+)
+
+// Counter is a striped counter for frequent concurrent increments and
+// rare reads. It adds to a single base value until it sees
+// contention, and then spreads increments over cells on different
+// cache lines, which a read sums up. The zero value is zero.
+//
+// It is the same as LongAdder in Java.
+type Counter struct {
+	base  atomic.Int64
+	cells atomic.Pointer[[]counterCell]
+}
+
+type counterCell struct {
+	v atomic.Int64
+	_ [cacheLineSize - 8]byte
+}
+
+// Add adds delta to the counter.
+func (c *Counter) Add(delta int64) {
+	cells := c.cells.Load()
+	if cells == nil {
+		old := c.base.Load()
+		if c.base.CompareAndSwap(old, old+delta) {
+			return
+		}
+		cells = c.initCells()
+	}
+
+	// pick a random cell, and move to another one if the cell is
+	// contended as well.
+	mask := uint32(len(*cells) - 1)
+	for i := rand.Uint32(); ; i = i*1664525 + 1013904223 {
+		cell := &(*cells)[i&mask]
+		old := cell.v.Load()
+		if cell.v.CompareAndSwap(old, old+delta) {
+			return
+		}
+	}
+}
+
+func (c *Counter) initCells() *[]counterCell {
+	n := int(ringSize(runtime.GOMAXPROCS(0), 2))
+	cells := make([]counterCell, n)
+	if c.cells.CompareAndSwap(nil, &cells) {
+		return &cells
+	}
+	return c.cells.Load()
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Sum returns the value of the counter. It is not a snapshot if the
+// counter is updated concurrently.
+func (c *Counter) Sum() int64 {
+	sum := c.base.Load()
+	if cells := c.cells.Load(); cells != nil {
+		for i := range *cells {
+			sum += (*cells)[i].v.Load()
+		}
+	}
+	return sum
+}
+
+// Reset resets the counter to zero. It is not atomic if the counter
+// is updated concurrently.
+func (c *Counter) Reset() {
+	c.base.Store(0)
+	if cells := c.cells.Load(); cells != nil {
+		for i := range *cells {
+			(*cells)[i].v.Store(0)
+		}
+	}
+}
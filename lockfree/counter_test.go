@@ -0,0 +1,55 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestCounter(t *testing.T) {
+	var c lockfree.Counter
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10000; j++ {
+				c.Inc()
+				c.Add(-2)
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Sum() != -80000 {
+		t.Fatalf("Sum wrong, expected -80000, got %v", c.Sum())
+	}
+	c.Reset()
+	if c.Sum() != 0 {
+		t.Fatalf("Reset wrong, got %v", c.Sum())
+	}
+}
+
+func BenchmarkCounter(b *testing.B) {
+	b.Run("atomic", func(b *testing.B) {
+		var c atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Add(1)
+			}
+		})
+	})
+	b.Run("Counter", func(b *testing.B) {
+		var c lockfree.Counter
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Inc()
+			}
+		})
+	})
+}
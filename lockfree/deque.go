@@ -0,0 +1,186 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import "sync/atomic"
+
+// Deque implements a lock-free double-ended queue on a doubly linked
+// list. Both ends are held by a single anchor, which is swapped by a
+// CAS together with a status that tells whether a push on either end
+// is not yet linked from its neighbor. Every operation first helps to
+// stabilize the anchor, so that an interrupted push never blocks
+// other goroutines.
+//
+// Paper: Michael, Maged M. (2003). "CAS-based lock-free algorithm for
+// shared deques". Euro-Par 2003: 651–660
+type Deque[T any] struct {
+	anchor atomic.Pointer[dequeAnchor[T]]
+	len    atomic.Uint64
+}
+
+type dequeNode[T any] struct {
+	left, right atomic.Pointer[dequeNode[T]]
+	v           T
+}
+
+type dequeStatus int
+
+const (
+	dequeStable dequeStatus = iota
+	dequeLPush              // the leftmost node is not linked from its right neighbor
+	dequeRPush              // the rightmost node is not linked from its left neighbor
+)
+
+// dequeAnchor is the immutable state of a deque, both ends are nil if
+// the deque is empty.
+type dequeAnchor[T any] struct {
+	left, right *dequeNode[T]
+	status      dequeStatus
+}
+
+// NewDeque creates an empty lock-free deque.
+func NewDeque[T any]() *Deque[T] {
+	d := &Deque[T]{}
+	d.anchor.Store(&dequeAnchor[T]{})
+	return d
+}
+
+// PushFront pushes v at the front of the deque.
+func (d *Deque[T]) PushFront(v T) {
+	n := &dequeNode[T]{v: v}
+	// count before the push, so that a concurrent pop never sees a
+	// negative length.
+	d.len.Add(1)
+	for {
+		a := d.anchor.Load()
+		switch {
+		case a.left == nil:
+			if d.anchor.CompareAndSwap(a, &dequeAnchor[T]{n, n, dequeStable}) {
+				return
+			}
+		case a.status == dequeStable:
+			n.right.Store(a.left)
+			na := &dequeAnchor[T]{n, a.right, dequeLPush}
+			if d.anchor.CompareAndSwap(a, na) {
+				d.stabilize(na)
+				return
+			}
+		default:
+			d.stabilize(a)
+		}
+	}
+}
+
+// PushBack pushes v at the back of the deque.
+func (d *Deque[T]) PushBack(v T) {
+	n := &dequeNode[T]{v: v}
+	// count before the push, so that a concurrent pop never sees a
+	// negative length.
+	d.len.Add(1)
+	for {
+		a := d.anchor.Load()
+		switch {
+		case a.right == nil:
+			if d.anchor.CompareAndSwap(a, &dequeAnchor[T]{n, n, dequeStable}) {
+				return
+			}
+		case a.status == dequeStable:
+			n.left.Store(a.right)
+			na := &dequeAnchor[T]{a.left, n, dequeRPush}
+			if d.anchor.CompareAndSwap(a, na) {
+				d.stabilize(na)
+				return
+			}
+		default:
+			d.stabilize(a)
+		}
+	}
+}
+
+// PopFront removes and returns the value at the front of the deque.
+// It returns false if the deque is empty.
+func (d *Deque[T]) PopFront() (v T, ok bool) {
+	for {
+		a := d.anchor.Load()
+		switch {
+		case a.left == nil:
+			return
+		case a.left == a.right:
+			if d.anchor.CompareAndSwap(a, &dequeAnchor[T]{}) {
+				d.len.Add(^uint64(0))
+				return a.left.v, true
+			}
+		case a.status == dequeStable:
+			next := a.left.right.Load()
+			if d.anchor.CompareAndSwap(a, &dequeAnchor[T]{next, a.right, dequeStable}) {
+				d.len.Add(^uint64(0))
+				return a.left.v, true
+			}
+		default:
+			d.stabilize(a)
+		}
+	}
+}
+
+// PopBack removes and returns the value at the back of the deque.
+// It returns false if the deque is empty.
+func (d *Deque[T]) PopBack() (v T, ok bool) {
+	for {
+		a := d.anchor.Load()
+		switch {
+		case a.right == nil:
+			return
+		case a.left == a.right:
+			if d.anchor.CompareAndSwap(a, &dequeAnchor[T]{}) {
+				d.len.Add(^uint64(0))
+				return a.right.v, true
+			}
+		case a.status == dequeStable:
+			prev := a.right.left.Load()
+			if d.anchor.CompareAndSwap(a, &dequeAnchor[T]{a.left, prev, dequeStable}) {
+				d.len.Add(^uint64(0))
+				return a.right.v, true
+			}
+		default:
+			d.stabilize(a)
+		}
+	}
+}
+
+// stabilize links the node pushed by the anchor a from its neighbor,
+// and marks a as stable.
+func (d *Deque[T]) stabilize(a *dequeAnchor[T]) {
+	switch a.status {
+	case dequeRPush:
+		prev := a.right.left.Load()
+		if d.anchor.Load() != a {
+			return
+		}
+		if next := prev.right.Load(); next != a.right {
+			// prev is only reachable if a is still the anchor.
+			if d.anchor.Load() != a || !prev.right.CompareAndSwap(next, a.right) {
+				return
+			}
+		}
+	case dequeLPush:
+		next := a.left.right.Load()
+		if d.anchor.Load() != a {
+			return
+		}
+		if prev := next.left.Load(); prev != a.left {
+			if d.anchor.Load() != a || !next.left.CompareAndSwap(prev, a.left) {
+				return
+			}
+		}
+	default:
+		return
+	}
+	d.anchor.CompareAndSwap(a, &dequeAnchor[T]{a.left, a.right, dequeStable})
+}
+
+// Length returns the length of the deque.
+func (d *Deque[T]) Length() uint64 {
+	return d.len.Load()
+}
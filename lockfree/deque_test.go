@@ -0,0 +1,102 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestDeque(t *testing.T) {
+	d := lockfree.NewDeque[int]()
+	var want []int
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		switch r.Intn(4) {
+		case 0:
+			d.PushFront(i)
+			want = append([]int{i}, want...)
+		case 1:
+			d.PushBack(i)
+			want = append(want, i)
+		case 2:
+			v, ok := d.PopFront()
+			if ok != (len(want) > 0) || (ok && v != want[0]) {
+				t.Fatalf("pop front wrong, got %d, %v", v, ok)
+			}
+			if ok {
+				want = want[1:]
+			}
+		case 3:
+			v, ok := d.PopBack()
+			if ok != (len(want) > 0) || (ok && v != want[len(want)-1]) {
+				t.Fatalf("pop back wrong, got %d, %v", v, ok)
+			}
+			if ok {
+				want = want[:len(want)-1]
+			}
+		}
+		if d.Length() != uint64(len(want)) {
+			t.Fatalf("length wrong, want %d, got %d", len(want), d.Length())
+		}
+	}
+}
+
+func TestDequeConcurrent(t *testing.T) {
+	const (
+		workers = 4
+		n       = 10000
+	)
+	d := lockfree.NewDeque[int]()
+	seen := make([]atomic.Int32, workers*n)
+	var (
+		wg   sync.WaitGroup
+		done atomic.Int64
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				if i%2 == 0 {
+					d.PushFront(w*n + i)
+				} else {
+					d.PushBack(w*n + i)
+				}
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for done.Load() < workers*n {
+				var (
+					v  int
+					ok bool
+				)
+				if w%2 == 0 {
+					v, ok = d.PopFront()
+				} else {
+					v, ok = d.PopBack()
+				}
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				seen[v].Add(1)
+				done.Add(1)
+			}
+		}(w)
+	}
+	wg.Wait()
+	for v := range seen {
+		if c := seen[v].Load(); c != 1 {
+			t.Fatalf("value %d popped %d times", v, c)
+		}
+	}
+}
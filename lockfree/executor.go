@@ -0,0 +1,152 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// Task is a unit of work that runs on a worker of an Executor. A task
+// may spawn subtasks on the same worker.
+type Task func(w *Worker)
+
+// Executor runs tasks on a fixed number of workers by work stealing.
+// Every worker owns a WorkStealingDeque of tasks, which it runs in
+// LIFO order, and steals tasks from other workers if it runs out of
+// tasks. Tasks submitted from outside are put in a shared Deque.
+type Executor struct {
+	workers []*Worker
+	global  *Deque[Task]
+	wake    chan struct{} // a token per pending wakeup of idle workers
+	done    chan struct{}
+	wg      sync.WaitGroup
+
+	pending atomic.Int64 // submitted or spawned tasks that did not finish
+	mu      sync.Mutex
+	idle    *sync.Cond
+}
+
+// Worker is a worker of an Executor.
+type Worker struct {
+	id    int
+	e     *Executor
+	tasks *WorkStealingDeque[Task]
+}
+
+// NewExecutor creates an executor of n workers and starts them.
+func NewExecutor(n int) *Executor {
+	if n <= 0 {
+		panic("lockfree: number of workers must be positive")
+	}
+	e := &Executor{
+		workers: make([]*Worker, n),
+		global:  NewDeque[Task](),
+		wake:    make(chan struct{}, n),
+		done:    make(chan struct{}),
+	}
+	e.idle = sync.NewCond(&e.mu)
+	for i := range e.workers {
+		e.workers[i] = &Worker{id: i, e: e, tasks: NewWorkStealingDeque[Task]()}
+	}
+	e.wg.Add(n)
+	for _, w := range e.workers {
+		go w.run()
+	}
+	return e
+}
+
+// Submit submits a task to the executor.
+func (e *Executor) Submit(t Task) {
+	e.pending.Add(1)
+	e.global.PushBack(t)
+	e.notify()
+}
+
+// Wait waits until all submitted tasks and their subtasks are done.
+func (e *Executor) Wait() {
+	e.mu.Lock()
+	for e.pending.Load() > 0 {
+		e.idle.Wait()
+	}
+	e.mu.Unlock()
+}
+
+// Close stops all workers after their running tasks are done. Tasks
+// that did not start are dropped, thus Wait should be called before
+// Close to finish all tasks.
+func (e *Executor) Close() {
+	close(e.done)
+	e.wg.Wait()
+}
+
+// notify wakes up an idle worker, if there is no pending wakeup for
+// every worker already.
+func (e *Executor) notify() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ID returns the index of the worker in its executor.
+func (w *Worker) ID() int {
+	return w.id
+}
+
+// Spawn pushes a subtask to the worker, which runs it unless the task
+// is stolen by another worker. It must be called from a task that runs
+// on w.
+func (w *Worker) Spawn(t Task) {
+	w.e.pending.Add(1)
+	w.tasks.Push(t)
+	w.e.notify()
+}
+
+func (w *Worker) run() {
+	defer w.e.wg.Done()
+	for {
+		if t, ok := w.find(); ok {
+			t(w)
+			if w.e.pending.Add(-1) == 0 {
+				w.e.mu.Lock()
+				w.e.idle.Broadcast()
+				w.e.mu.Unlock()
+			}
+			continue
+		}
+		select {
+		case <-w.e.wake:
+		case <-w.e.done:
+			return
+		}
+	}
+}
+
+// find returns the next task of w, which is its own latest task, or
+// a submitted task, or the earliest task stolen from a random worker.
+func (w *Worker) find() (Task, bool) {
+	select {
+	case <-w.e.done:
+		return nil, false
+	default:
+	}
+	if t, ok := w.tasks.Pop(); ok {
+		return t, true
+	}
+	if t, ok := w.e.global.PopFront(); ok {
+		return t, true
+	}
+	n := len(w.e.workers)
+	for i, off := 0, rand.Intn(n); i < n; i++ {
+		if v := w.e.workers[(i+off)%n]; v != w {
+			if t, ok := v.tasks.Steal(); ok {
+				return t, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+// sum adds up xs into s by splitting xs in halves as subtasks.
+func sum(xs []int64, s *atomic.Int64) lockfree.Task {
+	return func(w *lockfree.Worker) {
+		if len(xs) <= 64 {
+			var local int64
+			for _, x := range xs {
+				local += x
+			}
+			s.Add(local)
+			return
+		}
+		w.Spawn(sum(xs[:len(xs)/2], s))
+		w.Spawn(sum(xs[len(xs)/2:], s))
+	}
+}
+
+func TestExecutor(t *testing.T) {
+	e := lockfree.NewExecutor(4)
+	defer e.Close()
+
+	xs := make([]int64, 100000)
+	for i := range xs {
+		xs[i] = int64(i)
+	}
+	for round := 0; round < 3; round++ {
+		var s atomic.Int64
+		for i := 0; i < 10; i++ {
+			e.Submit(sum(xs, &s))
+		}
+		e.Wait()
+		if want := int64(10 * 99999 * 100000 / 2); s.Load() != want {
+			t.Fatalf("sum wrong, want %d, got %d", want, s.Load())
+		}
+	}
+}
+
+func ExampleExecutor() {
+	e := lockfree.NewExecutor(2)
+	defer e.Close()
+
+	var n atomic.Int64
+	e.Submit(func(w *lockfree.Worker) {
+		for i := 0; i < 10; i++ {
+			w.Spawn(func(w *lockfree.Worker) { n.Add(1) })
+		}
+	})
+	e.Wait()
+	fmt.Println(n.Load())
+
+	// Output:
+	// 10
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package hazard implements hazard pointers, a safe memory
+// reclamation scheme for lock-free data structures.
+//
+// A goroutine publishes the nodes it is about to access in its hazard
+// pointers, and a removed node is retired instead of being reused
+// immediately. A retired node is handed back for reuse only if no
+// hazard pointer refers to it, which rules out both use-after-free and
+// the ABA problem when nodes are recycled, e.g. through a sync.Pool.
+//
+// Paper: Michael, Maged M. (2004). "Hazard pointers: safe memory
+// reclamation for lock-free objects". IEEE Transactions on Parallel
+// and Distributed Systems 15 (6): 491–504
+package hazard
+
+import (
+	"sync/atomic"
+)
+
+// Domain is a set of hazard pointers that protect nodes of type T,
+// usually one domain per data structure.
+type Domain[T any] struct {
+	head    atomic.Pointer[Record[T]] // records are never removed
+	records atomic.Int64
+	slots   int
+	free    func(*T)
+}
+
+// Record is a set of hazard pointers of a goroutine, and the nodes
+// it retired. A record is owned by a single goroutine between Acquire
+// and Release.
+type Record[T any] struct {
+	next    *Record[T]
+	active  atomic.Bool
+	hp      []atomic.Pointer[T]
+	dom     *Domain[T]
+	retired []*T
+	hazards map[*T]struct{} // reused by scan
+}
+
+// NewDomain creates a domain where every record has the given number
+// of hazard pointers. The free function is called on retired nodes
+// that are safe to reuse.
+func NewDomain[T any](slots int, free func(*T)) *Domain[T] {
+	if slots <= 0 {
+		panic("hazard: number of slots must be positive")
+	}
+	return &Domain[T]{slots: slots, free: free}
+}
+
+// Acquire returns a record for the calling goroutine, the record must
+// be released by Release after use.
+func (d *Domain[T]) Acquire() *Record[T] {
+	for r := d.head.Load(); r != nil; r = r.next {
+		if !r.active.Load() && r.active.CompareAndSwap(false, true) {
+			return r
+		}
+	}
+	r := &Record[T]{
+		hp:      make([]atomic.Pointer[T], d.slots),
+		dom:     d,
+		hazards: map[*T]struct{}{},
+	}
+	r.active.Store(true)
+	for {
+		r.next = d.head.Load()
+		if d.head.CompareAndSwap(r.next, r) {
+			d.records.Add(1)
+			return r
+		}
+	}
+}
+
+// Release clears the hazard pointers of r, and returns r to the
+// domain. The retired nodes of r are kept by r for the next owner.
+func (r *Record[T]) Release() {
+	for i := range r.hp {
+		r.hp[i].Store(nil)
+	}
+	r.active.Store(false)
+}
+
+// Protect loads the pointer from src, and publishes it in the i-th
+// hazard pointer. It returns the pointer, which is safe to access
+// until the hazard pointer is changed, even if it is retired.
+func (r *Record[T]) Protect(i int, src *atomic.Pointer[T]) *T {
+	p := src.Load()
+	for {
+		r.hp[i].Store(p)
+		// src may have changed, and p may have been retired before
+		// it was published.
+		q := src.Load()
+		if q == p {
+			return p
+		}
+		p = q
+	}
+}
+
+// Set publishes p in the i-th hazard pointer. The caller must verify
+// that p is not retired after the call, before it accesses p.
+func (r *Record[T]) Set(i int, p *T) {
+	r.hp[i].Store(p)
+}
+
+// Clear clears the i-th hazard pointer.
+func (r *Record[T]) Clear(i int) {
+	r.hp[i].Store(nil)
+}
+
+// Retire retires a node that is removed from the data structure and
+// is no longer reachable by new accesses. The node is freed once no
+// hazard pointer refers to it.
+func (r *Record[T]) Retire(p *T) {
+	r.retired = append(r.retired, p)
+	// scan once there are twice as many retired nodes as hazard
+	// pointers, so that at least half of the retired nodes are freed,
+	// which amortizes the scan to O(1) per node.
+	if len(r.retired) >= 2*int(r.dom.records.Load())*r.dom.slots+16 {
+		r.Scan()
+	}
+}
+
+// Scan frees the retired nodes of r that are not protected by any
+// hazard pointer.
+func (r *Record[T]) Scan() {
+	clear(r.hazards)
+	for rr := r.dom.head.Load(); rr != nil; rr = rr.next {
+		for i := range rr.hp {
+			if p := rr.hp[i].Load(); p != nil {
+				r.hazards[p] = struct{}{}
+			}
+		}
+	}
+	kept := r.retired[:0]
+	for _, p := range r.retired {
+		if _, ok := r.hazards[p]; ok {
+			kept = append(kept, p)
+		} else if r.dom.free != nil {
+			r.dom.free(p)
+		}
+	}
+	clear(r.retired[len(kept):])
+	r.retired = kept
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package hazard_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree/hazard"
+)
+
+type node struct {
+	v int
+}
+
+func TestProtect(t *testing.T) {
+	freed := map[*node]bool{}
+	d := hazard.NewDomain(1, func(n *node) { freed[n] = true })
+
+	var src atomic.Pointer[node]
+	a, b := &node{1}, &node{2}
+	src.Store(a)
+
+	reader := d.Acquire()
+	if p := reader.Protect(0, &src); p != a {
+		t.Fatalf("protect returns %v, want %v", p, a)
+	}
+
+	writer := d.Acquire()
+	if writer == reader {
+		t.Fatalf("acquire returns an active record")
+	}
+	src.Store(b)
+	writer.Retire(a)
+	writer.Scan()
+	if freed[a] {
+		t.Fatalf("a protected node is freed")
+	}
+
+	reader.Release()
+	writer.Scan()
+	if !freed[a] {
+		t.Fatalf("an unprotected node is not freed")
+	}
+	writer.Release()
+
+	if r := d.Acquire(); r != reader && r != writer {
+		t.Fatalf("acquire does not reuse released records")
+	}
+}
+
+func TestRetireScan(t *testing.T) {
+	var freed int
+	d := hazard.NewDomain(2, func(n *node) { freed++ })
+	r := d.Acquire()
+	defer r.Release()
+	for i := 0; i < 1000; i++ {
+		r.Retire(&node{i})
+	}
+	if freed < 900 {
+		t.Fatalf("retired nodes are not freed, freed %d of 1000", freed)
+	}
+}
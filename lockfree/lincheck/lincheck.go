@@ -0,0 +1,241 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package lincheck checks concurrent histories of a data structure for
+// linearizability against a sequential model.
+//
+// A history is recorded by a Recorder while goroutines operate on the
+// data structure, and Check searches for an order of the operations
+// that respects their real-time order and is accepted by the model.
+// For instance:
+//
+//	r := &lincheck.Recorder[Input, Output]{}
+//	for i := 0; i < 4; i++ {
+//		go func(i int) {
+//			r.Record(i, in, func() Output { return do(in) })
+//		}(i)
+//	}
+//	...
+//	if !lincheck.Check(model, r.Operations()) {
+//		t.Fatal("history is not linearizable")
+//	}
+//
+// Checking is NP-complete in general, thus histories should be kept
+// short, e.g. a few hundred operations.
+//
+// Paper: Lowe, Gavin (2017). "Testing for linearizability".
+// Concurrency and Computation: Practice and Experience 29 (4): e3928
+package lincheck
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Operation is a completed operation of a history. An operation took
+// effect at some time between Call and Return, which are logical
+// timestamps.
+type Operation[I, O any] struct {
+	Client int
+	Input  I
+	Output O
+	Call   int64
+	Return int64
+}
+
+// Model is a sequential specification of a data structure with states
+// of type S. States must be treated as immutable.
+type Model[S, I, O any] struct {
+	// Init returns the initial state.
+	Init func() S
+	// Step applies an operation of given input to state, and reports
+	// whether the output is a legal result, and the next state.
+	Step func(state S, input I, output O) (ok bool, next S)
+	// Equal reports whether two states are the same. If nil,
+	// states are compared by reflect.DeepEqual.
+	Equal func(a, b S) bool
+}
+
+// Recorder records operations of concurrent goroutines. The zero
+// value is ready to use.
+type Recorder[I, O any] struct {
+	clock atomic.Int64
+	mu    sync.Mutex
+	ops   []Operation[I, O]
+}
+
+// Record runs f as the operation of given client and input, and
+// records its output. It is safe for concurrent use.
+func (r *Recorder[I, O]) Record(client int, input I, f func() O) O {
+	call := r.clock.Add(1)
+	out := f()
+	ret := r.clock.Add(1)
+	r.mu.Lock()
+	r.ops = append(r.ops, Operation[I, O]{client, input, out, call, ret})
+	r.mu.Unlock()
+	return out
+}
+
+// Operations returns the recorded operations.
+func (r *Recorder[I, O]) Operations() []Operation[I, O] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Operation[I, O](nil), r.ops...)
+}
+
+// event is a call or return event in a doubly linked list of events
+// in time order. A call event links to its return event.
+type event struct {
+	id         int // index of the operation
+	call       bool
+	ret        *event // the return event of a call event
+	prev, next *event
+}
+
+// lift removes a call event and its return event from the list.
+func (e *event) lift() {
+	e.prev.next, e.next.prev = e.next, e.prev
+	r := e.ret
+	r.prev.next = r.next
+	if r.next != nil {
+		r.next.prev = r.prev
+	}
+}
+
+// unlift reinserts a call event and its return event that were
+// removed by lift.
+func (e *event) unlift() {
+	r := e.ret
+	r.prev.next = r
+	if r.next != nil {
+		r.next.prev = r
+	}
+	e.prev.next, e.next.prev = e, e
+}
+
+type bitset []uint64
+
+func (b bitset) set(i int)   { b[i/64] |= 1 << (i % 64) }
+func (b bitset) clear(i int) { b[i/64] &^= 1 << (i % 64) }
+
+func (b bitset) hash() uint64 {
+	h := uint64(14695981039346656037)
+	for _, w := range b {
+		h = (h ^ w) * 1099511628211
+	}
+	return h
+}
+
+func (b bitset) equal(c bitset) bool {
+	for i := range b {
+		if b[i] != c[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Check reports whether the history of operations is linearizable
+// with respect to the model.
+//
+// It implements the search of Wing and Gong with the memoization of
+// Lowe: operations are linearized one by one as long as the model
+// accepts them, and the search backtracks when it meets a return
+// event of an operation that is not linearized yet. A configuration
+// of linearized operations and model state is never explored twice.
+func Check[S, I, O any](m Model[S, I, O], ops []Operation[I, O]) bool {
+	if len(ops) == 0 {
+		return true
+	}
+	equal := m.Equal
+	if equal == nil {
+		equal = func(a, b S) bool { return reflect.DeepEqual(a, b) }
+	}
+
+	// build the list of events in time order, calls go before
+	// returns of the same time.
+	events := make([]*event, 0, 2*len(ops))
+	times := make(map[*event]int64, 2*len(ops))
+	for i, op := range ops {
+		c := &event{id: i, call: true}
+		r := &event{id: i}
+		c.ret = r
+		events = append(events, c, r)
+		times[c], times[r] = op.Call, op.Return
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, tj := times[events[i]], times[events[j]]
+		if ti != tj {
+			return ti < tj
+		}
+		return events[i].call && !events[j].call
+	})
+	head := &event{}
+	prev := head
+	for _, e := range events {
+		prev.next, e.prev = e, prev
+		prev = e
+	}
+
+	type config struct {
+		linearized bitset
+		state      S
+	}
+	type frame struct {
+		e     *event
+		state S
+	}
+	var (
+		cache      = map[uint64][]config{}
+		stack      []frame
+		linearized = make(bitset, (len(ops)+63)/64)
+		state      = m.Init()
+		e          = head.next
+	)
+	seen := func(lin bitset, s S) bool {
+		h := lin.hash()
+		for _, c := range cache[h] {
+			if c.linearized.equal(lin) && equal(c.state, s) {
+				return true
+			}
+		}
+		cache[h] = append(cache[h], config{append(bitset(nil), lin...), s})
+		return false
+	}
+
+	for head.next != nil {
+		if e.call {
+			op := ops[e.id]
+			ok, next := m.Step(state, op.Input, op.Output)
+			if ok {
+				linearized.set(e.id)
+				if !seen(linearized, next) {
+					stack = append(stack, frame{e, state})
+					state = next
+					e.lift()
+					e = head.next
+					continue
+				}
+				linearized.clear(e.id)
+			}
+			e = e.next
+			continue
+		}
+
+		// a return event of an operation that is not linearized,
+		// backtrack to the last linearized operation.
+		if len(stack) == 0 {
+			return false
+		}
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		state = f.state
+		linearized.clear(f.e.id)
+		f.e.unlift()
+		e = f.e.next
+	}
+	return true
+}
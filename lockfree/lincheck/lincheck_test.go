@@ -0,0 +1,125 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lincheck_test
+
+import (
+	"sync"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree/lincheck"
+)
+
+// registerOp writes v if write is true, otherwise reads.
+type registerOp struct {
+	write bool
+	v     int
+}
+
+var register = lincheck.Model[int, registerOp, int]{
+	Init: func() int { return 0 },
+	Step: func(s int, in registerOp, out int) (bool, int) {
+		if in.write {
+			return true, in.v
+		}
+		return out == s, s
+	},
+}
+
+func TestCheck(t *testing.T) {
+	type op = lincheck.Operation[registerOp, int]
+	tests := []struct {
+		name string
+		ops  []op
+		want bool
+	}{
+		{"empty", nil, true},
+		{
+			// the read overlaps the write, and may see either value.
+			"overlap",
+			[]op{
+				{Client: 0, Input: registerOp{true, 1}, Call: 1, Return: 4},
+				{Client: 1, Input: registerOp{}, Output: 1, Call: 2, Return: 3},
+				{Client: 2, Input: registerOp{}, Output: 0, Call: 2, Return: 3},
+			},
+			true,
+		},
+		{
+			// the read starts after the write returns, and must see it.
+			"stale read",
+			[]op{
+				{Client: 0, Input: registerOp{true, 1}, Call: 1, Return: 2},
+				{Client: 1, Input: registerOp{}, Output: 0, Call: 3, Return: 4},
+			},
+			false,
+		},
+		{
+			// both reads overlap both writes, but they see the writes
+			// in different orders.
+			"inconsistent order",
+			[]op{
+				{Client: 0, Input: registerOp{true, 1}, Call: 1, Return: 10},
+				{Client: 1, Input: registerOp{true, 2}, Call: 1, Return: 10},
+				{Client: 2, Input: registerOp{}, Output: 1, Call: 2, Return: 3},
+				{Client: 2, Input: registerOp{}, Output: 2, Call: 4, Return: 5},
+				{Client: 3, Input: registerOp{}, Output: 2, Call: 2, Return: 3},
+				{Client: 3, Input: registerOp{}, Output: 1, Call: 4, Return: 5},
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lincheck.Check(register, tt.ops); got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var (
+		mu sync.Mutex
+		v  int
+		r  lincheck.Recorder[registerOp, int]
+		wg sync.WaitGroup
+	)
+	for c := 0; c < 4; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				in := registerOp{write: i%2 == 0, v: c*100 + i}
+				r.Record(c, in, func() int {
+					mu.Lock()
+					defer mu.Unlock()
+					if in.write {
+						v = in.v
+					}
+					return v
+				})
+			}
+		}(c)
+	}
+	wg.Wait()
+	ops := r.Operations()
+	if len(ops) != 200 {
+		t.Fatalf("want 200 operations, got %d", len(ops))
+	}
+	if !lincheck.Check(register, ops) {
+		t.Fatalf("history of a mutex-protected register is not linearizable")
+	}
+
+	// the history becomes illegal if a read sees a value that was
+	// never written.
+	for i := range ops {
+		if !ops[i].Input.write {
+			ops[i].Output = -1
+			break
+		}
+	}
+	if lincheck.Check(register, ops) {
+		t.Fatalf("illegal history is linearizable")
+	}
+}
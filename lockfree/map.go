@@ -0,0 +1,367 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math/bits"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Map implements a lock-free concurrent hash map on a split-ordered
+// list. All entries live in a single lock-free sorted linked list,
+// ordered by the bit-reversed hashes of keys, and buckets are
+// shortcuts into the list. Doubling the number of buckets splits
+// every bucket in place, which grows the map without moving entries
+// or locking.
+//
+// The link of a node is never modified in place, every update of it
+// swaps an immutable state by a CAS, so that a deleted node can not be
+// linked to by concurrent operations. The value of a node is swapped
+// by a separate CAS, so that overwriting a key does not allocate a new
+// state. Deleting a key swaps its value to a tombstone, which is the
+// linearization point, and then marks the state of the node as
+// deleted, which lets find unlink it.
+//
+// Paper: Shalev, Ori and Shavit, Nir (2006). "Split-ordered lists:
+// lock-free extensible hash tables". Journal of the ACM 53 (3):
+// 379–405
+type Map[K comparable, V any] struct {
+	hash     func(K) uint64
+	size     atomic.Uint64 // number of buckets, a power of two
+	count    atomic.Int64
+	segments [64]atomic.Pointer[[]atomic.Pointer[mapNode[K, V]]]
+	deleted  *V // tombstone value of deleted nodes
+}
+
+type mapNode[K comparable, V any] struct {
+	sokey    uint64 // split-order key
+	sentinel bool   // whether the node starts a bucket
+	key      K
+	v        atomic.Pointer[V] // nil for sentinels
+	state    atomic.Pointer[mapState[K, V]]
+}
+
+// mapState is the immutable link state of a node.
+type mapState[K comparable, V any] struct {
+	next    *mapNode[K, V]
+	deleted bool
+}
+
+const (
+	mapMinSize    = 16
+	mapMaxSize    = 1 << 62
+	mapLoadFactor = 4
+)
+
+// NewMap returns an empty lock-free map. If hash is nil, string and
+// integer keys are hashed by maphash, and other keys are hashed by
+// their fmt representation, which is correct but slow.
+func NewMap[K comparable, V any](hash func(K) uint64) *Map[K, V] {
+	if hash == nil {
+		hash = defaultHasher[K]()
+	}
+	m := &Map[K, V]{hash: hash, deleted: new(V)}
+	m.size.Store(mapMinSize)
+	head := &mapNode[K, V]{sentinel: true}
+	head.state.Store(&mapState[K, V]{})
+	seg := make([]atomic.Pointer[mapNode[K, V]], 1)
+	seg[0].Store(head)
+	m.segments[0].Store(&seg)
+	return m
+}
+
+func defaultHasher[K comparable]() func(K) uint64 {
+	seed := maphash.MakeSeed()
+	var k K
+	switch any(k).(type) {
+	case string:
+		return func(k K) uint64 { return maphash.String(seed, any(k).(string)) }
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		// hash the memory of integers, which has no padding.
+		return func(k K) uint64 {
+			return maphash.Bytes(seed, unsafe.Slice((*byte)(unsafe.Pointer(&k)), unsafe.Sizeof(k)))
+		}
+	}
+	return func(k K) uint64 {
+		var h maphash.Hash
+		h.SetSeed(seed)
+		fmt.Fprint(&h, k)
+		return h.Sum64()
+	}
+}
+
+// regularKey returns the split-order key of an entry, which is odd.
+func regularKey(h uint64) uint64 {
+	return bits.Reverse64(h | 1<<63)
+}
+
+// sentinelKey returns the split-order key of a bucket, which is even.
+func sentinelKey(b uint64) uint64 {
+	return bits.Reverse64(b)
+}
+
+// bucketSlot returns the slot of bucket b in the segment table.
+// Segment 0 holds bucket 0, and segment s > 0 holds the 2^(s-1)
+// buckets that their highest bit is the (s-1)-th bit.
+func (m *Map[K, V]) bucketSlot(b uint64) *atomic.Pointer[mapNode[K, V]] {
+	s := bits.Len64(b)
+	seg := m.segments[s].Load()
+	if seg == nil {
+		n := 1
+		if s > 0 {
+			n = 1 << (s - 1)
+		}
+		newseg := make([]atomic.Pointer[mapNode[K, V]], n)
+		if !m.segments[s].CompareAndSwap(nil, &newseg) {
+			seg = m.segments[s].Load()
+		} else {
+			seg = &newseg
+		}
+	}
+	if s == 0 {
+		return &(*seg)[0]
+	}
+	return &(*seg)[b-1<<(s-1)]
+}
+
+// bucket returns the sentinel of bucket b, it initializes the bucket
+// if it was not used yet.
+func (m *Map[K, V]) bucket(b uint64) *mapNode[K, V] {
+	slot := m.bucketSlot(b)
+	if n := slot.Load(); n != nil {
+		return n
+	}
+	// a bucket is split from its parent, which is the bucket without
+	// the highest bit.
+	parent := m.bucket(b &^ (1 << (bits.Len64(b) - 1)))
+	n := &mapNode[K, V]{sokey: sentinelKey(b), sentinel: true}
+	n = m.insert(parent, n)
+	slot.CompareAndSwap(nil, n)
+	return slot.Load()
+}
+
+// find finds the node of the given split-order key and key from the
+// sentinel start. It returns the node before the position, and the
+// node at the position which is the found node if found is true.
+// Deleted nodes on the way are unlinked.
+func (m *Map[K, V]) find(start *mapNode[K, V], sokey uint64, sentinel bool, key K) (
+	pred *mapNode[K, V], ps *mapState[K, V], curr *mapNode[K, V], found bool,
+) {
+retry:
+	pred, ps = start, start.state.Load()
+	for {
+		curr = ps.next
+		if curr == nil {
+			return pred, ps, nil, false
+		}
+		cs := curr.state.Load()
+		if cs.deleted {
+			nps := &mapState[K, V]{next: cs.next}
+			if ps.deleted || !pred.state.CompareAndSwap(ps, nps) {
+				goto retry
+			}
+			ps = nps
+			continue
+		}
+		if curr.sokey > sokey {
+			return pred, ps, curr, false
+		}
+		if curr.sokey == sokey && curr.sentinel == sentinel && (sentinel || curr.key == key) {
+			return pred, ps, curr, true
+		}
+		pred, ps = curr, cs
+	}
+}
+
+// insert inserts n into the bucket of start. It returns the existing
+// node if there is already one of the same key, otherwise n.
+func (m *Map[K, V]) insert(start, n *mapNode[K, V]) *mapNode[K, V] {
+	for {
+		pred, ps, curr, found := m.find(start, n.sokey, n.sentinel, n.key)
+		if found {
+			return curr
+		}
+		n.state.Store(&mapState[K, V]{next: curr})
+		if pred.state.CompareAndSwap(ps, &mapState[K, V]{next: n}) {
+			return n
+		}
+	}
+}
+
+// store inserts a new node of key and v, and reports whether there was
+// no node of key.
+func (m *Map[K, V]) store(start *mapNode[K, V], sokey uint64, key K, v *V) bool {
+	n := &mapNode[K, V]{sokey: sokey, key: key}
+	n.v.Store(v)
+	if m.insert(start, n) != n {
+		return false
+	}
+	m.count.Add(1)
+	m.grow()
+	return true
+}
+
+// load returns the value of n, or nil if n is deleted. A node whose
+// value is deleted is marked as deleted as well, so that the callers
+// can retry after find unlinks it.
+func (m *Map[K, V]) load(n *mapNode[K, V]) *V {
+	p := n.v.Load()
+	if p != m.deleted {
+		return p
+	}
+	for {
+		s := n.state.Load()
+		if s.deleted || n.state.CompareAndSwap(s, &mapState[K, V]{next: s.next, deleted: true}) {
+			return nil
+		}
+	}
+}
+
+// locate returns the sentinel of the bucket of key, and the split-order
+// key of key.
+func (m *Map[K, V]) locate(key K) (*mapNode[K, V], uint64) {
+	h := m.hash(key)
+	return m.bucket(h & (m.size.Load() - 1)), regularKey(h)
+}
+
+// grow doubles the number of buckets if the map is overloaded.
+func (m *Map[K, V]) grow() {
+	size := m.size.Load()
+	if size < mapMaxSize && uint64(m.count.Load()) > size*mapLoadFactor {
+		m.size.CompareAndSwap(size, size*2)
+	}
+}
+
+// Len returns the number of entries in the map.
+func (m *Map[K, V]) Len() int {
+	return int(m.count.Load())
+}
+
+// Load returns the value stored in the map for a key.
+func (m *Map[K, V]) Load(key K) (v V, ok bool) {
+	start, sokey := m.locate(key)
+	for n := start; n != nil; {
+		s := n.state.Load()
+		if n.sokey > sokey {
+			break
+		}
+		if n.sokey == sokey && !n.sentinel && n.key == key && !s.deleted {
+			if p := n.v.Load(); p != m.deleted {
+				return *p, true
+			}
+			return
+		}
+		n = s.next
+	}
+	return
+}
+
+// Store sets the value for a key.
+func (m *Map[K, V]) Store(key K, v V) {
+	start, sokey := m.locate(key)
+	for {
+		_, _, curr, found := m.find(start, sokey, false, key)
+		if !found {
+			if m.store(start, sokey, key, &v) {
+				return
+			}
+			continue
+		}
+		if p := m.load(curr); p != nil && curr.v.CompareAndSwap(p, &v) {
+			return
+		}
+	}
+}
+
+// LoadOrStore returns the existing value for the key if present.
+// Otherwise, it stores and returns the given value. The loaded result
+// is true if the value was loaded, false if stored.
+func (m *Map[K, V]) LoadOrStore(key K, v V) (actual V, loaded bool) {
+	start, sokey := m.locate(key)
+	for {
+		_, _, curr, found := m.find(start, sokey, false, key)
+		if !found {
+			if m.store(start, sokey, key, &v) {
+				return v, false
+			}
+			continue
+		}
+		if p := m.load(curr); p != nil {
+			return *p, true
+		}
+	}
+}
+
+// CompareAndSwap swaps the old and new values for key if the value
+// stored in the map is equal to old. The old value must be of a
+// comparable type, otherwise it panics.
+func (m *Map[K, V]) CompareAndSwap(key K, old, new V) bool {
+	start, sokey := m.locate(key)
+	for {
+		_, _, curr, found := m.find(start, sokey, false, key)
+		if !found {
+			return false
+		}
+		p := m.load(curr)
+		if p == nil {
+			continue
+		}
+		if any(*p) != any(old) {
+			return false
+		}
+		if curr.v.CompareAndSwap(p, &new) {
+			return true
+		}
+	}
+}
+
+// Delete deletes the value for a key.
+func (m *Map[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+// LoadAndDelete deletes the value for a key, returning the previous
+// value if any. The loaded result reports whether the key was present.
+func (m *Map[K, V]) LoadAndDelete(key K) (v V, loaded bool) {
+	start, sokey := m.locate(key)
+	for {
+		_, _, curr, found := m.find(start, sokey, false, key)
+		if !found {
+			return
+		}
+		p := m.load(curr)
+		if p == nil {
+			continue
+		}
+		// swap the value to the tombstone, which is the linearization
+		// point, and then mark and unlink the node.
+		if curr.v.CompareAndSwap(p, m.deleted) {
+			m.count.Add(-1)
+			m.load(curr)
+			m.find(start, sokey, false, key)
+			return *p, true
+		}
+	}
+}
+
+// Range calls op sequentially for each key and value present in the
+// map. If op returns false, range stops the iteration.
+//
+// Range does not correspond to a consistent snapshot of the map, the
+// same as sync.Map.
+func (m *Map[K, V]) Range(op func(key K, v V) bool) {
+	for n := m.bucket(0); n != nil; {
+		s := n.state.Load()
+		if !n.sentinel && !s.deleted {
+			if p := n.v.Load(); p != m.deleted && !op(n.key, *p) {
+				return
+			}
+		}
+		n = s.next
+	}
+}
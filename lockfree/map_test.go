@@ -0,0 +1,201 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestMap(t *testing.T) {
+	m := lockfree.NewMap[int, int](nil)
+	want := map[int]int{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50000; i++ {
+		k := r.Intn(2000)
+		switch r.Intn(4) {
+		case 0:
+			m.Store(k, i)
+			want[k] = i
+		case 1:
+			v, loaded := m.LoadOrStore(k, i)
+			wv, ok := want[k]
+			if loaded != ok || (ok && v != wv) || (!ok && v != i) {
+				t.Fatalf("load or store %d, want %d %v, got %d %v", k, wv, ok, v, loaded)
+			}
+			if !ok {
+				want[k] = i
+			}
+		case 2:
+			wv, ok := want[k]
+			if swapped := m.CompareAndSwap(k, wv, i); swapped != ok {
+				t.Fatalf("compare and swap %d, want %v, got %v", k, ok, swapped)
+			}
+			if ok {
+				want[k] = i
+			}
+		case 3:
+			v, loaded := m.LoadAndDelete(k)
+			if wv, ok := want[k]; loaded != ok || v != wv {
+				t.Fatalf("load and delete %d, want %d %v, got %d %v", k, wv, ok, v, loaded)
+			}
+			delete(want, k)
+		}
+		if m.Len() != len(want) {
+			t.Fatalf("want len %d, got %d", len(want), m.Len())
+		}
+	}
+	for k := 0; k < 2000; k++ {
+		v, ok := m.Load(k)
+		if wv, wok := want[k]; ok != wok || v != wv {
+			t.Fatalf("load %d, want %d %v, got %d %v", k, wv, wok, v, ok)
+		}
+	}
+	n := 0
+	m.Range(func(k, v int) bool {
+		if want[k] != v {
+			t.Fatalf("range %d, want %d, got %d", k, want[k], v)
+		}
+		n++
+		return true
+	})
+	if n != len(want) {
+		t.Fatalf("range visits %d entries, want %d", n, len(want))
+	}
+}
+
+func TestMapConcurrent(t *testing.T) {
+	const (
+		workers = 8
+		n       = 5000
+	)
+	m := lockfree.NewMap[string, int](nil)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				k := fmt.Sprint(w, "-", i)
+				m.Store(k, i)
+				if i%2 == 0 {
+					m.Delete(k)
+				}
+				// a shared counter that every worker increments.
+				for {
+					v, _ := m.LoadOrStore("counter", 0)
+					if m.CompareAndSwap("counter", v, v+1) {
+						break
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if v, _ := m.Load("counter"); v != workers*n {
+		t.Fatalf("counter wrong, want %d, got %d", workers*n, v)
+	}
+	if m.Len() != workers*n/2+1 {
+		t.Fatalf("want len %d, got %d", workers*n/2+1, m.Len())
+	}
+	for w := 0; w < workers; w++ {
+		for i := 0; i < n; i++ {
+			v, ok := m.Load(fmt.Sprint(w, "-", i))
+			if ok != (i%2 == 1) || (ok && v != i) {
+				t.Fatalf("load %d-%d, got %d %v", w, i, v, ok)
+			}
+		}
+	}
+}
+
+func TestMapStructKey(t *testing.T) {
+	type point struct{ x, y int }
+	m := lockfree.NewMap[point, string](nil)
+	m.Store(point{1, 2}, "a")
+	m.Store(point{2, 1}, "b")
+	if v, ok := m.Load(point{1, 2}); !ok || v != "a" {
+		t.Fatalf("load wrong, want a, got %q", v)
+	}
+}
+
+type intMap interface {
+	Load(k int) (int, bool)
+	Store(k, v int)
+}
+
+type syncMap struct{ m sync.Map }
+
+func (m *syncMap) Load(k int) (int, bool) {
+	v, ok := m.m.Load(k)
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+func (m *syncMap) Store(k, v int) { m.m.Store(k, v) }
+
+// shardedMap is a map of mutex-protected shards.
+type shardedMap struct {
+	shards [64]struct {
+		sync.RWMutex
+		m map[int]int
+	}
+}
+
+func newShardedMap() *shardedMap {
+	m := &shardedMap{}
+	for i := range m.shards {
+		m.shards[i].m = map[int]int{}
+	}
+	return m
+}
+
+func (m *shardedMap) Load(k int) (int, bool) {
+	s := &m.shards[uint(k)%64]
+	s.RLock()
+	v, ok := s.m[k]
+	s.RUnlock()
+	return v, ok
+}
+func (m *shardedMap) Store(k, v int) {
+	s := &m.shards[uint(k)%64]
+	s.Lock()
+	s.m[k] = v
+	s.Unlock()
+}
+
+// BenchmarkMap compares maps on 1024 keys, with 10%, 50% and 90% of
+// operations being writes.
+func BenchmarkMap(b *testing.B) {
+	for _, writes := range []int{10, 50, 90} {
+		for _, m := range [...]intMap{
+			lockfree.NewMap[int, int](nil),
+			&syncMap{},
+			newShardedMap(),
+		} {
+			b.Run(fmt.Sprintf("writes=%d%%/%T", writes, m), func(b *testing.B) {
+				for k := 0; k < 1024; k++ {
+					m.Store(k, k)
+				}
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewSource(rand.Int63()))
+					for pb.Next() {
+						k := r.Intn(1024)
+						if r.Intn(100) < writes {
+							m.Store(k, k)
+						} else {
+							m.Load(k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
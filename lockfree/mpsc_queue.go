@@ -0,0 +1,64 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import "sync/atomic"
+
+// MPSCQueue implements a lock-free bounded multi-producer
+// single-consumer FIFO queue. Producers are the same as BoundedQueue,
+// but the consumer owns the head and dequeues without CAS.
+//
+// TryDequeue must not be called concurrently, TryEnqueue is safe for
+// concurrent use.
+type MPSCQueue[T any] struct {
+	_     cacheLinePad
+	tail  atomic.Uint64
+	_     cacheLinePad
+	head  atomic.Uint64 // only stored by the consumer
+	_     cacheLinePad
+	mask  uint64
+	slots []seqslot[T]
+}
+
+// NewMPSCQueue creates a multi-producer single-consumer queue that
+// holds at least capacity values. The capacity is rounded up to a
+// power of two.
+func NewMPSCQueue[T any](capacity int) *MPSCQueue[T] {
+	n := ringSize(capacity, 2)
+	return &MPSCQueue[T]{mask: n - 1, slots: newSeqslots[T](n)}
+}
+
+// TryEnqueue puts the given value v at the tail of the queue.
+// It returns false if the queue is full.
+func (q *MPSCQueue[T]) TryEnqueue(v T) bool {
+	return enqueueSeqslots(q.slots, q.mask, &q.tail, v)
+}
+
+// TryDequeue removes and returns the value at the head of the queue.
+// It returns false if the queue is empty.
+func (q *MPSCQueue[T]) TryDequeue() (v T, ok bool) {
+	pos := q.head.Load()
+	s := &q.slots[pos&q.mask]
+	if s.seq.Load() != pos+1 {
+		return
+	}
+	v = s.v
+	var zero T
+	s.v = zero
+	s.seq.Store(pos + q.mask + 1)
+	q.head.Store(pos + 1)
+	return v, true
+}
+
+// Length returns the length of the queue. The length is a snapshot
+// if the queue is accessed concurrently.
+func (q *MPSCQueue[T]) Length() uint64 {
+	return ringLength(&q.head, &q.tail, q.mask)
+}
+
+// Capacity returns the maximum length of the queue.
+func (q *MPSCQueue[T]) Capacity() uint64 {
+	return q.mask + 1
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestMPSCQueue(t *testing.T) {
+	testTryQueueSequential(t, lockfree.NewMPSCQueue[int](1))
+	testTryQueueSequential(t, lockfree.NewMPSCQueue[int](16))
+	testTryQueueConcurrent(t, lockfree.NewMPSCQueue[int](64), 4, 1)
+}
+
+type mpscQueue struct {
+	q *lockfree.MPSCQueue[interface{}]
+}
+
+func (q mpscQueue) Enqueue(v interface{}) {
+	for !q.q.TryEnqueue(v) {
+		runtime.Gosched()
+	}
+}
+func (q mpscQueue) Dequeue() interface{} {
+	v, _ := q.q.TryDequeue()
+	return v
+}
+
+// BenchmarkMPSCQueue compares queues with four producers and one
+// consumer.
+func BenchmarkMPSCQueue(b *testing.B) {
+	for _, q := range [...]queueInterface{
+		lockfree.NewQueue(),
+		newMutexQueue(),
+		mpscQueue{lockfree.NewMPSCQueue[interface{}](1 << 12)},
+	} {
+		b.Run(fmt.Sprintf("%T", q), func(b *testing.B) {
+			benchmarkProducerConsumer(b, q, 4)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"changkun.de/x/pkg/lockfree/hazard"
+)
+
+// PooledQueue implements the same lock-free FIFO queue as Queue, but
+// recycles its nodes through a pool instead of leaving them to the
+// GC. Dequeued nodes are reclaimed by hazard pointers, so that a node
+// is never reused while another goroutine may still access it.
+type PooledQueue[T any] struct {
+	head atomic.Pointer[pooledNode[T]]
+	tail atomic.Pointer[pooledNode[T]]
+	len  atomic.Uint64
+	pool sync.Pool
+	dom  *hazard.Domain[pooledNode[T]]
+}
+
+type pooledNode[T any] struct {
+	next atomic.Pointer[pooledNode[T]]
+	v    T
+}
+
+// NewPooledQueue creates a new lock-free queue that recycles nodes.
+func NewPooledQueue[T any]() *PooledQueue[T] {
+	q := &PooledQueue[T]{}
+	q.pool.New = func() any { return &pooledNode[T]{} }
+	q.dom = hazard.NewDomain(2, q.free)
+	dummy := &pooledNode[T]{}
+	q.head.Store(dummy)
+	q.tail.Store(dummy)
+	return q
+}
+
+func (q *PooledQueue[T]) free(n *pooledNode[T]) {
+	var zero T
+	n.v = zero
+	n.next.Store(nil)
+	q.pool.Put(n)
+}
+
+// Enqueue puts the given value v at the tail of the queue.
+func (q *PooledQueue[T]) Enqueue(v T) {
+	n := q.pool.Get().(*pooledNode[T])
+	n.v = v
+	r := q.dom.Acquire()
+	defer r.Release()
+	for {
+		last := r.Protect(0, &q.tail)
+		next := last.next.Load()
+		if q.tail.Load() != last {
+			continue
+		}
+		if next != nil { // tail is falling behind, try to advance it
+			q.tail.CompareAndSwap(last, next)
+			continue
+		}
+		if last.next.CompareAndSwap(nil, n) {
+			q.tail.CompareAndSwap(last, n)
+			q.len.Add(1)
+			return
+		}
+	}
+}
+
+// Dequeue removes and returns the value at the head of the queue.
+// It returns false if the queue is empty.
+func (q *PooledQueue[T]) Dequeue() (v T, ok bool) {
+	r := q.dom.Acquire()
+	defer r.Release()
+	for {
+		first := r.Protect(0, &q.head)
+		last := q.tail.Load()
+		next := first.next.Load()
+		r.Set(1, next)
+		// next is safe to access only if first is still the head,
+		// i.e. next is not dequeued and retired yet.
+		if q.head.Load() != first {
+			continue
+		}
+		if next == nil {
+			return
+		}
+		if first == last { // tail is falling behind, try to advance it
+			q.tail.CompareAndSwap(last, next)
+			continue
+		}
+		v = next.v
+		if q.head.CompareAndSwap(first, next) {
+			q.len.Add(^uint64(0))
+			r.Clear(0)
+			r.Retire(first)
+			return v, true
+		}
+	}
+}
+
+// Length returns the length of the queue.
+func (q *PooledQueue[T]) Length() uint64 {
+	return q.len.Load()
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestPooledQueue(t *testing.T) {
+	q := lockfree.NewPooledQueue[int]()
+	if _, ok := q.Dequeue(); ok {
+		t.Fatalf("dequeue empty queue succeeds")
+	}
+	for i := 0; i < 100; i++ {
+		q.Enqueue(i)
+	}
+	if q.Length() != 100 {
+		t.Fatalf("length wrong, want 100, got %d", q.Length())
+	}
+	for i := 0; i < 100; i++ {
+		if v, ok := q.Dequeue(); !ok || v != i {
+			t.Fatalf("dequeue wrong, want %d, got %d, %v", i, v, ok)
+		}
+	}
+}
+
+func TestPooledQueueConcurrent(t *testing.T) {
+	const (
+		workers = 4
+		n       = 10000
+	)
+	q := lockfree.NewPooledQueue[int]()
+	seen := make([]atomic.Int32, workers*n)
+	var (
+		wg   sync.WaitGroup
+		done atomic.Int64
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				q.Enqueue(w*n + i)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for done.Load() < workers*n {
+				if v, ok := q.Dequeue(); ok {
+					seen[v].Add(1)
+					done.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for v := range seen {
+		if c := seen[v].Load(); c != 1 {
+			t.Fatalf("value %d dequeued %d times", v, c)
+		}
+	}
+}
+
+type pooledQueue struct {
+	q *lockfree.PooledQueue[interface{}]
+}
+
+func (q pooledQueue) Enqueue(v interface{}) { q.q.Enqueue(v) }
+func (q pooledQueue) Dequeue() interface{} {
+	v, _ := q.q.Dequeue()
+	return v
+}
+
+func BenchmarkPooledQueue(b *testing.B) {
+	for _, q := range [...]queueInterface{
+		lockfree.NewQueue(),
+		pooledQueue{lockfree.NewPooledQueue[interface{}]()},
+	} {
+		b.Run(fmt.Sprintf("%T", q), func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					if i%2 == 0 {
+						q.Enqueue(nil)
+					} else {
+						q.Dequeue()
+					}
+				}
+			})
+		})
+	}
+}
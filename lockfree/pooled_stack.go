@@ -0,0 +1,78 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"changkun.de/x/pkg/lockfree/hazard"
+)
+
+// PooledStack implements the same lock-free stack as Stack, but
+// recycles its nodes through a pool instead of leaving them to the
+// GC. Popped nodes are reclaimed by hazard pointers, which also
+// prevents the ABA problem of a recycled node being pushed back
+// while a Pop is in progress.
+type PooledStack[T any] struct {
+	top  atomic.Pointer[pooledNode[T]]
+	len  atomic.Uint64
+	pool sync.Pool
+	dom  *hazard.Domain[pooledNode[T]]
+}
+
+// NewPooledStack creates a new lock-free stack that recycles nodes.
+func NewPooledStack[T any]() *PooledStack[T] {
+	s := &PooledStack[T]{}
+	s.pool.New = func() any { return &pooledNode[T]{} }
+	s.dom = hazard.NewDomain(1, s.free)
+	return s
+}
+
+func (s *PooledStack[T]) free(n *pooledNode[T]) {
+	var zero T
+	n.v = zero
+	n.next.Store(nil)
+	s.pool.Put(n)
+}
+
+// Push pushes a value on top of the stack.
+func (s *PooledStack[T]) Push(v T) {
+	n := s.pool.Get().(*pooledNode[T])
+	n.v = v
+	for {
+		top := s.top.Load()
+		n.next.Store(top)
+		if s.top.CompareAndSwap(top, n) {
+			s.len.Add(1)
+			return
+		}
+	}
+}
+
+// Pop pops value from the top of the stack.
+// It returns false if the stack is empty.
+func (s *PooledStack[T]) Pop() (v T, ok bool) {
+	r := s.dom.Acquire()
+	defer r.Release()
+	for {
+		top := r.Protect(0, &s.top)
+		if top == nil {
+			return
+		}
+		if s.top.CompareAndSwap(top, top.next.Load()) {
+			s.len.Add(^uint64(0))
+			v = top.v
+			r.Clear(0)
+			r.Retire(top)
+			return v, true
+		}
+	}
+}
+
+// Length returns the length of the stack.
+func (s *PooledStack[T]) Length() uint64 {
+	return s.len.Load()
+}
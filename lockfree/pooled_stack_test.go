@@ -0,0 +1,87 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestPooledStack(t *testing.T) {
+	s := lockfree.NewPooledStack[int]()
+	if _, ok := s.Pop(); ok {
+		t.Fatalf("pop empty stack succeeds")
+	}
+	for i := 0; i < 100; i++ {
+		s.Push(i)
+	}
+	if s.Length() != 100 {
+		t.Fatalf("length wrong, want 100, got %d", s.Length())
+	}
+	for i := 99; i >= 0; i-- {
+		if v, ok := s.Pop(); !ok || v != i {
+			t.Fatalf("pop wrong, want %d, got %d, %v", i, v, ok)
+		}
+	}
+}
+
+func TestPooledStackConcurrent(t *testing.T) {
+	const (
+		workers = 4
+		n       = 10000
+	)
+	s := lockfree.NewPooledStack[int]()
+	seen := make([]atomic.Int32, workers*n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// push and pop alternately, so that nodes are recycled
+			// while other goroutines are popping.
+			for i := 0; i < n; i++ {
+				s.Push(w*n + i)
+				if v, ok := s.Pop(); ok {
+					seen[v].Add(1)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	for v, ok := s.Pop(); ok; v, ok = s.Pop() {
+		seen[v].Add(1)
+	}
+	for v := range seen {
+		if c := seen[v].Load(); c != 1 {
+			t.Fatalf("value %d popped %d times", v, c)
+		}
+	}
+}
+
+func BenchmarkPooledStack(b *testing.B) {
+	b.Run("Stack", func(b *testing.B) {
+		s := lockfree.NewStack()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.Push(nil)
+				s.Pop()
+			}
+		})
+	})
+	b.Run("PooledStack", func(b *testing.B) {
+		s := lockfree.NewPooledStack[interface{}]()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.Push(nil)
+				s.Pop()
+			}
+		})
+	})
+}
@@ -5,29 +5,75 @@
 package lockfree
 
 import (
+	"context"
+	"errors"
+	"runtime"
 	"sync/atomic"
 	"unsafe"
 )
 
+// ErrClosed is returned by DequeueCtx if the queue is closed and
+// drained.
+var ErrClosed = errors.New("queue is closed")
+
 // Queue implements lock-free FIFO freelist based queue.
 // ref: https://dl.acm.org/citation.cfm?doid=248052.248106
+//
+// Enqueue and TryDequeue are lock-free. DequeueCtx blocks on an empty
+// queue, a blocked consumer registers itself as a waiter and parks on
+// a channel, which Enqueue signals only if there are waiters.
 type Queue struct {
 	head unsafe.Pointer
 	tail unsafe.Pointer
 	len  uint64
+
+	waiters int32
+	state   uint64        // queueClosed | the number of ongoing TryEnqueue
+	signal  chan struct{} // a pending wakeup of a waiter
+	done    chan struct{} // closed by Close
 }
 
+// queueClosed is the bit of Queue.state that is set by Close.
+const queueClosed = 1 << 63
+
 // NewQueue creates a new lock-free queue.
 func NewQueue() *Queue {
 	head := directItem{next: nil, v: nil} // allocate a free item
 	return &Queue{
-		tail: unsafe.Pointer(&head), // both head and tail points
-		head: unsafe.Pointer(&head), // to the free item
+		tail:   unsafe.Pointer(&head), // both head and tail points
+		head:   unsafe.Pointer(&head), // to the free item
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
 	}
 }
 
-// Enqueue puts the given value v at the tail of the queue.
+// Enqueue puts the given value v at the tail of the queue. It
+// enqueues even if the queue is closed, and it is not ordered with a
+// concurrent Close: DequeueCtx may return ErrClosed before the value.
+// Use TryEnqueue to stop producing after Close.
 func (q *Queue) Enqueue(v interface{}) {
+	q.enqueue(v)
+}
+
+// TryEnqueue puts the given value v at the tail of the queue, and
+// returns true, unless the queue is closed. A value that TryEnqueue
+// succeeds to put is always returned by DequeueCtx before ErrClosed.
+func (q *Queue) TryEnqueue(v interface{}) bool {
+	for {
+		s := atomic.LoadUint64(&q.state)
+		if s&queueClosed != 0 {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&q.state, s, s+1) {
+			q.enqueue(v)
+			atomic.AddUint64(&q.state, ^uint64(0))
+			return true
+		}
+	}
+}
+
+// enqueue puts v at the tail of the queue.
+func (q *Queue) enqueue(v interface{}) {
 	i := &directItem{next: nil, v: v} // allocate new item
 	var last, lastnext *directItem
 	for {
@@ -38,6 +84,9 @@ func (q *Queue) Enqueue(v interface{}) {
 				if casitem(&last.next, lastnext, i) { // try to link item at the end of linked list
 					casitem(&q.tail, last, i) // enqueue is done. try swing tail to the inserted node
 					atomic.AddUint64(&q.len, 1)
+					if atomic.LoadInt32(&q.waiters) > 0 {
+						q.wake()
+					}
 					return
 				}
 			} else { // tail was not pointing to the last node
@@ -50,6 +99,13 @@ func (q *Queue) Enqueue(v interface{}) {
 // Dequeue removes and returns the value at the head of the queue.
 // It returns nil if the queue is empty.
 func (q *Queue) Dequeue() interface{} {
+	v, _ := q.TryDequeue()
+	return v
+}
+
+// TryDequeue removes and returns the value at the head of the queue.
+// It returns false if the queue is empty.
+func (q *Queue) TryDequeue() (interface{}, bool) {
 	var first, last, firstnext *directItem
 	for {
 		first = loaditem(&q.head)
@@ -58,14 +114,14 @@ func (q *Queue) Dequeue() interface{} {
 		if first == loaditem(&q.head) { // are head, tail and next consistent?
 			if first == last { // is queue empty?
 				if firstnext == nil { // queue is empty, couldn't dequeue
-					return nil
+					return nil, false
 				}
 				casitem(&q.tail, last, firstnext) // tail is falling behind, try to advance it
 			} else { // read value before cas, otherwise another dequeue might free the next node
 				v := firstnext.v
 				if casitem(&q.head, first, firstnext) { // try to swing head to the next node
 					atomic.AddUint64(&q.len, ^uint64(0))
-					return v // queue was not empty and dequeue finished.
+					return v, true // queue was not empty and dequeue finished.
 				}
 			}
 		}
@@ -76,3 +132,72 @@ func (q *Queue) Dequeue() interface{} {
 func (q *Queue) Length() uint64 {
 	return atomic.LoadUint64(&q.len)
 }
+
+// DequeueCtx removes and returns the value at the head of the queue.
+// If the queue is empty, it blocks until a value is enqueued, the
+// queue is closed, or the context is done. After the queue is closed,
+// it returns the remaining values, and then ErrClosed.
+func (q *Queue) DequeueCtx(ctx context.Context) (interface{}, error) {
+	if v, ok := q.TryDequeue(); ok {
+		return v, nil
+	}
+	atomic.AddInt32(&q.waiters, 1)
+	defer atomic.AddInt32(&q.waiters, -1)
+	for {
+		// check again after registering as a waiter, an Enqueue
+		// that finished before will not signal.
+		if v, ok := q.TryDequeue(); ok {
+			// pass on the wakeup that may have been merged with
+			// ours, if more values are left for other waiters.
+			if atomic.LoadUint64(&q.len) > 0 && atomic.LoadInt32(&q.waiters) > 1 {
+				q.wake()
+			}
+			return v, nil
+		}
+		if s := atomic.LoadUint64(&q.state); s&queueClosed != 0 {
+			if s != queueClosed {
+				// a value of an ongoing TryEnqueue is not linked yet.
+				runtime.Gosched()
+				continue
+			}
+			// no more TryEnqueue can succeed, check the last values
+			// that were enqueued after our previous attempt.
+			if v, ok := q.TryDequeue(); ok {
+				return v, nil
+			}
+			return nil, ErrClosed
+		}
+		select {
+		case <-q.signal:
+		case <-q.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// wake wakes up a waiter, unless a wakeup is pending already.
+func (q *Queue) wake() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Close closes the queue, which wakes up all blocked consumers, and
+// lets DequeueCtx return ErrClosed once the queue is drained. It is
+// safe to call Close concurrently with producers: TryEnqueue fails
+// after Close, and the values of the TryEnqueue calls that are ongoing
+// at Close are drained as well.
+func (q *Queue) Close() {
+	for {
+		s := atomic.LoadUint64(&q.state)
+		if s&queueClosed != 0 {
+			return
+		}
+		if atomic.CompareAndSwapUint64(&q.state, s, s|queueClosed) {
+			close(q.done)
+			return
+		}
+	}
+}
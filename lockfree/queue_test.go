@@ -5,13 +5,18 @@
 package lockfree_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"changkun.de/x/pkg/lockfree"
+	"changkun.de/x/pkg/lockfree/lincheck"
 )
 
 func TestQueueDequeueEmpty(t *testing.T) {
@@ -113,3 +118,210 @@ func BenchmarkQueue(b *testing.B) {
 		})
 	}
 }
+
+// queueOp enqueues v if enqueue is true, otherwise dequeues.
+type queueOp struct {
+	enqueue bool
+	v       int
+}
+
+var queueModel = lincheck.Model[[]int, queueOp, interface{}]{
+	Init: func() []int { return nil },
+	Step: func(s []int, in queueOp, out interface{}) (bool, []int) {
+		if in.enqueue {
+			return true, append(s[:len(s):len(s)], in.v)
+		}
+		if len(s) == 0 {
+			return out == nil, s
+		}
+		return out == s[0], s[1:]
+	},
+	Equal: func(a, b []int) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	},
+}
+
+func TestQueueLinearizable(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		q := lockfree.NewQueue()
+		var (
+			r  lincheck.Recorder[queueOp, interface{}]
+			wg sync.WaitGroup
+		)
+		for c := 0; c < 4; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				for i := 0; i < 30; i++ {
+					in := queueOp{enqueue: rand.Intn(2) == 0, v: c*100 + i}
+					r.Record(c, in, func() interface{} {
+						if in.enqueue {
+							q.Enqueue(in.v)
+							return nil
+						}
+						return q.Dequeue()
+					})
+				}
+			}(c)
+		}
+		wg.Wait()
+		if !lincheck.Check(queueModel, r.Operations()) {
+			t.Fatalf("history is not linearizable: %+v", r.Operations())
+		}
+	}
+}
+
+func TestQueueTryDequeue(t *testing.T) {
+	q := lockfree.NewQueue()
+	if _, ok := q.TryDequeue(); ok {
+		t.Fatalf("dequeue empty queue succeeds")
+	}
+	q.Enqueue(nil)
+	if v, ok := q.TryDequeue(); !ok || v != nil {
+		t.Fatalf("dequeue nil value wrong, got %v, %v", v, ok)
+	}
+}
+
+func TestQueueDequeueCtx(t *testing.T) {
+	q := lockfree.NewQueue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.DequeueCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Enqueue(42)
+	}()
+	if v, err := q.DequeueCtx(context.Background()); err != nil || v != 42 {
+		t.Fatalf("blocking dequeue wrong, got %v, %v", v, err)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	const (
+		producers = 4
+		consumers = 4
+		n         = 10000
+	)
+	q := lockfree.NewQueue()
+	var (
+		pwg, cwg sync.WaitGroup
+		got      atomic.Int64
+	)
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func() {
+			defer cwg.Done()
+			for {
+				_, err := q.DequeueCtx(context.Background())
+				if errors.Is(err, lockfree.ErrClosed) {
+					return
+				}
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				got.Add(1)
+			}
+		}()
+	}
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func() {
+			defer pwg.Done()
+			for i := 0; i < n; i++ {
+				q.Enqueue(i)
+				if i%100 == 0 {
+					time.Sleep(time.Microsecond) // let consumers park
+				}
+			}
+		}()
+	}
+	pwg.Wait()
+	q.Close()
+	cwg.Wait()
+	if got.Load() != producers*n {
+		t.Fatalf("closed queue is not drained, want %d, got %d", producers*n, got.Load())
+	}
+
+	if q.TryEnqueue(1) {
+		t.Fatalf("TryEnqueue succeeds on closed queue")
+	}
+	q.Enqueue(2) // Enqueue still works after Close
+	if v, err := q.DequeueCtx(context.Background()); err != nil || v != 2 {
+		t.Fatalf("dequeue after close wrong, got %v, %v", v, err)
+	}
+	if _, err := q.DequeueCtx(context.Background()); !errors.Is(err, lockfree.ErrClosed) {
+		t.Fatalf("want ErrClosed, got %v", err)
+	}
+}
+
+// TestQueueCloseConcurrent closes the queue while producers are still
+// enqueueing: every value that TryEnqueue accepts must be dequeued.
+func TestQueueCloseConcurrent(t *testing.T) {
+	const (
+		producers = 4
+		consumers = 4
+	)
+	for round := 0; round < 20; round++ {
+		q := lockfree.NewQueue()
+		var (
+			pwg, cwg  sync.WaitGroup
+			put, got  atomic.Int64
+			producing atomic.Int32
+		)
+		for c := 0; c < consumers; c++ {
+			cwg.Add(1)
+			go func() {
+				defer cwg.Done()
+				for {
+					_, err := q.DequeueCtx(context.Background())
+					if errors.Is(err, lockfree.ErrClosed) {
+						return
+					}
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+						return
+					}
+					got.Add(1)
+				}
+			}()
+		}
+		for p := 0; p < producers; p++ {
+			pwg.Add(1)
+			producing.Add(1)
+			go func() {
+				defer pwg.Done()
+				for i := 0; q.TryEnqueue(i); i++ {
+					put.Add(1)
+					if i == 100 {
+						producing.Add(-1)
+					}
+					if i%10 == 0 {
+						runtime.Gosched()
+					}
+				}
+			}()
+		}
+		for producing.Load() > 0 {
+			runtime.Gosched()
+		}
+		q.Close()
+		pwg.Wait()
+		cwg.Wait()
+		if got.Load() != put.Load() {
+			t.Fatalf("closed queue is not drained, want %d, got %d", put.Load(), got.Load())
+		}
+	}
+}
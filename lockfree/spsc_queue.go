@@ -0,0 +1,78 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import "sync/atomic"
+
+// SPSCQueue implements a wait-free bounded single-producer
+// single-consumer FIFO queue on a ring buffer. The producer owns the
+// tail and the consumer owns the head, and each side caches the index
+// of the other side, so that it only touches the shared cache line
+// when the cached index says the queue is full or empty.
+//
+// TryEnqueue and TryDequeue must not be called concurrently with
+// themselves, but can be called concurrently with each other.
+type SPSCQueue[T any] struct {
+	_          cacheLinePad
+	head       atomic.Uint64
+	cachedTail uint64 // consumer's copy of tail
+	_          cacheLinePad
+	tail       atomic.Uint64
+	cachedHead uint64 // producer's copy of head
+	_          cacheLinePad
+	mask       uint64
+	buf        []T
+}
+
+// NewSPSCQueue creates a single-producer single-consumer queue that
+// holds at least capacity values. The capacity is rounded up to a
+// power of two.
+func NewSPSCQueue[T any](capacity int) *SPSCQueue[T] {
+	n := ringSize(capacity, 1)
+	return &SPSCQueue[T]{mask: n - 1, buf: make([]T, n)}
+}
+
+// TryEnqueue puts the given value v at the tail of the queue.
+// It returns false if the queue is full.
+func (q *SPSCQueue[T]) TryEnqueue(v T) bool {
+	t := q.tail.Load()
+	if t-q.cachedHead > q.mask {
+		q.cachedHead = q.head.Load()
+		if t-q.cachedHead > q.mask {
+			return false
+		}
+	}
+	q.buf[t&q.mask] = v
+	q.tail.Store(t + 1)
+	return true
+}
+
+// TryDequeue removes and returns the value at the head of the queue.
+// It returns false if the queue is empty.
+func (q *SPSCQueue[T]) TryDequeue() (v T, ok bool) {
+	h := q.head.Load()
+	if h == q.cachedTail {
+		q.cachedTail = q.tail.Load()
+		if h == q.cachedTail {
+			return
+		}
+	}
+	v = q.buf[h&q.mask]
+	var zero T
+	q.buf[h&q.mask] = zero
+	q.head.Store(h + 1)
+	return v, true
+}
+
+// Length returns the length of the queue. The length is a snapshot
+// if the queue is accessed concurrently.
+func (q *SPSCQueue[T]) Length() uint64 {
+	return ringLength(&q.head, &q.tail, q.mask)
+}
+
+// Capacity returns the maximum length of the queue.
+func (q *SPSCQueue[T]) Capacity() uint64 {
+	return q.mask + 1
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestSPSCQueue(t *testing.T) {
+	testTryQueueSequential(t, lockfree.NewSPSCQueue[int](1))
+	testTryQueueSequential(t, lockfree.NewSPSCQueue[int](6))
+	testTryQueueConcurrent(t, lockfree.NewSPSCQueue[int](64), 1, 1)
+}
+
+// BenchmarkSPSCQueue compares queues with one producer and one
+// consumer.
+func BenchmarkSPSCQueue(b *testing.B) {
+	for _, q := range [...]queueInterface{
+		lockfree.NewQueue(),
+		newMutexQueue(),
+		spscQueue{lockfree.NewSPSCQueue[interface{}](1 << 12)},
+	} {
+		b.Run(fmt.Sprintf("%T", q), func(b *testing.B) {
+			benchmarkProducerConsumer(b, q, 1)
+		})
+	}
+}
+
+type spscQueue struct {
+	q *lockfree.SPSCQueue[interface{}]
+}
+
+func (q spscQueue) Enqueue(v interface{}) {
+	for !q.q.TryEnqueue(v) {
+		runtime.Gosched()
+	}
+}
+func (q spscQueue) Dequeue() interface{} {
+	v, _ := q.q.TryDequeue()
+	return v
+}
+
+// benchmarkProducerConsumer enqueues b.N values from producers, and
+// dequeues all of them from a single consumer.
+func benchmarkProducerConsumer(b *testing.B, q queueInterface, producers int) {
+	done := make(chan struct{})
+	go func() {
+		for n := 0; n < b.N; {
+			if q.Dequeue() != nil {
+				n++
+			} else {
+				runtime.Gosched()
+			}
+		}
+		close(done)
+	}()
+	per := b.N / producers
+	for p := 0; p < producers; p++ {
+		m := per
+		if p == 0 {
+			m += b.N % producers
+		}
+		go func() {
+			for i := 0; i < m; i++ {
+				q.Enqueue(i)
+			}
+		}()
+	}
+	<-done
+}
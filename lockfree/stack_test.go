@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"changkun.de/x/pkg/lockfree"
+	"changkun.de/x/pkg/lockfree/lincheck"
 )
 
 func TestStackPopEmpty(t *testing.T) {
@@ -91,3 +92,53 @@ func BenchmarkStack(b *testing.B) {
 		})
 	}
 }
+
+// stackOp pushes v if push is true, otherwise pops.
+type stackOp struct {
+	push bool
+	v    int
+}
+
+var stackModel = lincheck.Model[[]int, stackOp, interface{}]{
+	Init: func() []int { return nil },
+	Step: func(s []int, in stackOp, out interface{}) (bool, []int) {
+		if in.push {
+			return true, append(s[:len(s):len(s)], in.v)
+		}
+		if len(s) == 0 {
+			return out == nil, s
+		}
+		return out == s[len(s)-1], s[:len(s)-1]
+	},
+	Equal: queueModel.Equal,
+}
+
+func TestStackLinearizable(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		s := lockfree.NewStack()
+		var (
+			r  lincheck.Recorder[stackOp, interface{}]
+			wg sync.WaitGroup
+		)
+		for c := 0; c < 4; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				for i := 0; i < 30; i++ {
+					in := stackOp{push: rand.Intn(2) == 0, v: c*100 + i}
+					r.Record(c, in, func() interface{} {
+						if in.push {
+							s.Push(in.v)
+							return nil
+						}
+						return s.Pop()
+					})
+				}
+			}(c)
+		}
+		wg.Wait()
+		if !lincheck.Check(stackModel, r.Operations()) {
+			t.Fatalf("history is not linearizable: %+v", r.Operations())
+		}
+	}
+}
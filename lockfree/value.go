@@ -0,0 +1,53 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import "sync/atomic"
+
+// Value is a typed atomic.Value. Unlike atomic.Value, the zero value
+// holds the zero value of T, and if T is an interface type, values of
+// different concrete types can be stored.
+type Value[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// Load atomically loads the value.
+func (v *Value[T]) Load() (x T) {
+	if p := v.p.Load(); p != nil {
+		return *p
+	}
+	return
+}
+
+// Store atomically stores x.
+func (v *Value[T]) Store(x T) {
+	v.p.Store(&x)
+}
+
+// Swap atomically stores new and returns the old value.
+func (v *Value[T]) Swap(new T) (old T) {
+	if p := v.p.Swap(&new); p != nil {
+		return *p
+	}
+	return
+}
+
+// CompareAndSwap stores new if the value equals old, and reports
+// whether new is stored. It panics if T is not comparable.
+func (v *Value[T]) CompareAndSwap(old, new T) bool {
+	for {
+		p := v.p.Load()
+		var cur T
+		if p != nil {
+			cur = *p
+		}
+		if any(cur) != any(old) {
+			return false
+		}
+		if v.p.CompareAndSwap(p, &new) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestValue(t *testing.T) {
+	var v lockfree.Value[string]
+	if v.Load() != "" {
+		t.Fatalf("zero value holds %q", v.Load())
+	}
+	if !v.CompareAndSwap("", "a") || v.CompareAndSwap("", "b") {
+		t.Fatalf("CompareAndSwap wrong, got %q", v.Load())
+	}
+	if v.Swap("c") != "a" || v.Load() != "c" {
+		t.Fatalf("Swap wrong, got %q", v.Load())
+	}
+
+	// values of different concrete types.
+	var e lockfree.Value[error]
+	e.Store(io.EOF)
+	e.Store(errors.New("other"))
+	if e.Load().Error() != "other" {
+		t.Fatalf("Store wrong, got %v", e.Load())
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree
+
+import "sync/atomic"
+
+// WorkStealingDeque implements a lock-free Chase-Lev work-stealing
+// deque on a growable ring buffer. The owner pushes and pops values
+// at the bottom in LIFO order, and other goroutines steal values from
+// the top in FIFO order. Only the last value is contended between the
+// owner and the thieves.
+//
+// Push and Pop must only be called by the owner, Steal is safe for
+// concurrent use.
+//
+// Paper: Chase, David and Lev, Yossi (2005). "Dynamic circular
+// work-stealing deque". SPAA '05: 21–28
+type WorkStealingDeque[T any] struct {
+	_      cacheLinePad
+	top    atomic.Int64 // stolen by thieves
+	_      cacheLinePad
+	bottom atomic.Int64 // owned by the owner
+	_      cacheLinePad
+	ring   atomic.Pointer[wsRing[T]]
+}
+
+// wsRing is a ring buffer of values, a full ring is replaced by a
+// larger copy, which the thieves that loaded the old ring can still
+// read from.
+type wsRing[T any] struct {
+	mask  int64
+	items []atomic.Pointer[T]
+}
+
+func newWSRing[T any](n int64) *wsRing[T] {
+	return &wsRing[T]{mask: n - 1, items: make([]atomic.Pointer[T], n)}
+}
+
+func (r *wsRing[T]) load(i int64) *T     { return r.items[i&r.mask].Load() }
+func (r *wsRing[T]) store(i int64, v *T) { r.items[i&r.mask].Store(v) }
+
+// NewWorkStealingDeque creates an empty work-stealing deque.
+func NewWorkStealingDeque[T any]() *WorkStealingDeque[T] {
+	d := &WorkStealingDeque[T]{}
+	d.ring.Store(newWSRing[T](32))
+	return d
+}
+
+// Push pushes v at the bottom of the deque.
+func (d *WorkStealingDeque[T]) Push(v T) {
+	b := d.bottom.Load()
+	t := d.top.Load()
+	r := d.ring.Load()
+	if b-t > r.mask { // full, grow the ring
+		nr := newWSRing[T](2 * (r.mask + 1))
+		for i := t; i < b; i++ {
+			nr.store(i, r.load(i))
+		}
+		d.ring.Store(nr)
+		r = nr
+	}
+	r.store(b, &v)
+	d.bottom.Store(b + 1)
+}
+
+// Pop removes and returns the value at the bottom of the deque.
+// It returns false if the deque is empty.
+func (d *WorkStealingDeque[T]) Pop() (v T, ok bool) {
+	b := d.bottom.Load() - 1
+	r := d.ring.Load()
+	// publish the claim of the bottom before reading top, so that
+	// thieves and the owner can not both take the last value.
+	d.bottom.Store(b)
+	t := d.top.Load()
+	if t > b { // empty
+		d.bottom.Store(b + 1)
+		return
+	}
+	p := r.load(b)
+	if t == b { // the last value, race with thieves for it
+		ok = d.top.CompareAndSwap(t, t+1)
+		d.bottom.Store(b + 1)
+		if !ok {
+			return
+		}
+		return *p, true
+	}
+	return *p, true
+}
+
+// Steal removes and returns the value at the top of the deque.
+// It returns false if the deque is empty.
+func (d *WorkStealingDeque[T]) Steal() (v T, ok bool) {
+	for {
+		t := d.top.Load()
+		b := d.bottom.Load()
+		if t >= b {
+			return
+		}
+		// read the value before the CAS, after which the owner may
+		// overwrite the slot.
+		p := d.ring.Load().load(t)
+		if d.top.CompareAndSwap(t, t+1) {
+			return *p, true
+		}
+	}
+}
+
+// Length returns the length of the deque. The length is a snapshot
+// if the deque is accessed concurrently.
+func (d *WorkStealingDeque[T]) Length() uint64 {
+	t := d.top.Load()
+	b := d.bottom.Load()
+	if b <= t {
+		return 0
+	}
+	return uint64(b - t)
+}
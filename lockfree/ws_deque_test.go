@@ -0,0 +1,81 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lockfree_test
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"changkun.de/x/pkg/lockfree"
+)
+
+func TestWorkStealingDeque(t *testing.T) {
+	d := lockfree.NewWorkStealingDeque[int]()
+	if _, ok := d.Pop(); ok {
+		t.Fatalf("pop empty deque succeeds")
+	}
+	if _, ok := d.Steal(); ok {
+		t.Fatalf("steal empty deque succeeds")
+	}
+	for i := 0; i < 100; i++ {
+		d.Push(i)
+	}
+	if d.Length() != 100 {
+		t.Fatalf("length wrong, want 100, got %d", d.Length())
+	}
+	if v, ok := d.Steal(); !ok || v != 0 {
+		t.Fatalf("steal wrong, want 0, got %d", v)
+	}
+	for i := 99; i > 0; i-- {
+		if v, ok := d.Pop(); !ok || v != i {
+			t.Fatalf("pop wrong, want %d, got %d", i, v)
+		}
+	}
+}
+
+func TestWorkStealingDequeConcurrent(t *testing.T) {
+	const (
+		thieves = 4
+		n       = 50000
+	)
+	d := lockfree.NewWorkStealingDeque[int]()
+	seen := make([]atomic.Int32, n)
+	var (
+		wg   sync.WaitGroup
+		done atomic.Bool
+	)
+	for i := 0; i < thieves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() || d.Length() > 0 {
+				if v, ok := d.Steal(); ok {
+					seen[v].Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	// the owner pushes in bursts and pops some of them, which races
+	// with the thieves on the last value.
+	for i := 0; i < n; i++ {
+		d.Push(i)
+		if i%3 == 0 {
+			if v, ok := d.Pop(); ok {
+				seen[v].Add(1)
+			}
+		}
+	}
+	done.Store(true)
+	wg.Wait()
+	for v := range seen {
+		if c := seen[v].Load(); c != 1 {
+			t.Fatalf("value %d taken %d times", v, c)
+		}
+	}
+}
@@ -18,6 +18,13 @@ import (
 var (
 	ErrNumElem = errors.New("bad number of elements")
 	ErrMatSize = errors.New("bad size of matrix")
+
+	ErrNotSymmetric  = errors.New("matrix is not symmetric")
+	ErrEigenNum      = errors.New("bad number of eigenpairs")
+	ErrNoConvergence = errors.New("iteration does not converge")
+
+	ErrSingular            = errors.New("matrix is singular")
+	ErrNotPositiveDefinite = errors.New("matrix is not positive definite")
 )
 
 // Dense implements Matrix interface
@@ -0,0 +1,119 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import "math"
+
+// Cholesky is the Cholesky decomposition of a symmetric positive
+// definite matrix A, such that A = L·Lᵀ, where L is lower triangular
+// with a positive diagonal. It takes half the time of LU.
+type Cholesky struct {
+	l *Dense
+}
+
+// NewCholesky computes the Cholesky decomposition of A.
+// Use block 36 version here, as DotBlock.
+func NewCholesky(A *Dense) (*Cholesky, error) {
+	return NewCholeskyBlock(36, A)
+}
+
+// NewCholeskyBlock computes the Cholesky decomposition of A by the
+// right-looking blocked algorithm, which factorizes a panel of
+// blockSize columns at a time, then updates the lower triangle of the
+// trailing matrix by dot products of contiguous rows. If blockSize < 1
+// or blockSize >= n, it runs the unblocked algorithm.
+//
+// It returns ErrNotPositiveDefinite if A is not positive definite.
+func NewCholeskyBlock(blockSize int, A *Dense) (*Cholesky, error) {
+	if err := A.checkSymmetric(); err != nil {
+		return nil, err
+	}
+	n := A.n
+	if blockSize < 1 || blockSize > n {
+		blockSize = n
+	}
+	L := Zero(n, n)
+	for i := 0; i < n; i++ {
+		copy(L.data[i*n:i*n+i+1], A.data[i*n:i*n+i+1])
+	}
+	a := L.data
+
+	for k0 := 0; k0 < n; k0 += blockSize {
+		k1 := min(k0+blockSize, n)
+
+		// factorize the panel a[k0:n, k0:k1].
+		for j := k0; j < k1; j++ {
+			lj := a[j*n+k0 : j*n+j]
+			d := a[j*n+j] - dot(lj, lj)
+			if !(d > 0) {
+				return nil, ErrNotPositiveDefinite
+			}
+			d = math.Sqrt(d)
+			a[j*n+j] = d
+			for i := j + 1; i < n; i++ {
+				a[i*n+j] = (a[i*n+j] - dot(a[i*n+k0:i*n+j], lj)) / d
+			}
+		}
+
+		// A22 -= L21·L21ᵀ on the lower triangle.
+		for i := k1; i < n; i++ {
+			li := a[i*n+k0 : i*n+k1]
+			for j := k1; j <= i; j++ {
+				a[i*n+j] -= dot(li, a[j*n+k0:j*n+k1])
+			}
+		}
+	}
+	return &Cholesky{l: L}, nil
+}
+
+// L returns the lower triangular factor.
+func (c *Cholesky) L() *Dense {
+	L := Zero(c.l.m, c.l.n)
+	copy(L.data, c.l.data)
+	return L
+}
+
+// Det returns the determinant of A.
+func (c *Cholesky) Det() float64 {
+	det := 1.0
+	for i := 0; i < c.l.n; i++ {
+		det *= c.l.At(i, i)
+	}
+	return det * det
+}
+
+// Solve returns X such that A·X = B.
+func (c *Cholesky) Solve(B *Dense) (*Dense, error) {
+	n := c.l.n
+	if B.m != n {
+		return nil, ErrMatSize
+	}
+	k, l := B.n, c.l.data
+	X := Zero(n, k)
+	copy(X.data, B.data)
+	x := X.data
+	// L·Y = B
+	for i := 0; i < n; i++ {
+		xi := x[i*k : (i+1)*k]
+		for j := 0; j < i; j++ {
+			axpy(-l[i*n+j], x[j*k:(j+1)*k], xi)
+		}
+		scale(xi, 1/l[i*n+i])
+	}
+	// Lᵀ·X = Y
+	for i := n - 1; i >= 0; i-- {
+		xi := x[i*k : (i+1)*k]
+		scale(xi, 1/l[i*n+i])
+		for j := 0; j < i; j++ {
+			axpy(-l[i*n+j], xi, x[j*k:(j+1)*k])
+		}
+	}
+	return X, nil
+}
+
+// Inverse returns the inverse of A.
+func (c *Cholesky) Inverse() (*Dense, error) {
+	return c.Solve(eye(c.l.n))
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	gonum "gonum.org/v1/gonum/mat"
+)
+
+// randSPD returns a random n×n symmetric positive definite matrix.
+func randSPD(r *rand.Rand, n int) *Dense {
+	A := randDense(r, n, n)
+	S := Zero(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			s := 0.0
+			for k := 0; k < n; k++ {
+				s += A.At(k, i) * A.At(k, j)
+			}
+			S.Set(i, j, s)
+		}
+		S.Inc(i, i, float64(n))
+	}
+	return S
+}
+
+func TestCholesky(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{1, 2, 5, 36, 37, 100} {
+		A := randSPD(r, n)
+		for _, bs := range []int{0, 1, 4, 36} {
+			f, err := NewCholeskyBlock(bs, A)
+			if err != nil {
+				t.Fatalf("NewCholeskyBlock(%d, %d×%d) error: %v", bs, n, n, err)
+			}
+			L := f.L()
+			LT := Zero(n, n)
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					if j > i && L.At(i, j) != 0 {
+						t.Fatalf("L is not lower triangular")
+					}
+					LT.Set(j, i, L.At(i, j))
+				}
+			}
+			if LLT := mustDot(t, L, LT); !approxEqual(A, LLT, 1e-10*float64(n)*float64(n)) {
+				t.Fatalf("NewCholeskyBlock(%d, %d×%d): A != L·Lᵀ", bs, n, n)
+			}
+		}
+
+		f, _ := NewCholesky(A)
+		if got, want := f.Det(), gonum.Det(toGonum(A)); math.Abs(got-want) > 1e-9*math.Abs(want) {
+			t.Fatalf("Det(%d×%d) = %v, want %v", n, n, got, want)
+		}
+		B := randDense(r, n, 3)
+		X, err := f.Solve(B)
+		if err != nil {
+			t.Fatalf("Solve(%d×%d) error: %v", n, n, err)
+		}
+		if AX := mustDot(t, A, X); !approxEqual(AX, B, 1e-8) {
+			t.Fatalf("Solve(%d×%d): A·X != B", n, n)
+		}
+		inv, _ := f.Inverse()
+		if I := mustDot(t, A, inv); !approxEqual(I, eye(n), 1e-8) {
+			t.Fatalf("A·Inverse(A) != I for %d×%d", n, n)
+		}
+	}
+
+	indefinite, _ := NewDense(2, 2)(1, 2, 2, 1)
+	if _, err := NewCholesky(indefinite); err != ErrNotPositiveDefinite {
+		t.Fatalf("NewCholesky(indefinite) error = %v, want %v", err, ErrNotPositiveDefinite)
+	}
+	nonsym, _ := NewDense(2, 2)(2, 1, 0, 2)
+	if _, err := NewCholesky(nonsym); err != ErrNotSymmetric {
+		t.Fatalf("NewCholesky(nonsymmetric) error = %v, want %v", err, ErrNotSymmetric)
+	}
+}
+
+func BenchmarkCholesky(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{100, 500} {
+		A := randSPD(r, n)
+		for _, bs := range []int{0, 36, 64} {
+			b.Run(fmt.Sprintf("block-%d/size-%d", bs, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					NewCholeskyBlock(bs, A)
+				}
+			})
+		}
+	}
+}
@@ -3,3 +3,719 @@
 // license that can be found in the LICENSE file.
 
 package mat
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// eps is the machine epsilon of float64.
+	eps = 1.0 / (1 << 52)
+	// eigenTol is the relative residual ||Av-λv|| / ||A|| under which
+	// an iterative eigenpair is considered converged.
+	eigenTol = 1e-10
+	// maxJacobiSweeps bounds the sweeps of EigenSym and NewSVD, which
+	// converge quadratically and rarely take more than 10 sweeps.
+	maxJacobiSweeps = 100
+	// maxQRIters bounds the QR iterations of Eigen per eigenvalue.
+	maxQRIters = 30
+	// maxPowerIters bounds the iterations of PowerIteration.
+	maxPowerIters = 10000
+)
+
+// rows copies A into a slice of rows.
+func (A *Dense) rows() [][]float64 {
+	a := make([][]float64, A.m)
+	for i := range a {
+		a[i] = append([]float64(nil), A.data[i*A.n:(i+1)*A.n]...)
+	}
+	return a
+}
+
+// frobenius returns the Frobenius norm of A.
+func (A *Dense) frobenius() float64 {
+	s := 0.0
+	for _, v := range A.data {
+		s += v * v
+	}
+	return math.Sqrt(s)
+}
+
+// checkSymmetric returns an error if A is not square or not symmetric
+// up to rounding errors.
+func (A *Dense) checkSymmetric() error {
+	if A.m != A.n {
+		return ErrMatSize
+	}
+	tol := 1e3 * eps * A.frobenius()
+	for i := 0; i < A.n; i++ {
+		for j := 0; j < i; j++ {
+			if math.Abs(A.At(i, j)-A.At(j, i)) > tol {
+				return ErrNotSymmetric
+			}
+		}
+	}
+	return nil
+}
+
+// EigenSym computes the eigenvalues and eigenvectors of a symmetric
+// matrix A, such that A·V = V·diag(values). The values are in
+// ascending order, and the i-th column of V is the orthonormal
+// eigenvector of the i-th value.
+//
+// It uses the cyclic Jacobi method, which diagonalizes A by plane
+// rotations in O(n^3) time per sweep. It is slower than tridiagonal
+// QR for large matrices, but computes small eigenvalues to high
+// relative accuracy.
+// Paper: Demmel, James and Veselić, Krešimir (1992). "Jacobi's method
+// is more accurate than QR". SIAM Journal on Matrix Analysis and
+// Applications 13 (4): 1204–1245
+func EigenSym(A *Dense) (values []float64, V *Dense, err error) {
+	if err := A.checkSymmetric(); err != nil {
+		return nil, nil, err
+	}
+	values, v, err := jacobi(A.rows())
+	if err != nil {
+		return nil, nil, err
+	}
+	n := len(values)
+	V = Zero(n, n)
+	for i := 0; i < n; i++ {
+		copy(V.data[i*n:(i+1)*n], v[i])
+	}
+	return values, V, nil
+}
+
+// jacobi diagonalizes the symmetric matrix a in place and returns its
+// eigenvalues in ascending order and the eigenvectors as the columns
+// of v.
+func jacobi(a [][]float64) (values []float64, v [][]float64, err error) {
+	n := len(a)
+	v = make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+	fro := 0.0
+	for i := range a {
+		for j := range a[i] {
+			fro += a[i][j] * a[i][j]
+		}
+	}
+	fro = math.Sqrt(fro)
+
+	converged := false
+	for sweep := 0; sweep < maxJacobiSweeps; sweep++ {
+		off := 0.0
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+		if math.Sqrt(off) <= eps*fro {
+			converged = true
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				// The rotation of angle φ in the (p, q) plane that
+				// annihilates a[p][q], where t = tan φ is the smaller
+				// root of t² + 2θt - 1 = 0.
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if math.IsInf(theta*theta, 0) {
+					t = 0.5 / math.Abs(theta)
+				}
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				a[p][q], a[q][p] = 0, 0
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	if !converged && n > 1 {
+		return nil, nil, ErrNoConvergence
+	}
+
+	values = make([]float64, n)
+	for i := range values {
+		values[i] = a[i][i]
+	}
+	return sortEigen(values, v, func(x, y float64) bool { return x < y })
+}
+
+// sortEigen sorts values by less and permutes the columns of v
+// accordingly.
+func sortEigen(values []float64, v [][]float64, less func(x, y float64) bool) ([]float64, [][]float64, error) {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return less(values[idx[i]], values[idx[j]]) })
+	sorted := make([]float64, len(values))
+	vs := make([][]float64, len(v))
+	for k := range v {
+		vs[k] = make([]float64, len(idx))
+		for i, j := range idx {
+			vs[k][i] = v[k][j]
+		}
+	}
+	for i, j := range idx {
+		sorted[i] = values[j]
+	}
+	return sorted, vs, nil
+}
+
+// Eigen computes the eigenvalues of a general real square matrix A.
+// Complex eigenvalues come in conjugate pairs. The values are sorted
+// by their real part, then by their imaginary part.
+//
+// It balances A, reduces it to the upper Hessenberg form by
+// Householder reflections, then runs the Francis double-shift QR
+// iteration on it, which takes O(n^3) time.
+// Paper: Francis, J. G. F. (1961). "The QR Transformation: A Unitary
+// Analogue to the LR Transformation". The Computer Journal 4 (3):
+// 265–271
+func Eigen(A *Dense) ([]complex128, error) {
+	if A.m != A.n {
+		return nil, ErrMatSize
+	}
+	a := A.rows()
+	balance(a)
+	hessenberg(a)
+	values, err := hqr(a)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if real(values[i]) != real(values[j]) {
+			return real(values[i]) < real(values[j])
+		}
+		return imag(values[i]) < imag(values[j])
+	})
+	return values, nil
+}
+
+// balance scales the rows and columns of a by powers of 2 to make
+// their norms close, which preserves the eigenvalues and reduces the
+// rounding errors of the QR iteration.
+// Paper: Parlett, B. N. and Reinsch, C. (1969). "Balancing a matrix
+// for calculation of eigenvalues and eigenvectors". Numerische
+// Mathematik 13 (4): 293–304
+func balance(a [][]float64) {
+	const radix = 2
+	for done := false; !done; {
+		done = true
+		for i := range a {
+			r, c := 0.0, 0.0
+			for j := range a {
+				if j != i {
+					c += math.Abs(a[j][i])
+					r += math.Abs(a[i][j])
+				}
+			}
+			if c == 0 || r == 0 {
+				continue
+			}
+			s := c + r
+			f := 1.0
+			for g := r / radix; c < g; {
+				f *= radix
+				c *= radix * radix
+			}
+			for g := r * radix; c > g; {
+				f /= radix
+				c /= radix * radix
+			}
+			if (c+r)/f < 0.95*s {
+				done = false
+				for j := range a {
+					a[i][j] /= f
+					a[j][i] *= f
+				}
+			}
+		}
+	}
+}
+
+// hessenberg reduces a to the upper Hessenberg form in place by
+// Householder reflections, which preserves the eigenvalues.
+func hessenberg(a [][]float64) {
+	n := len(a)
+	v := make([]float64, n)
+	for k := 0; k < n-2; k++ {
+		nrm := 0.0
+		for i := k + 1; i < n; i++ {
+			nrm = math.Hypot(nrm, a[i][k])
+		}
+		if nrm == 0 {
+			continue
+		}
+		alpha := -math.Copysign(nrm, a[k+1][k])
+		vv := 0.0
+		for i := k + 1; i < n; i++ {
+			v[i] = a[i][k]
+			if i == k+1 {
+				v[i] -= alpha
+			}
+			vv += v[i] * v[i]
+		}
+		// a = H·a·H, where H = I - 2vvᵀ/vᵀv.
+		for j := k; j < n; j++ {
+			s := 0.0
+			for i := k + 1; i < n; i++ {
+				s += v[i] * a[i][j]
+			}
+			f := 2 * s / vv
+			for i := k + 1; i < n; i++ {
+				a[i][j] -= f * v[i]
+			}
+		}
+		for i := 0; i < n; i++ {
+			s := 0.0
+			for j := k + 1; j < n; j++ {
+				s += a[i][j] * v[j]
+			}
+			f := 2 * s / vv
+			for j := k + 1; j < n; j++ {
+				a[i][j] -= f * v[j]
+			}
+		}
+		for i := k + 2; i < n; i++ {
+			a[i][k] = 0
+		}
+	}
+}
+
+// hqr computes the eigenvalues of the upper Hessenberg matrix a by the
+// Francis double-shift QR iteration, which destroys a. It deflates a
+// 1×1 or 2×2 block from the bottom once a subdiagonal element becomes
+// negligible.
+func hqr(a [][]float64) ([]complex128, error) {
+	n := len(a)
+	values := make([]complex128, n)
+	anorm := 0.0
+	for i := 0; i < n; i++ {
+		for j := max(i-1, 0); j < n; j++ {
+			anorm += math.Abs(a[i][j])
+		}
+	}
+
+	var p, q, r, s, t, w, x, y, z float64
+	for nn := n - 1; nn >= 0; {
+		for its := 0; ; its++ {
+			// find a negligible subdiagonal element a[l][l-1].
+			l := nn
+			for ; l > 0; l-- {
+				s = math.Abs(a[l-1][l-1]) + math.Abs(a[l][l])
+				if s == 0 {
+					s = anorm
+				}
+				if math.Abs(a[l][l-1]) <= eps*s {
+					a[l][l-1] = 0
+					break
+				}
+			}
+			x = a[nn][nn]
+			if l == nn { // a 1×1 block
+				values[nn] = complex(x+t, 0)
+				nn--
+				break
+			}
+			y = a[nn-1][nn-1]
+			w = a[nn][nn-1] * a[nn-1][nn]
+			if l == nn-1 { // a 2×2 block
+				p = 0.5 * (y - x)
+				q = p*p + w
+				z = math.Sqrt(math.Abs(q))
+				x += t
+				if q >= 0 {
+					z = p + math.Copysign(z, p)
+					values[nn-1], values[nn] = complex(x+z, 0), complex(x+z, 0)
+					if z != 0 {
+						values[nn] = complex(x-w/z, 0)
+					}
+				} else {
+					values[nn] = complex(x+p, -z)
+					values[nn-1] = cmplx.Conj(values[nn])
+				}
+				nn -= 2
+				break
+			}
+
+			if its == maxQRIters {
+				return nil, ErrNoConvergence
+			}
+			if its == 10 || its == 20 { // an exceptional shift
+				t += x
+				for i := 0; i <= nn; i++ {
+					a[i][i] -= x
+				}
+				s = math.Abs(a[nn][nn-1]) + math.Abs(a[nn-1][nn-2])
+				x = 0.75 * s
+				y = x
+				w = -0.4375 * s * s
+			}
+
+			// find two consecutive small subdiagonal elements to
+			// start the implicit double-shift QR step at row m.
+			m := nn - 2
+			for ; m >= l; m-- {
+				z = a[m][m]
+				r = x - z
+				s = y - z
+				p = (r*s-w)/a[m+1][m] + a[m][m+1]
+				q = a[m+1][m+1] - z - r - s
+				r = a[m+2][m+1]
+				s = math.Abs(p) + math.Abs(q) + math.Abs(r)
+				p /= s
+				q /= s
+				r /= s
+				if m == l {
+					break
+				}
+				u := math.Abs(a[m][m-1]) * (math.Abs(q) + math.Abs(r))
+				v := math.Abs(p) * (math.Abs(a[m-1][m-1]) + math.Abs(z) + math.Abs(a[m+1][m+1]))
+				if u <= eps*v {
+					break
+				}
+			}
+			for i := m; i < nn-1; i++ {
+				a[i+2][i] = 0
+				if i != m {
+					a[i+2][i-1] = 0
+				}
+			}
+
+			// chase the bulge down the subdiagonal.
+			for k := m; k < nn; k++ {
+				if k != m {
+					p = a[k][k-1]
+					q = a[k+1][k-1]
+					r = 0
+					if k+1 != nn {
+						r = a[k+2][k-1]
+					}
+					if x = math.Abs(p) + math.Abs(q) + math.Abs(r); x != 0 {
+						p /= x
+						q /= x
+						r /= x
+					}
+				}
+				if s = math.Copysign(math.Sqrt(p*p+q*q+r*r), p); s == 0 {
+					continue
+				}
+				if k == m {
+					if l != m {
+						a[k][k-1] = -a[k][k-1]
+					}
+				} else {
+					a[k][k-1] = -s * x
+				}
+				p += s
+				x = p / s
+				y = q / s
+				z = r / s
+				q /= p
+				r /= p
+				for j := k; j <= nn; j++ {
+					p = a[k][j] + q*a[k+1][j]
+					if k+1 != nn {
+						p += r * a[k+2][j]
+						a[k+2][j] -= p * z
+					}
+					a[k+1][j] -= p * y
+					a[k][j] -= p * x
+				}
+				for i := l; i <= min(nn, k+3); i++ {
+					p = x*a[i][k] + y*a[i][k+1]
+					if k+1 != nn {
+						p += z * a[i][k+2]
+						a[i][k+2] -= p * r
+					}
+					a[i][k+1] -= p * q
+					a[i][k] -= p
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// PowerIteration computes the k eigenpairs of the largest magnitude of
+// a symmetric matrix A by the orthogonal (subspace) iteration with
+// Rayleigh-Ritz projections. The values are in descending order of
+// magnitude, and the i-th column of V is the eigenvector of the i-th
+// value. It converges at the rate of |λ(k+1)/λ(k)| per iteration,
+// thus it is only practical if the k-th eigenvalue is well separated
+// from the rest, where each iteration takes O(kn^2) time.
+func PowerIteration(A *Dense, k int) (values []float64, V *Dense, err error) {
+	if err := A.checkSymmetric(); err != nil {
+		return nil, nil, err
+	}
+	n := A.n
+	if k < 1 || k > n {
+		return nil, nil, ErrEigenNum
+	}
+	anorm := A.frobenius()
+	rnd := rand.New(rand.NewSource(1))
+	x := make([][]float64, k) // the orthonormal basis of the subspace
+	for i := range x {
+		x[i] = make([]float64, n)
+		for j := range x[i] {
+			x[i][j] = rnd.NormFloat64()
+		}
+	}
+	orthonormalize(x, rnd)
+
+	z := make([][]float64, k)
+	for it := 0; it < maxPowerIters; it++ {
+		for i := range z {
+			z[i] = A.mulVec(x[i], z[i])
+		}
+		// Rayleigh-Ritz: diagonalize the projection H = XᵀAX, and
+		// rotate X and Z = AX by its eigenvectors.
+		h := make([][]float64, k)
+		for i := range h {
+			h[i] = make([]float64, k)
+			for j := range h[i] {
+				h[i][j] = dot(x[i], z[j])
+			}
+		}
+		for i := range h {
+			for j := 0; j < i; j++ {
+				h[i][j] = (h[i][j] + h[j][i]) / 2
+				h[j][i] = h[i][j]
+			}
+		}
+		theta, s, err := jacobi(h)
+		if err != nil {
+			return nil, nil, err
+		}
+		theta, s, _ = sortEigen(theta, s, func(a, b float64) bool { return math.Abs(a) > math.Abs(b) })
+		x, z = combine(x, s), combine(z, s)
+
+		converged := true
+		for i := range x {
+			res := 0.0
+			for j := range x[i] {
+				d := z[i][j] - theta[i]*x[i][j]
+				res += d * d
+			}
+			if math.Sqrt(res) > eigenTol*anorm {
+				converged = false
+				break
+			}
+		}
+		if converged {
+			return theta, columns(n, x), nil
+		}
+		x, z = z, x
+		orthonormalize(x, rnd)
+	}
+	return nil, nil, ErrNoConvergence
+}
+
+// Lanczos computes the k largest eigenpairs of a symmetric matrix A.
+// The values are in descending order, and the i-th column of V is the
+// eigenvector of the i-th value.
+//
+// It builds an orthonormal basis Q of the Krylov subspace of A by the
+// Lanczos iteration with full reorthogonalization, where QᵀAQ is
+// tridiagonal, and takes the Ritz pairs of the projection as the
+// approximation. If they do not converge, it restarts with a larger
+// subspace, up to the size of A. Each iteration takes O(n^2) time for
+// the product of A and a vector, thus it is much faster than EigenSym
+// if k is small.
+// Paper: Lanczos, Cornelius (1950). "An iteration method for the
+// solution of the eigenvalue problem of linear differential and
+// integral operators". Journal of Research of the National Bureau of
+// Standards 45 (4): 255–282
+func Lanczos(A *Dense, k int) (values []float64, V *Dense, err error) {
+	if err := A.checkSymmetric(); err != nil {
+		return nil, nil, err
+	}
+	n := A.n
+	if k < 1 || k > n {
+		return nil, nil, ErrEigenNum
+	}
+	anorm := A.frobenius()
+	for m := min(n, max(2*k, k+20)); ; m = min(n, 2*m) {
+		theta, y, res := lanczos(A, m)
+		converged := true
+		for i := 0; i < k; i++ {
+			if res[i] > eigenTol*anorm {
+				converged = false
+				break
+			}
+		}
+		if converged || m == n {
+			return theta[:k], columns(n, y[:k]), nil
+		}
+	}
+}
+
+// lanczos runs m steps of the Lanczos iteration on A and returns the
+// Ritz values in descending order, their Ritz vectors and residual
+// norms.
+func lanczos(A *Dense, m int) (theta []float64, y [][]float64, res []float64) {
+	n := A.n
+	rnd := rand.New(rand.NewSource(1))
+	q := make([][]float64, m)
+	alpha := make([]float64, m)
+	beta := make([]float64, m) // beta[j] is T[j][j+1]
+
+	tiny := eps * A.frobenius()
+	w := make([]float64, n)
+	for j := 0; j < m; j++ {
+		if j == 0 || beta[j-1] == 0 {
+			// start a new Krylov subspace orthogonal to the previous
+			// invariant one.
+			for i := range w {
+				w[i] = rnd.NormFloat64()
+			}
+			reorthogonalize(w, q[:j])
+			scale(w, 1/norm(w))
+		}
+		q[j] = append([]float64(nil), w...)
+		w = A.mulVec(q[j], w)
+		alpha[j] = dot(q[j], w)
+		reorthogonalize(w, q[:j+1])
+		if j+1 < m {
+			beta[j] = norm(w)
+			if beta[j] <= tiny {
+				beta[j] = 0
+			} else {
+				scale(w, 1/beta[j])
+			}
+		} else {
+			beta[j] = norm(w)
+		}
+	}
+
+	t := make([][]float64, m)
+	for i := range t {
+		t[i] = make([]float64, m)
+		t[i][i] = alpha[i]
+	}
+	for i := 0; i+1 < m; i++ {
+		t[i][i+1], t[i+1][i] = beta[i], beta[i]
+	}
+	theta, s, _ := jacobi(t)
+	theta, s, _ = sortEigen(theta, s, func(a, b float64) bool { return a > b })
+	// the residual of the i-th Ritz pair is |β(m-1)·s[m-1][i]|.
+	res = make([]float64, m)
+	for i := range res {
+		res[i] = math.Abs(beta[m-1] * s[m-1][i])
+	}
+	return theta, combine(q, s), res
+}
+
+// mulVec returns A·x, reusing the storage of dst.
+func (A *Dense) mulVec(x, dst []float64) []float64 {
+	if cap(dst) < A.m {
+		dst = make([]float64, A.m)
+	}
+	dst = dst[:A.m]
+	for i := range dst {
+		dst[i] = dot(A.data[i*A.n:(i+1)*A.n], x)
+	}
+	return dst
+}
+
+func dot(x, y []float64) float64 {
+	s := 0.0
+	for i := range x {
+		s += x[i] * y[i]
+	}
+	return s
+}
+
+func norm(x []float64) float64 {
+	return math.Sqrt(dot(x, x))
+}
+
+func scale(x []float64, f float64) {
+	for i := range x {
+		x[i] *= f
+	}
+}
+
+// reorthogonalize removes the components of w along the orthonormal
+// vectors q by two passes of the classical Gram-Schmidt process, which
+// is enough to keep them orthogonal to the working precision.
+func reorthogonalize(w []float64, q [][]float64) {
+	for pass := 0; pass < 2; pass++ {
+		for _, v := range q {
+			d := dot(v, w)
+			for i := range w {
+				w[i] -= d * v[i]
+			}
+		}
+	}
+}
+
+// orthonormalize orthonormalizes the vectors x in place, replacing the
+// linearly dependent ones by random vectors.
+func orthonormalize(x [][]float64, rnd *rand.Rand) {
+	for i := range x {
+		before := norm(x[i])
+		reorthogonalize(x[i], x[:i])
+		for nrm := norm(x[i]); nrm <= 1e-8*before || nrm == 0; nrm = norm(x[i]) {
+			for j := range x[i] {
+				x[i][j] = rnd.NormFloat64()
+			}
+			before = norm(x[i])
+			reorthogonalize(x[i], x[:i])
+		}
+		scale(x[i], 1/norm(x[i]))
+	}
+}
+
+// combine returns the vectors y[j] = Σ x[i]·s[i][j] for the first
+// len(s[0]) columns of s.
+func combine(x [][]float64, s [][]float64) [][]float64 {
+	y := make([][]float64, len(s[0]))
+	for j := range y {
+		y[j] = make([]float64, len(x[0]))
+		for i := range x {
+			if f := s[i][j]; f != 0 {
+				for l := range y[j] {
+					y[j][l] += f * x[i][l]
+				}
+			}
+		}
+	}
+	return y
+}
+
+// columns returns the m×len(x) matrix whose columns are the vectors x.
+func columns(m int, x [][]float64) *Dense {
+	V := Zero(m, len(x))
+	for j := range x {
+		for i, v := range x[j] {
+			V.Set(i, j, v)
+		}
+	}
+	return V
+}
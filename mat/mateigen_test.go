@@ -3,3 +3,317 @@
 // license that can be found in the LICENSE file.
 
 package mat
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+	"testing"
+
+	gonum "gonum.org/v1/gonum/mat"
+)
+
+// randSym returns a random n×n symmetric matrix.
+func randSym(r *rand.Rand, n int) *Dense {
+	A := Zero(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			v := r.NormFloat64()
+			A.Set(i, j, v)
+			A.Set(j, i, v)
+		}
+	}
+	return A
+}
+
+// symWithSpectrum returns Q·diag(values)·Qᵀ for a random orthogonal
+// matrix Q.
+func symWithSpectrum(r *rand.Rand, values []float64) *Dense {
+	n := len(values)
+	q := make([][]float64, n)
+	for i := range q {
+		q[i] = make([]float64, n)
+		for j := range q[i] {
+			q[i][j] = r.NormFloat64()
+		}
+	}
+	orthonormalize(q, r)
+	A := Zero(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			s := 0.0
+			for k := 0; k < n; k++ {
+				s += q[k][i] * values[k] * q[k][j]
+			}
+			A.Set(i, j, s)
+		}
+	}
+	return A
+}
+
+// gonumSym returns the eigenvalues of the symmetric matrix A in
+// ascending order computed by gonum.
+func gonumSym(t *testing.T, A *Dense) []float64 {
+	var e gonum.EigenSym
+	if !e.Factorize(gonum.NewSymDense(A.n, append([]float64(nil), A.data...)), false) {
+		t.Fatalf("gonum failed to factorize")
+	}
+	return e.Values(nil)
+}
+
+// checkEigenpairs checks that the columns of V are orthonormal and
+// that A·v = λ·v for each of them.
+func checkEigenpairs(t *testing.T, A *Dense, values []float64, V *Dense) {
+	t.Helper()
+	tol := 1e-8 * (1 + A.frobenius())
+	for j := range values {
+		for l := 0; l <= j; l++ {
+			d := 0.0
+			for i := 0; i < A.n; i++ {
+				d += V.At(i, j) * V.At(i, l)
+			}
+			if want := map[bool]float64{true: 1, false: 0}[j == l]; math.Abs(d-want) > 1e-8 {
+				t.Fatalf("eigenvectors %d and %d: dot product %v, want %v", j, l, d, want)
+			}
+		}
+		for i := 0; i < A.n; i++ {
+			av := 0.0
+			for k := 0; k < A.n; k++ {
+				av += A.At(i, k) * V.At(k, j)
+			}
+			if math.Abs(av-values[j]*V.At(i, j)) > tol {
+				t.Fatalf("eigenpair %d: (Av)[%d] = %v, want %v", j, i, av, values[j]*V.At(i, j))
+			}
+		}
+	}
+}
+
+func equalValues(a, b []float64, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > tol*(1+math.Abs(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEigenSym(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{1, 2, 3, 5, 10, 30} {
+		A := randSym(r, n)
+		values, V, err := EigenSym(A)
+		if err != nil {
+			t.Fatalf("EigenSym(%d×%d) error: %v", n, n, err)
+		}
+		if want := gonumSym(t, A); !equalValues(values, want, 1e-10) {
+			t.Fatalf("EigenSym(%d×%d) = %v, want %v", n, n, values, want)
+		}
+		checkEigenpairs(t, A, values, V)
+	}
+
+	// repeated eigenvalues
+	A := symWithSpectrum(r, []float64{2, 2, 2, -1, -1, 5})
+	values, V, err := EigenSym(A)
+	if err != nil {
+		t.Fatalf("EigenSym error: %v", err)
+	}
+	if want := []float64{-1, -1, 2, 2, 2, 5}; !equalValues(values, want, 1e-10) {
+		t.Fatalf("EigenSym = %v, want %v", values, want)
+	}
+	checkEigenpairs(t, A, values, V)
+
+	if _, _, err := EigenSym(Zero(2, 3)); err != ErrMatSize {
+		t.Fatalf("EigenSym(2×3) error = %v, want %v", err, ErrMatSize)
+	}
+	B, _ := NewDense(2, 2)(1, 2, 3, 4)
+	if _, _, err := EigenSym(B); err != ErrNotSymmetric {
+		t.Fatalf("EigenSym(nonsymmetric) error = %v, want %v", err, ErrNotSymmetric)
+	}
+}
+
+func sortComplex(v []complex128) {
+	sort.Slice(v, func(i, j int) bool {
+		if real(v[i]) != real(v[j]) {
+			return real(v[i]) < real(v[j])
+		}
+		return imag(v[i]) < imag(v[j])
+	})
+}
+
+// matchComplex reports whether a and b are equal as multisets up to
+// tol, which is robust to the order of nearly equal real parts.
+func matchComplex(a, b []complex128, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for j, y := range b {
+			if !used[j] && cmplx.Abs(x-y) <= tol*(1+cmplx.Abs(y)) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEigen(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{1, 2, 3, 4, 7, 10, 30, 50} {
+		A := Rand(n, n)
+		for i := range A.data {
+			A.data[i] = r.NormFloat64()
+		}
+		values, err := Eigen(A)
+		if err != nil {
+			t.Fatalf("Eigen(%d×%d) error: %v", n, n, err)
+		}
+
+		var e gonum.Eigen
+		if !e.Factorize(gonum.NewDense(n, n, append([]float64(nil), A.data...)), gonum.EigenNone) {
+			t.Fatalf("gonum failed to factorize")
+		}
+		want := e.Values(nil)
+		sortComplex(want)
+		if !matchComplex(values, want, 1e-8) {
+			t.Fatalf("Eigen(%d×%d) = %v, want %v", n, n, values, want)
+		}
+		for i := 1; i < len(values); i++ {
+			if real(values[i-1]) > real(values[i]) {
+				t.Fatalf("Eigen(%d×%d) = %v, not sorted", n, n, values)
+			}
+		}
+	}
+
+	// a rotation has the eigenvalues ±i.
+	R, _ := NewDense(2, 2)(0, -1, 1, 0)
+	values, err := Eigen(R)
+	if err != nil {
+		t.Fatalf("Eigen error: %v", err)
+	}
+	if want := []complex128{-1i, 1i}; !matchComplex(values, want, 1e-12) {
+		t.Fatalf("Eigen(rotation) = %v, want %v", values, want)
+	}
+
+	// a badly scaled matrix needs balancing.
+	S, _ := NewDense(3, 3)(
+		1, 1e10, 0,
+		1e-10, 1, 1e10,
+		0, 1e-10, 1,
+	)
+	var e gonum.Eigen
+	e.Factorize(gonum.NewDense(3, 3, append([]float64(nil), S.data...)), gonum.EigenNone)
+	values, err = Eigen(S)
+	if err != nil {
+		t.Fatalf("Eigen error: %v", err)
+	}
+	if want := e.Values(nil); !matchComplex(values, want, 1e-8) {
+		t.Fatalf("Eigen(scaled) = %v, want %v", values, want)
+	}
+
+	if _, err := Eigen(Zero(2, 3)); err != ErrMatSize {
+		t.Fatalf("Eigen(2×3) error = %v, want %v", err, ErrMatSize)
+	}
+}
+
+func TestPowerIteration(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	spectrum := make([]float64, 40)
+	for i := range spectrum {
+		spectrum[i] = float64(i + 1)
+	}
+	spectrum[0] = -50 // the largest in magnitude is negative.
+	A := symWithSpectrum(r, spectrum)
+
+	for _, k := range []int{1, 3, 5} {
+		values, V, err := PowerIteration(A, k)
+		if err != nil {
+			t.Fatalf("PowerIteration(k=%d) error: %v", k, err)
+		}
+		want := gonumSym(t, A)
+		sort.Slice(want, func(i, j int) bool { return math.Abs(want[i]) > math.Abs(want[j]) })
+		if !equalValues(values, want[:k], 1e-8) {
+			t.Fatalf("PowerIteration(k=%d) = %v, want %v", k, values, want[:k])
+		}
+		checkEigenpairs(t, A, values, V)
+	}
+
+	for _, k := range []int{0, 41} {
+		if _, _, err := PowerIteration(A, k); err != ErrEigenNum {
+			t.Fatalf("PowerIteration(k=%d) error = %v, want %v", k, err, ErrEigenNum)
+		}
+	}
+}
+
+func TestLanczos(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, tt := range []struct {
+		A *Dense
+		k int
+	}{
+		{randSym(r, 1), 1},
+		{randSym(r, 10), 10},
+		{randSym(r, 50), 1},
+		{randSym(r, 100), 5},
+		{randSym(r, 200), 8},
+		// clustered and repeated eigenvalues
+		{symWithSpectrum(r, []float64{5, 5, 5, 4.999, 1, 1, 0, -3, -3, 2}), 4},
+		// an invariant subspace stops the iteration early.
+		{Zero(6, 6), 3},
+	} {
+		values, V, err := Lanczos(tt.A, tt.k)
+		if err != nil {
+			t.Fatalf("Lanczos(%d×%d, k=%d) error: %v", tt.A.n, tt.A.n, tt.k, err)
+		}
+		want := gonumSym(t, tt.A)
+		sort.Sort(sort.Reverse(sort.Float64Slice(want)))
+		if !equalValues(values, want[:tt.k], 1e-8) {
+			t.Fatalf("Lanczos(%d×%d, k=%d) = %v, want %v", tt.A.n, tt.A.n, tt.k, values, want[:tt.k])
+		}
+		checkEigenpairs(t, tt.A, values, V)
+	}
+
+	B, _ := NewDense(2, 2)(1, 2, 3, 4)
+	if _, _, err := Lanczos(B, 1); err != ErrNotSymmetric {
+		t.Fatalf("Lanczos(nonsymmetric) error = %v, want %v", err, ErrNotSymmetric)
+	}
+}
+
+func BenchmarkEigen(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{10, 100} {
+		A := randSym(r, n)
+		b.Run(fmt.Sprintf("EigenSym/size-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				EigenSym(A)
+			}
+		})
+		b.Run(fmt.Sprintf("Eigen/size-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Eigen(A)
+			}
+		})
+		b.Run(fmt.Sprintf("Lanczos-top3/size-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Lanczos(A, 3)
+			}
+		})
+		b.Run(fmt.Sprintf("gonum/size-%d", n), func(b *testing.B) {
+			S := gonum.NewSymDense(n, append([]float64(nil), A.data...))
+			for i := 0; i < b.N; i++ {
+				var e gonum.EigenSym
+				e.Factorize(S, true)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import "math"
+
+// LU is the LU decomposition with partial pivoting of a square matrix
+// A, such that P·A = L·U, where P is a permutation, L is unit lower
+// triangular and U is upper triangular.
+type LU struct {
+	lu    *Dense // L below the diagonal and U on and above it
+	piv   []int  // row i of P·A is row piv[i] of A
+	sign  float64
+	anorm float64 // the 1-norm of A
+}
+
+// NewLU computes the LU decomposition of A.
+// Use block 36 version here, as DotBlock.
+func NewLU(A *Dense) (*LU, error) {
+	return NewLUBlock(36, A)
+}
+
+// NewLUBlock computes the LU decomposition of A by the right-looking
+// blocked algorithm, which factorizes a panel of blockSize columns at
+// a time, then updates the trailing matrix with a matrix
+// multiplication that is cache friendly. If blockSize < 1 or
+// blockSize >= n, it runs the unblocked algorithm.
+//
+// A singular A still has a decomposition, but it cannot Solve.
+func NewLUBlock(blockSize int, A *Dense) (*LU, error) {
+	if A.m != A.n {
+		return nil, ErrMatSize
+	}
+	n := A.n
+	if blockSize < 1 || blockSize > n {
+		blockSize = n
+	}
+	f := &LU{
+		lu:    Zero(n, n),
+		piv:   make([]int, n),
+		sign:  1,
+		anorm: A.norm1(),
+	}
+	copy(f.lu.data, A.data)
+	for i := range f.piv {
+		f.piv[i] = i
+	}
+	a := f.lu.data
+
+	for k0 := 0; k0 < n; k0 += blockSize {
+		k1 := min(k0+blockSize, n)
+
+		// factorize the panel a[k0:n, k0:k1].
+		for k := k0; k < k1; k++ {
+			p := k
+			for i := k + 1; i < n; i++ {
+				if math.Abs(a[i*n+k]) > math.Abs(a[p*n+k]) {
+					p = i
+				}
+			}
+			if p != k {
+				for j := 0; j < n; j++ {
+					a[k*n+j], a[p*n+j] = a[p*n+j], a[k*n+j]
+				}
+				f.piv[k], f.piv[p] = f.piv[p], f.piv[k]
+				f.sign = -f.sign
+			}
+			pivot := a[k*n+k]
+			if pivot == 0 {
+				continue
+			}
+			for i := k + 1; i < n; i++ {
+				a[i*n+k] /= pivot
+				l := a[i*n+k]
+				for j := k + 1; j < k1; j++ {
+					a[i*n+j] -= l * a[k*n+j]
+				}
+			}
+		}
+		if k1 == n {
+			break
+		}
+
+		// U12 = L11⁻¹·A12
+		for k := k0; k < k1; k++ {
+			uk := a[k*n+k1 : k*n+n]
+			for i := k + 1; i < k1; i++ {
+				axpy(-a[i*n+k], uk, a[i*n+k1:i*n+n])
+			}
+		}
+		// A22 -= L21·U12
+		for i := k1; i < n; i++ {
+			ai := a[i*n+k1 : i*n+n]
+			for k := k0; k < k1; k++ {
+				axpy(-a[i*n+k], a[k*n+k1:k*n+n], ai)
+			}
+		}
+	}
+	return f, nil
+}
+
+// axpy computes y += alpha·x.
+func axpy(alpha float64, x, y []float64) {
+	if alpha == 0 {
+		return
+	}
+	for i, v := range x {
+		y[i] += alpha * v
+	}
+}
+
+// norm1 returns the 1-norm of A, the maximum absolute column sum.
+func (A *Dense) norm1() float64 {
+	sums := make([]float64, A.n)
+	for i := 0; i < A.m; i++ {
+		for j, v := range A.data[i*A.n : (i+1)*A.n] {
+			sums[j] += math.Abs(v)
+		}
+	}
+	r := 0.0
+	for _, s := range sums {
+		r = math.Max(r, s)
+	}
+	return r
+}
+
+// L returns the unit lower triangular factor.
+func (f *LU) L() *Dense {
+	n := f.lu.n
+	L := Zero(n, n)
+	for i := 0; i < n; i++ {
+		copy(L.data[i*n:i*n+i], f.lu.data[i*n:i*n+i])
+		L.data[i*n+i] = 1
+	}
+	return L
+}
+
+// U returns the upper triangular factor.
+func (f *LU) U() *Dense {
+	n := f.lu.n
+	U := Zero(n, n)
+	for i := 0; i < n; i++ {
+		copy(U.data[i*n+i:(i+1)*n], f.lu.data[i*n+i:(i+1)*n])
+	}
+	return U
+}
+
+// Pivot returns the row permutation, where row i of P·A is row
+// Pivot()[i] of A.
+func (f *LU) Pivot() []int {
+	return append([]int(nil), f.piv...)
+}
+
+// Det returns the determinant of A.
+func (f *LU) Det() float64 {
+	det := f.sign
+	for i := 0; i < f.lu.n; i++ {
+		det *= f.lu.At(i, i)
+	}
+	return det
+}
+
+// singular reports whether U has a zero on the diagonal.
+func (f *LU) singular() bool {
+	for i := 0; i < f.lu.n; i++ {
+		if f.lu.At(i, i) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Solve returns X such that A·X = B. It returns ErrSingular if A is
+// singular.
+func (f *LU) Solve(B *Dense) (*Dense, error) {
+	n := f.lu.n
+	if B.m != n {
+		return nil, ErrMatSize
+	}
+	if f.singular() {
+		return nil, ErrSingular
+	}
+	X := Zero(n, B.n)
+	for i, p := range f.piv {
+		copy(X.data[i*B.n:(i+1)*B.n], B.data[p*B.n:(p+1)*B.n])
+	}
+	f.solveInPlace(X)
+	return X, nil
+}
+
+// solveInPlace overwrites X with U⁻¹·L⁻¹·X, where the rows of X are
+// already permuted.
+func (f *LU) solveInPlace(X *Dense) {
+	n, k, a := f.lu.n, X.n, f.lu.data
+	x := X.data
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			axpy(-a[i*n+j], x[j*k:(j+1)*k], x[i*k:(i+1)*k])
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		xi := x[i*k : (i+1)*k]
+		for j := i + 1; j < n; j++ {
+			axpy(-a[i*n+j], x[j*k:(j+1)*k], xi)
+		}
+		scale(xi, 1/a[i*n+i])
+	}
+}
+
+// solveTrans returns z such that Aᵀ·z = b for a vector b.
+func (f *LU) solveTrans(b []float64) []float64 {
+	n, a := f.lu.n, f.lu.data
+	v := append([]float64(nil), b...)
+	// Aᵀ = Uᵀ·Lᵀ·P, solve Uᵀ·w = b, then Lᵀ·v = w.
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			v[i] -= a[j*n+i] * v[j]
+		}
+		v[i] /= a[i*n+i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			v[i] -= a[j*n+i] * v[j]
+		}
+	}
+	z := make([]float64, n)
+	for i, p := range f.piv {
+		z[p] = v[i]
+	}
+	return z
+}
+
+// Inverse returns the inverse of A. It returns ErrSingular if A is
+// singular.
+func (f *LU) Inverse() (*Dense, error) {
+	return f.Solve(eye(f.lu.n))
+}
+
+// Cond returns an estimate of the condition number of A in the
+// 1-norm, ||A||·||A⁻¹||, which takes O(n^2) time instead of the O(n^3)
+// time to compute A⁻¹. The estimate is a lower bound that is rarely
+// off by more than a factor of 3. It returns +Inf if A is singular.
+// Paper: Hager, William W. (1984). "Condition Estimates". SIAM Journal
+// on Scientific and Statistical Computing 5 (2): 311–316
+func (f *LU) Cond() float64 {
+	if f.singular() {
+		return math.Inf(1)
+	}
+	n := f.lu.n
+	if n == 0 {
+		return 0
+	}
+	// maximize ||A⁻¹·x||₁ over ||x||₁ = 1 by a gradient ascent that
+	// starts from the uniform vector and moves to a vertex e(j).
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 1 / float64(n)
+	}
+	est := 0.0
+	for it := 0; it < 5; it++ {
+		X := Zero(n, 1)
+		for i, p := range f.piv {
+			X.data[i] = x[p]
+		}
+		f.solveInPlace(X)
+		y := X.data
+		s := 0.0
+		for _, v := range y {
+			s += math.Abs(v)
+		}
+		if s <= est {
+			break
+		}
+		est = s
+		xi := make([]float64, n)
+		for i, v := range y {
+			xi[i] = math.Copysign(1, v)
+		}
+		z := f.solveTrans(xi)
+		j, zx := 0, dot(z, x)
+		for i := range z {
+			if math.Abs(z[i]) > math.Abs(z[j]) {
+				j = i
+			}
+		}
+		if math.Abs(z[j]) <= zx {
+			break
+		}
+		clear(x)
+		x[j] = 1
+	}
+	return f.anorm * est
+}
+
+// eye returns the n×n identity matrix.
+func eye(n int) *Dense {
+	I := Zero(n, n)
+	for i := 0; i < n; i++ {
+		I.data[i*n+i] = 1
+	}
+	return I
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	gonum "gonum.org/v1/gonum/mat"
+)
+
+// randDense returns a random m×n matrix of standard normal elements.
+func randDense(r *rand.Rand, m, n int) *Dense {
+	A := Zero(m, n)
+	for i := range A.data {
+		A.data[i] = r.NormFloat64()
+	}
+	return A
+}
+
+func toGonum(A *Dense) *gonum.Dense {
+	return gonum.NewDense(A.m, A.n, append([]float64(nil), A.data...))
+}
+
+// approxEqual reports whether A and B have the same shape and their
+// elements differ by at most tol.
+func approxEqual(A, B *Dense, tol float64) bool {
+	if !A.EqualShape(B) {
+		return false
+	}
+	for i := range A.data {
+		if math.Abs(A.data[i]-B.data[i]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+func mustDot(t *testing.T, A, B *Dense) *Dense {
+	t.Helper()
+	C, err := Dot(A, B)
+	if err != nil {
+		t.Fatalf("Dot error: %v", err)
+	}
+	return C
+}
+
+func TestLU(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{1, 2, 5, 36, 37, 100} {
+		A := randDense(r, n, n)
+		for _, bs := range []int{0, 1, 4, 36} {
+			f, err := NewLUBlock(bs, A)
+			if err != nil {
+				t.Fatalf("NewLUBlock(%d, %d×%d) error: %v", bs, n, n, err)
+			}
+			// P·A = L·U
+			PA := Zero(n, n)
+			for i, p := range f.Pivot() {
+				copy(PA.data[i*n:(i+1)*n], A.data[p*n:(p+1)*n])
+			}
+			if LU := mustDot(t, f.L(), f.U()); !approxEqual(PA, LU, 1e-10*float64(n)) {
+				t.Fatalf("NewLUBlock(%d, %d×%d): P·A != L·U", bs, n, n)
+			}
+			if got, want := f.Det(), gonum.Det(toGonum(A)); math.Abs(got-want) > 1e-9*math.Abs(want) {
+				t.Fatalf("Det(%d×%d) = %v, want %v", n, n, got, want)
+			}
+		}
+
+		f, _ := NewLU(A)
+		B := randDense(r, n, 3)
+		X, err := f.Solve(B)
+		if err != nil {
+			t.Fatalf("Solve(%d×%d) error: %v", n, n, err)
+		}
+		var want gonum.Dense
+		if err := want.Solve(toGonum(A), toGonum(B)); err != nil {
+			t.Fatalf("gonum failed to solve: %v", err)
+		}
+		if !approxEqual(X, fromGonum(&want), 1e-8) {
+			t.Fatalf("Solve(%d×%d) differs from gonum", n, n)
+		}
+
+		inv, err := f.Inverse()
+		if err != nil {
+			t.Fatalf("Inverse(%d×%d) error: %v", n, n, err)
+		}
+		if I := mustDot(t, A, inv); !approxEqual(I, eye(n), 1e-8) {
+			t.Fatalf("A·Inverse(A) != I for %d×%d", n, n)
+		}
+	}
+
+	if _, err := NewLU(Zero(2, 3)); err != ErrMatSize {
+		t.Fatalf("NewLU(2×3) error = %v, want %v", err, ErrMatSize)
+	}
+	S, _ := NewDense(3, 3)(1, 2, 3, 2, 4, 6, 1, 0, 1)
+	f, err := NewLU(S)
+	if err != nil {
+		t.Fatalf("NewLU(singular) error: %v", err)
+	}
+	if f.Det() != 0 {
+		t.Fatalf("Det(singular) = %v, want 0", f.Det())
+	}
+	if _, err := f.Solve(eye(3)); err != ErrSingular {
+		t.Fatalf("Solve(singular) error = %v, want %v", err, ErrSingular)
+	}
+	if c := f.Cond(); !math.IsInf(c, 1) {
+		t.Fatalf("Cond(singular) = %v, want +Inf", c)
+	}
+}
+
+// fromGonum converts a gonum matrix for comparisons.
+func fromGonum(G *gonum.Dense) *Dense {
+	m, n := G.Dims()
+	A := Zero(m, n)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			A.Set(i, j, G.At(i, j))
+		}
+	}
+	return A
+}
+
+func TestLUCond(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{1, 3, 10, 50} {
+		A := randDense(r, n, n)
+		f, _ := NewLU(A)
+		inv, _ := f.Inverse()
+		exact := A.norm1() * inv.norm1()
+		if est := f.Cond(); est > exact*(1+1e-8) || est < exact/10 {
+			t.Fatalf("Cond(%d×%d) = %v, exact %v", n, n, est, exact)
+		}
+	}
+
+	// the Hilbert matrix is notoriously ill-conditioned.
+	n := 8
+	H := Zero(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			H.Set(i, j, 1/float64(i+j+1))
+		}
+	}
+	f, _ := NewLU(H)
+	if c := f.Cond(); c < 1e9 {
+		t.Fatalf("Cond(Hilbert) = %v, want > 1e9", c)
+	}
+}
+
+func BenchmarkLU(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{100, 500} {
+		A := randDense(r, n, n)
+		for _, bs := range []int{0, 36, 64} {
+			b.Run(fmt.Sprintf("block-%d/size-%d", bs, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					NewLUBlock(bs, A)
+				}
+			})
+		}
+		b.Run(fmt.Sprintf("gonum/size-%d", n), func(b *testing.B) {
+			G := toGonum(A)
+			for i := 0; i < b.N; i++ {
+				var lu gonum.LU
+				lu.Factorize(G)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import "math"
+
+// QR is the QR decomposition of an m×n matrix A with m >= n, such that
+// A = Q·R, where Q is m×n with orthonormal columns and R is n×n upper
+// triangular.
+type QR struct {
+	qr    *Dense    // the Householder vectors on and below the diagonal, R above it
+	rdiag []float64 // the diagonal of R
+}
+
+// NewQR computes the QR decomposition of A by Householder reflections.
+// Each reflection is applied to the trailing matrix row by row, which
+// accesses the row-major storage contiguously. It returns ErrMatSize
+// if A has more columns than rows.
+func NewQR(A *Dense) (*QR, error) {
+	m, n := A.m, A.n
+	if m < n {
+		return nil, ErrMatSize
+	}
+	f := &QR{qr: Zero(m, n), rdiag: make([]float64, n)}
+	copy(f.qr.data, A.data)
+	a := f.qr.data
+	s := make([]float64, n)
+	for k := 0; k < n; k++ {
+		nrm := 0.0
+		for i := k; i < m; i++ {
+			nrm = math.Hypot(nrm, a[i*n+k])
+		}
+		if nrm != 0 {
+			// the reflection I - v·vᵀ/v[k] maps the column k to
+			// -nrm·e(k), where v is normalized by nrm.
+			if a[k*n+k] < 0 {
+				nrm = -nrm
+			}
+			for i := k; i < m; i++ {
+				a[i*n+k] /= nrm
+			}
+			a[k*n+k]++
+
+			// s = vᵀ·A[k:, k+1:]
+			s := s[k+1:]
+			clear(s)
+			for i := k; i < m; i++ {
+				axpy(a[i*n+k], a[i*n+k+1:(i+1)*n], s)
+			}
+			scale(s, -1/a[k*n+k])
+			for i := k; i < m; i++ {
+				axpy(a[i*n+k], s, a[i*n+k+1:(i+1)*n])
+			}
+		}
+		f.rdiag[k] = -nrm
+	}
+	return f, nil
+}
+
+// FullRank reports whether R, and hence A, has full column rank, i.e.
+// no diagonal element of R is negligible relative to the largest one.
+func (f *QR) FullRank() bool {
+	r := 0.0
+	for _, d := range f.rdiag {
+		r = math.Max(r, math.Abs(d))
+	}
+	tol := float64(f.qr.m) * eps * r
+	for _, d := range f.rdiag {
+		if math.Abs(d) <= tol {
+			return false
+		}
+	}
+	return true
+}
+
+// Q returns the m×n factor with orthonormal columns.
+func (f *QR) Q() *Dense {
+	m, n := f.qr.m, f.qr.n
+	Q := Zero(m, n)
+	for i := 0; i < n; i++ {
+		Q.data[i*n+i] = 1
+	}
+	f.applyQ(Q)
+	return Q
+}
+
+// R returns the n×n upper triangular factor.
+func (f *QR) R() *Dense {
+	n := f.qr.n
+	R := Zero(n, n)
+	for i := 0; i < n; i++ {
+		R.data[i*n+i] = f.rdiag[i]
+		copy(R.data[i*n+i+1:(i+1)*n], f.qr.data[i*n+i+1:(i+1)*n])
+	}
+	return R
+}
+
+// applyQ overwrites the m×k matrix X with Q·X by applying the
+// reflections in reverse order.
+func (f *QR) applyQ(X *Dense) {
+	f.reflect(X, f.qr.n-1, -1, -1)
+}
+
+// applyQT overwrites the m×k matrix X with Qᵀ·X.
+func (f *QR) applyQT(X *Dense) {
+	f.reflect(X, 0, f.qr.n, 1)
+}
+
+// reflect applies the reflections from..to (exclusive) with step to
+// the m×k matrix X.
+func (f *QR) reflect(X *Dense, from, to, step int) {
+	m, n, k := f.qr.m, f.qr.n, X.n
+	a, x := f.qr.data, X.data
+	s := make([]float64, k)
+	for j := from; j != to; j += step {
+		if f.rdiag[j] == 0 {
+			continue
+		}
+		clear(s)
+		for i := j; i < m; i++ {
+			axpy(a[i*n+j], x[i*k:(i+1)*k], s)
+		}
+		scale(s, -1/a[j*n+j])
+		for i := j; i < m; i++ {
+			axpy(a[i*n+j], s, x[i*k:(i+1)*k])
+		}
+	}
+}
+
+// Solve returns the least squares solution X that minimizes
+// ||A·X - B|| in the Frobenius norm, which is the exact solution if
+// A is square. It returns ErrSingular if A is rank deficient.
+func (f *QR) Solve(B *Dense) (*Dense, error) {
+	m, n, k := f.qr.m, f.qr.n, B.n
+	if B.m != m {
+		return nil, ErrMatSize
+	}
+	if !f.FullRank() {
+		return nil, ErrSingular
+	}
+	Y := Zero(m, k)
+	copy(Y.data, B.data)
+	f.applyQT(Y)
+
+	// R·X = (Qᵀ·B)[:n]
+	X := Zero(n, k)
+	copy(X.data, Y.data[:n*k])
+	a, x := f.qr.data, X.data
+	for i := n - 1; i >= 0; i-- {
+		xi := x[i*k : (i+1)*k]
+		for j := i + 1; j < n; j++ {
+			axpy(-a[i*n+j], x[j*k:(j+1)*k], xi)
+		}
+		scale(xi, 1/f.rdiag[i])
+	}
+	return X, nil
+}
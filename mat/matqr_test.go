@@ -0,0 +1,91 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	gonum "gonum.org/v1/gonum/mat"
+)
+
+func TestQR(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, size := range [][2]int{{1, 1}, {3, 3}, {5, 2}, {40, 10}, {100, 100}} {
+		m, n := size[0], size[1]
+		A := randDense(r, m, n)
+		f, err := NewQR(A)
+		if err != nil {
+			t.Fatalf("NewQR(%d×%d) error: %v", m, n, err)
+		}
+		Q, R := f.Q(), f.R()
+		if QR := mustDot(t, Q, R); !approxEqual(A, QR, 1e-10*float64(m)) {
+			t.Fatalf("NewQR(%d×%d): A != Q·R", m, n)
+		}
+		QTQ := Zero(n, n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				s := 0.0
+				for k := 0; k < m; k++ {
+					s += Q.At(k, i) * Q.At(k, j)
+				}
+				QTQ.Set(i, j, s)
+			}
+			for j := 0; j < i; j++ {
+				if R.At(i, j) != 0 {
+					t.Fatalf("R is not upper triangular")
+				}
+			}
+		}
+		if !approxEqual(QTQ, eye(n), 1e-10*float64(m)) {
+			t.Fatalf("NewQR(%d×%d): columns of Q are not orthonormal", m, n)
+		}
+
+		B := randDense(r, m, 2)
+		X, err := f.Solve(B)
+		if err != nil {
+			t.Fatalf("Solve(%d×%d) error: %v", m, n, err)
+		}
+		var want gonum.Dense
+		if err := want.Solve(toGonum(A), toGonum(B)); err != nil {
+			t.Fatalf("gonum failed to solve: %v", err)
+		}
+		if !approxEqual(X, fromGonum(&want), 1e-8) {
+			t.Fatalf("Solve(%d×%d) differs from gonum", m, n)
+		}
+	}
+
+	if _, err := NewQR(Zero(2, 3)); err != ErrMatSize {
+		t.Fatalf("NewQR(2×3) error = %v, want %v", err, ErrMatSize)
+	}
+	deficient, _ := NewDense(3, 2)(1, 2, 2, 4, 3, 6)
+	f, _ := NewQR(deficient)
+	if f.FullRank() {
+		t.Fatalf("FullRank() = true for a rank deficient matrix")
+	}
+	if _, err := f.Solve(Zero(3, 1)); err != ErrSingular {
+		t.Fatalf("Solve(rank deficient) error = %v, want %v", err, ErrSingular)
+	}
+}
+
+func BenchmarkQR(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{100, 300} {
+		A := randDense(r, 2*n, n)
+		b.Run(fmt.Sprintf("size-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				NewQR(A)
+			}
+		})
+		b.Run(fmt.Sprintf("gonum/size-%d", n), func(b *testing.B) {
+			G := toGonum(A)
+			for i := 0; i < b.N; i++ {
+				var qr gonum.QR
+				qr.Factorize(G)
+			}
+		})
+	}
+}
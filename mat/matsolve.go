@@ -0,0 +1,55 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+// Solve returns X such that A·X = B for a square matrix A by the LU
+// decomposition. It returns ErrSingular if A is singular.
+func Solve(A, B *Dense) (*Dense, error) {
+	f, err := NewLU(A)
+	if err != nil {
+		return nil, err
+	}
+	return f.Solve(B)
+}
+
+// LeastSquares returns X that minimizes ||A·X - B|| for an m×n matrix
+// A with m >= n and full column rank by the QR decomposition. Use
+// SVD.Solve for rank deficient or underdetermined systems.
+func LeastSquares(A, B *Dense) (*Dense, error) {
+	f, err := NewQR(A)
+	if err != nil {
+		return nil, err
+	}
+	return f.Solve(B)
+}
+
+// Inverse returns the inverse of a square matrix A. It returns
+// ErrSingular if A is singular.
+func Inverse(A *Dense) (*Dense, error) {
+	f, err := NewLU(A)
+	if err != nil {
+		return nil, err
+	}
+	return f.Inverse()
+}
+
+// Det returns the determinant of a square matrix A.
+func Det(A *Dense) (float64, error) {
+	f, err := NewLU(A)
+	if err != nil {
+		return 0, err
+	}
+	return f.Det(), nil
+}
+
+// Cond returns the condition number of A in the 2-norm by the SVD.
+// Use LU.Cond for a cheaper estimate of a square matrix.
+func Cond(A *Dense) (float64, error) {
+	f, err := NewSVD(A)
+	if err != nil {
+		return 0, err
+	}
+	return f.Cond(), nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	gonum "gonum.org/v1/gonum/mat"
+)
+
+func TestSolve(t *testing.T) {
+	A, _ := NewDense(3, 3)(
+		2, 1, -1,
+		-3, -1, 2,
+		-2, 1, 2,
+	)
+	b, _ := NewDense(3, 1)(8, -11, -3)
+	x, err := Solve(A, b)
+	if err != nil {
+		t.Fatalf("Solve error: %v", err)
+	}
+	if want, _ := NewDense(3, 1)(2, 3, -1); !approxEqual(x, want, 1e-12) {
+		t.Fatalf("Solve = %v, want %v", x.data, want.data)
+	}
+	if det, _ := Det(A); math.Abs(det-(-1)) > 1e-12 {
+		t.Fatalf("Det = %v, want -1", det)
+	}
+	inv, err := Inverse(A)
+	if err != nil {
+		t.Fatalf("Inverse error: %v", err)
+	}
+	want, _ := NewDense(3, 3)(
+		4, 3, -1,
+		-2, -2, 1,
+		5, 4, -1,
+	)
+	if !approxEqual(inv, want, 1e-12) {
+		t.Fatalf("Inverse = %v, want %v", inv.data, want.data)
+	}
+
+	// fit y = 1 + 2x to the exact points by least squares.
+	X, _ := NewDense(4, 2)(1, 0, 1, 1, 1, 2, 1, 3)
+	y, _ := NewDense(4, 1)(1, 3, 5, 7)
+	beta, err := LeastSquares(X, y)
+	if err != nil {
+		t.Fatalf("LeastSquares error: %v", err)
+	}
+	if want, _ := NewDense(2, 1)(1, 2); !approxEqual(beta, want, 1e-12) {
+		t.Fatalf("LeastSquares = %v, want %v", beta.data, want.data)
+	}
+
+	for _, f := range []func() error{
+		func() error { _, err := Solve(Zero(2, 3), Zero(2, 1)); return err },
+		func() error { _, err := Solve(Zero(2, 2), Zero(3, 1)); return err },
+		func() error { _, err := Inverse(Zero(2, 3)); return err },
+		func() error { _, err := Det(Zero(2, 3)); return err },
+		func() error { _, err := LeastSquares(Zero(2, 3), Zero(2, 1)); return err },
+	} {
+		if err := f(); err != ErrMatSize {
+			t.Fatalf("error = %v, want %v", err, ErrMatSize)
+		}
+	}
+	if _, err := Inverse(Zero(2, 2)); err != ErrSingular {
+		t.Fatalf("Inverse(zero) error = %v, want %v", err, ErrSingular)
+	}
+}
+
+func TestCond(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, size := range [][2]int{{5, 5}, {20, 5}, {5, 20}} {
+		A := randDense(r, size[0], size[1])
+		c, err := Cond(A)
+		if err != nil {
+			t.Fatalf("Cond error: %v", err)
+		}
+		if want := gonum.Cond(toGonum(A), 2); math.Abs(c-want) > 1e-8*want {
+			t.Fatalf("Cond(%d×%d) = %v, want %v", size[0], size[1], c, want)
+		}
+	}
+	if c, _ := Cond(eye(4)); math.Abs(c-1) > 1e-15 {
+		t.Fatalf("Cond(I) = %v, want 1", c)
+	}
+	if c, _ := Cond(Zero(3, 3)); !math.IsInf(c, 1) {
+		t.Fatalf("Cond(zero) = %v, want +Inf", c)
+	}
+}
+
+func TestSolveEmpty(t *testing.T) {
+	lu, err := NewLU(Zero(0, 0))
+	if err != nil {
+		t.Fatalf("NewLU(0×0) error: %v", err)
+	}
+	qr, err := NewQR(Zero(0, 0))
+	if err != nil {
+		t.Fatalf("NewQR(0×0) error: %v", err)
+	}
+	chol, err := NewCholesky(Zero(0, 0))
+	if err != nil {
+		t.Fatalf("NewCholesky(0×0) error: %v", err)
+	}
+	svd, err := NewSVD(Zero(0, 0))
+	if err != nil {
+		t.Fatalf("NewSVD(0×0) error: %v", err)
+	}
+	if lu.Det() != 1 || chol.Det() != 1 {
+		t.Fatalf("Det of 0×0 = %v and %v, want 1", lu.Det(), chol.Det())
+	}
+	if lu.Cond() != 0 || svd.Cond() != 0 {
+		t.Fatalf("Cond of 0×0 = %v and %v, want 0", lu.Cond(), svd.Cond())
+	}
+	for _, f := range []interface {
+		Solve(*Dense) (*Dense, error)
+	}{lu, qr, chol, svd} {
+		X, err := f.Solve(Zero(0, 2))
+		if err != nil {
+			t.Fatalf("%T.Solve error: %v", f, err)
+		}
+		if m, n := X.Size(); m != 0 || n != 2 {
+			t.Fatalf("%T.Solve size = %d×%d, want 0×2", f, m, n)
+		}
+	}
+
+	for _, size := range [][2]int{{0, 3}, {3, 0}} {
+		m, n := size[0], size[1]
+		f, err := NewSVD(Zero(m, n))
+		if err != nil {
+			t.Fatalf("NewSVD(%d×%d) error: %v", m, n, err)
+		}
+		if len(f.Values()) != 0 || f.Rank() != 0 {
+			t.Fatalf("NewSVD(%d×%d) values = %v, want none", m, n, f.Values())
+		}
+		if r, c := f.U().Size(); r != m || c != 0 {
+			t.Fatalf("NewSVD(%d×%d) U is %d×%d, want %d×0", m, n, r, c, m)
+		}
+		if r, c := f.V().Size(); r != n || c != 0 {
+			t.Fatalf("NewSVD(%d×%d) V is %d×%d, want %d×0", m, n, r, c, n)
+		}
+		X, err := f.Solve(Zero(m, 1))
+		if err != nil {
+			t.Fatalf("NewSVD(%d×%d) Solve error: %v", m, n, err)
+		}
+		if r, c := X.Size(); r != n || c != 1 {
+			t.Fatalf("NewSVD(%d×%d) Solve size = %d×%d, want %d×1", m, n, r, c, n)
+		}
+	}
+	if _, err := NewQR(Zero(3, 0)); err != nil {
+		t.Fatalf("NewQR(3×0) error: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SVD is the thin singular value decomposition of an m×n matrix A,
+// such that A = U·diag(s)·Vᵀ, where k = min(m, n), U is m×k and V is
+// n×k with orthonormal columns, and s are the k singular values in
+// descending order.
+type SVD struct {
+	m, n int
+	u, v [][]float64 // the columns of U and V
+	s    []float64
+}
+
+// NewSVD computes the singular value decomposition of A by the
+// one-sided Jacobi method, which rotates pairs of columns of A until
+// they are orthogonal, where the singular values are their norms. Like
+// EigenSym, it is slower than bidiagonal QR for large matrices, but
+// computes small singular values to high relative accuracy.
+// Paper: Hestenes, Magnus R. (1958). "Inversion of Matrices by
+// Biorthogonalization and Related Results". Journal of the Society for
+// Industrial and Applied Mathematics 6 (1): 51–90
+func NewSVD(A *Dense) (*SVD, error) {
+	m, n := A.m, A.n
+	transposed := m < n
+	if transposed {
+		m, n = n, m
+	}
+	// w are the n columns of the m×n matrix, which is Aᵀ if A is wide.
+	w := make([][]float64, n)
+	for j := range w {
+		w[j] = make([]float64, m)
+		for i := range w[j] {
+			if transposed {
+				w[j][i] = A.At(j, i)
+			} else {
+				w[j][i] = A.At(i, j)
+			}
+		}
+	}
+	v := make([][]float64, n)
+	for j := range v {
+		v[j] = make([]float64, n)
+		v[j][j] = 1
+	}
+
+	converged := false
+	for sweep := 0; sweep < maxJacobiSweeps && !converged; sweep++ {
+		converged = true
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				alpha, beta, gamma := dot(w[p], w[p]), dot(w[q], w[q]), dot(w[p], w[q])
+				if gamma == 0 || math.Abs(gamma) <= eps*math.Sqrt(alpha*beta) {
+					continue
+				}
+				converged = false
+				// The rotation that makes the columns p and q
+				// orthogonal, as the one of jacobi on AᵀA.
+				zeta := (beta - alpha) / (2 * gamma)
+				t := 1 / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				if math.IsInf(zeta*zeta, 0) {
+					t = 0.5 / math.Abs(zeta)
+				}
+				if zeta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+				rotate(w[p], w[q], c, s)
+				rotate(v[p], v[q], c, s)
+			}
+		}
+	}
+	if !converged {
+		return nil, ErrNoConvergence
+	}
+
+	s := make([]float64, n)
+	for j := range w {
+		s[j] = norm(w[j])
+	}
+	s, perm := sortDesc(s)
+	u := make([][]float64, n)
+	vs := make([][]float64, n)
+	for j, p := range perm {
+		u[j], vs[j] = w[p], v[p]
+		if s[j] != 0 {
+			scale(u[j], 1/s[j])
+		}
+	}
+	// complete the left singular vectors of zero singular values to
+	// an orthonormal set.
+	for j := range u {
+		if s[j] == 0 {
+			clear(u[j])
+		}
+	}
+	orthonormalize(u, rand.New(rand.NewSource(1)))
+
+	if transposed {
+		u, vs = vs, u
+	}
+	return &SVD{m: A.m, n: A.n, u: u, v: vs, s: s}, nil
+}
+
+// rotate applies the plane rotation of c and s to x and y.
+func rotate(x, y []float64, c, s float64) {
+	for i := range x {
+		x[i], y[i] = c*x[i]-s*y[i], s*x[i]+c*y[i]
+	}
+}
+
+// sortDesc sorts s in descending order and returns the permutation,
+// where the i-th sorted value is s[perm[i]].
+func sortDesc(s []float64) ([]float64, []int) {
+	perm := make([]int, len(s))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(i, j int) bool { return s[perm[i]] > s[perm[j]] })
+	sorted := make([]float64, len(s))
+	for i, p := range perm {
+		sorted[i] = s[p]
+	}
+	return sorted, perm
+}
+
+// Values returns the singular values in descending order.
+func (f *SVD) Values() []float64 {
+	return append([]float64(nil), f.s...)
+}
+
+// U returns the m×k matrix of the left singular vectors.
+func (f *SVD) U() *Dense {
+	return columns(f.m, f.u)
+}
+
+// V returns the n×k matrix of the right singular vectors.
+func (f *SVD) V() *Dense {
+	return columns(f.n, f.v)
+}
+
+// tol returns the threshold under which a singular value is treated
+// as zero.
+func (f *SVD) tol() float64 {
+	return float64(max(f.m, f.n)) * eps * f.s[0]
+}
+
+// Rank returns the numerical rank of A, the number of singular values
+// that are not negligible relative to the largest one.
+func (f *SVD) Rank() int {
+	r := 0
+	for _, s := range f.s {
+		if s > f.tol() {
+			r++
+		}
+	}
+	return r
+}
+
+// Cond returns the condition number of A in the 2-norm, the ratio of
+// the largest to the smallest singular value. It returns +Inf if the
+// smallest singular value is zero, and 0 if A is empty, as LU.Cond.
+func (f *SVD) Cond() float64 {
+	if len(f.s) == 0 {
+		return 0
+	}
+	if f.s[len(f.s)-1] == 0 {
+		return math.Inf(1)
+	}
+	return f.s[0] / f.s[len(f.s)-1]
+}
+
+// Solve returns the minimum norm least squares solution X = A⁺·B,
+// where A⁺ is the pseudo-inverse of A. Unlike QR, it also solves rank
+// deficient and underdetermined systems.
+func (f *SVD) Solve(B *Dense) (*Dense, error) {
+	if B.m != f.m {
+		return nil, ErrMatSize
+	}
+	n, k := f.n, B.n
+	X := Zero(n, k)
+	c := make([]float64, k)
+	for j, s := range f.s {
+		if s <= f.tol() {
+			break
+		}
+		// X += v(j)·(u(j)ᵀ·B)/s(j)
+		clear(c)
+		for i, u := range f.u[j] {
+			axpy(u, B.data[i*k:(i+1)*k], c)
+		}
+		scale(c, 1/s)
+		for i, v := range f.v[j] {
+			axpy(v, c, X.data[i*k:(i+1)*k])
+		}
+	}
+	return X, nil
+}
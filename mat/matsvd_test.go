@@ -0,0 +1,143 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package mat
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	gonum "gonum.org/v1/gonum/mat"
+)
+
+// checkOrthonormal checks that the columns of Q are orthonormal.
+func checkOrthonormal(t *testing.T, Q *Dense) {
+	t.Helper()
+	m, n := Q.Size()
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			s := 0.0
+			for k := 0; k < m; k++ {
+				s += Q.At(k, i) * Q.At(k, j)
+			}
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			if math.Abs(s-want) > 1e-9 {
+				t.Fatalf("columns %d and %d: dot product %v, want %v", i, j, s, want)
+			}
+		}
+	}
+}
+
+func TestSVD(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	rank2 := mustDot(t, randDense(r, 6, 2), randDense(r, 2, 5))
+	for _, A := range []*Dense{
+		randDense(r, 1, 1),
+		randDense(r, 4, 4),
+		randDense(r, 10, 3),
+		randDense(r, 3, 10),
+		randDense(r, 50, 30),
+		rank2,
+		Zero(3, 2),
+	} {
+		m, n := A.Size()
+		f, err := NewSVD(A)
+		if err != nil {
+			t.Fatalf("NewSVD(%d×%d) error: %v", m, n, err)
+		}
+
+		var g gonum.SVD
+		if !g.Factorize(toGonum(A), gonum.SVDNone) {
+			t.Fatalf("gonum failed to factorize")
+		}
+		if !equalValues(f.Values(), g.Values(nil), 1e-10) {
+			t.Fatalf("NewSVD(%d×%d) values = %v, want %v", m, n, f.Values(), g.Values(nil))
+		}
+
+		U, V := f.U(), f.V()
+		checkOrthonormal(t, U)
+		checkOrthonormal(t, V)
+		k := min(m, n)
+		US := Zero(m, k)
+		for i := 0; i < m; i++ {
+			for j := 0; j < k; j++ {
+				US.Set(i, j, U.At(i, j)*f.Values()[j])
+			}
+		}
+		VT := Zero(k, n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < k; j++ {
+				VT.Set(j, i, V.At(i, j))
+			}
+		}
+		if USVT := mustDot(t, US, VT); !approxEqual(A, USVT, 1e-10*float64(m+n)) {
+			t.Fatalf("NewSVD(%d×%d): A != U·diag(s)·Vᵀ", m, n)
+		}
+	}
+
+	f, _ := NewSVD(rank2)
+	if f.Rank() != 2 {
+		t.Fatalf("Rank() = %d, want 2", f.Rank())
+	}
+}
+
+func TestSVDSolve(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	// a full rank overdetermined system agrees with QR.
+	A, B := randDense(r, 20, 5), randDense(r, 20, 2)
+	f, _ := NewSVD(A)
+	X, err := f.Solve(B)
+	if err != nil {
+		t.Fatalf("Solve error: %v", err)
+	}
+	want, _ := LeastSquares(A, B)
+	if !approxEqual(X, want, 1e-9) {
+		t.Fatalf("Solve differs from LeastSquares")
+	}
+
+	// an underdetermined system has the minimum norm solution, which
+	// is the one in the row space of A.
+	A, B = randDense(r, 3, 6), randDense(r, 3, 1)
+	f, _ = NewSVD(A)
+	X, _ = f.Solve(B)
+	if AX := mustDot(t, A, X); !approxEqual(AX, B, 1e-9) {
+		t.Fatalf("Solve(underdetermined): A·X != B")
+	}
+	var g gonum.Dense
+	if err := g.Solve(toGonum(A), toGonum(B)); err != nil {
+		t.Fatalf("gonum failed to solve: %v", err)
+	}
+	if !approxEqual(X, fromGonum(&g), 1e-9) {
+		t.Fatalf("Solve(underdetermined) is not the minimum norm solution")
+	}
+
+	if _, err := f.Solve(Zero(4, 1)); err != ErrMatSize {
+		t.Fatalf("Solve(4×1) error = %v, want %v", err, ErrMatSize)
+	}
+}
+
+func BenchmarkSVD(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{10, 100} {
+		A := randDense(r, n, n)
+		b.Run(fmt.Sprintf("size-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				NewSVD(A)
+			}
+		})
+		b.Run(fmt.Sprintf("gonum/size-%d", n), func(b *testing.B) {
+			G := toGonum(A)
+			for i := 0; i < b.N; i++ {
+				var svd gonum.SVD
+				svd.Factorize(G, gonum.SVDThin)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@
 package sched
 
 import (
-	"container/heap"
 	"context"
 	"fmt"
 	"runtime"
@@ -13,6 +12,8 @@ import (
 	"sync/atomic"
 	"time"
 	"unsafe"
+
+	"changkun.de/x/pkg/ds"
 )
 
 // Task interface for sched
@@ -246,20 +247,25 @@ func (s *sched) execute(t *task) {
 	t.future.put(result)
 }
 
-// TaskQueue implements a timer queue based on a heap
+// TaskQueue implements a timer queue based on an indexed priority queue
 // Its supports bi-direction accessing, such as access value by key
 // or access key by its value
 //
 // TODO: lock-free
 type taskQueue struct {
-	heap   *taskHeap
+	// queue is indexed by the tasks themselves rather than their ids,
+	// so that a task can be queued while another one of the same id is
+	// running and rescheduled.
+	queue  *ds.IndexedPriorityQueue[*task, *task]
 	lookup map[string]*task
 	mu     sync.Mutex
 }
 
 func newTaskQueue() *taskQueue {
 	return &taskQueue{
-		heap:   &taskHeap{},
+		queue: ds.NewIndexedPriorityQueue[*task](func(a, b *task) bool {
+			return a.priority.Before(b.priority)
+		}),
 		lookup: map[string]*task{},
 	}
 }
@@ -267,7 +273,7 @@ func newTaskQueue() *taskQueue {
 // length of queue
 func (m *taskQueue) length() (l int) {
 	m.mu.Lock()
-	l = m.heap.Len()
+	l = m.queue.Len()
 	m.mu.Unlock()
 	return
 }
@@ -275,7 +281,7 @@ func (m *taskQueue) length() (l int) {
 // push item
 func (m *taskQueue) push(t *task) *future {
 	m.mu.Lock()
-	heap.Push(m.heap, t)          // O(log(n))
+	m.queue.Push(t, t)            // O(log(n))
 	m.lookup[t.value.GetID()] = t // O(1)
 	m.mu.Unlock()
 	return t.future
@@ -284,13 +290,11 @@ func (m *taskQueue) push(t *task) *future {
 // Pop item
 func (m *taskQueue) pop() *task {
 	m.mu.Lock()
-
-	if m.heap.Len() == 0 {
+	_, item, ok := m.queue.Pop() // O(log(n))
+	if !ok {
 		m.mu.Unlock()
 		return nil
 	}
-
-	item := heap.Pop(m.heap).(*task)     // O(log(n))
 	delete(m.lookup, item.value.GetID()) // O(1) amortized
 	m.mu.Unlock()
 	return item
@@ -299,14 +303,12 @@ func (m *taskQueue) pop() *task {
 // peek the top priority item without deletion
 func (m *taskQueue) peek() (t Task) {
 	m.mu.Lock()
-
-	if m.heap.Len() == 0 {
-		m.mu.Unlock()
+	_, item, ok := m.queue.Peek()
+	m.mu.Unlock()
+	if !ok {
 		return nil
 	}
-	t = (*m.heap)[0].value
-	m.mu.Unlock()
-	return
+	return item.value
 }
 
 // update of a given task
@@ -320,18 +322,14 @@ func (m *taskQueue) update(t Task, when time.Time) (*future, bool) {
 
 	item.priority = when
 	item.value = t
-	heap.Fix(m.heap, item.index) // O(log(n))
+	m.queue.Update(item, item) // O(log(n))
 	m.mu.Unlock()
 	return item.future, true
 }
 
 // a task is something we manage in a priority queue.
 type task struct {
-	value Task // for storage
-
-	// The index is needed by update and is maintained by the
-	// heap.Interface methods.
-	index    int       // The index of the item in the heap.
+	value    Task      // for storage
 	priority time.Time // type of time for priority
 	future   *future
 }
@@ -357,33 +355,3 @@ func (f *future) Get() (v interface{}) {
 func (f *future) put(v interface{}) {
 	f.value.Store(v)
 }
-
-type taskHeap []*task
-
-func (pq taskHeap) Len() int {
-	return len(pq)
-}
-
-func (pq taskHeap) Less(i, j int) bool {
-	return pq[i].priority.Before(pq[j].priority)
-}
-
-func (pq taskHeap) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
-}
-
-func (pq *taskHeap) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	*pq = old[0 : n-1]
-	return item
-}
-
-func (pq *taskHeap) Push(x interface{}) {
-	item := x.(*task)
-	item.index = len(*pq)
-	*pq = append(*pq, item)
-}
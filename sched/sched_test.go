@@ -243,6 +243,38 @@ func TestSchedError(t *testing.T) {
 	Resume()
 }
 
+func TestSchedRescheduleSameID(t *testing.T) {
+	s := &sched{
+		timer: unsafe.Pointer(time.NewTimer(0)),
+		tasks: newTaskQueue(),
+	}
+	defer s.pause()
+
+	later := time.Now().UTC().Add(time.Hour)
+	first := s.schedule(tests.NewTask("task", later), later)
+
+	// the first task is running, and the same id is scheduled again.
+	running := s.tasks.pop()
+	second := s.schedule(tests.NewTask("task", later), later)
+	if first == second {
+		t.Fatalf("the second schedule must not reuse the future of a running task")
+	}
+
+	// a retry of the running task must not overwrite the newer one.
+	s.reschedule(running, later.Add(time.Hour))
+	if l := s.tasks.length(); l != 2 {
+		t.Fatalf("queue length want 2, got: %v", l)
+	}
+	queued := s.tasks.pop()
+	if queued.future != second || !queued.priority.Equal(later) {
+		t.Fatalf("the newer task is overwritten")
+	}
+	queued = s.tasks.pop()
+	if queued.future != first || !queued.priority.Equal(later.Add(time.Hour)) {
+		t.Fatalf("the rescheduled task is lost")
+	}
+}
+
 func TestSchedStop2(t *testing.T) {
 	if testLeak {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,59 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package slice
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parallelBatch is the maximum number of elements a worker of
+// ParallelMap claims at a time, which amortizes the contention on the
+// shared index while keeping the load balanced for uneven costs of f.
+const parallelBatch = 64
+
+// ParallelMap is like Map, but it applies f concurrently on at most
+// workers goroutines. If workers <= 0, it uses runtime.GOMAXPROCS(0)
+// workers. The results keep the order of s regardless of the order
+// that f is called.
+func ParallelMap[T, U any](s []T, f func(T) U, workers int) []U {
+	if s == nil {
+		return nil
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(s))
+	if workers <= 1 {
+		return Map(s, f)
+	}
+	// small inputs are split into smaller batches, so that a few
+	// expensive calls of f still run on all workers.
+	batch := max(1, min(parallelBatch, len(s)/(4*workers)))
+
+	r := make([]U, len(s))
+	var (
+		next atomic.Int64
+		wg   sync.WaitGroup
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(int64(batch))) - batch
+				if i >= len(s) {
+					return
+				}
+				for j := i; j < min(i+batch, len(s)); j++ {
+					r[j] = f(s[j])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return r
+}
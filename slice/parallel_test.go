@@ -0,0 +1,98 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package slice_test
+
+import (
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"changkun.de/x/pkg/slice"
+)
+
+func TestParallelMap(t *testing.T) {
+	square := func(v int) int { return v * v }
+	for _, n := range []int{0, 1, 63, 64, 1000, 4097} {
+		s := make([]int, n)
+		for i := range s {
+			s[i] = i
+		}
+		want := slice.Map(s, square)
+		for _, workers := range []int{-1, 0, 1, 3, 100} {
+			if got := slice.ParallelMap(s, square, workers); !slices.Equal(got, want) {
+				t.Fatalf("ParallelMap(n=%d, workers=%d) returns wrong results", n, workers)
+			}
+		}
+	}
+	if got := slice.ParallelMap([]int(nil), square, 4); got != nil {
+		t.Fatalf("ParallelMap(nil) = %v, want nil", got)
+	}
+}
+
+func TestParallelMapWorkers(t *testing.T) {
+	const workers = 3
+	var running, peak atomic.Int32
+	s := make([]int, 10000)
+	slice.ParallelMap(s, func(v int) int {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		running.Add(-1)
+		return v
+	}, workers)
+	if p := peak.Load(); p > workers {
+		t.Fatalf("%d concurrent calls, want at most %d", p, workers)
+	}
+}
+
+func TestParallelMapSmallInput(t *testing.T) {
+	// every call waits until all workers are running, which never
+	// happens if the few calls run one after another.
+	const workers = 4
+	var running atomic.Int32
+	s := make([]int, 2*workers)
+	got := slice.ParallelMap(s, func(v int) bool {
+		running.Add(1)
+		for deadline := time.Now().Add(5 * time.Second); running.Load() < workers; {
+			if time.Now().After(deadline) {
+				return false
+			}
+			runtime.Gosched()
+		}
+		return true
+	}, workers)
+	if slices.Contains(got, false) {
+		t.Fatalf("%d calls of a small input do not run on %d workers", len(s), workers)
+	}
+}
+
+func BenchmarkParallelMap(b *testing.B) {
+	s := make([]int, 1<<16)
+	for i := range s {
+		s[i] = i
+	}
+	f := func(v int) int {
+		for i := 0; i < 100; i++ {
+			v = v*31 + i
+		}
+		return v
+	}
+	b.Run("Map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			slice.Map(s, f)
+		}
+	})
+	b.Run("ParallelMap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			slice.ParallelMap(s, f, 0)
+		}
+	})
+}
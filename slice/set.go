@@ -0,0 +1,112 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package slice
+
+// smallSet is the length under which a linear scan is used for
+// membership tests instead of a map, which avoids hashing and the
+// allocation of the map for small slices.
+const smallSet = 16
+
+// set is a membership test over the elements of a slice.
+type set[T comparable] struct {
+	s []T
+	m map[T]struct{}
+}
+
+func newSet[T comparable](s []T) set[T] {
+	if len(s) <= smallSet {
+		return set[T]{s: s}
+	}
+	m := make(map[T]struct{}, len(s))
+	for _, v := range s {
+		m[v] = struct{}{}
+	}
+	return set[T]{m: m}
+}
+
+func (s set[T]) has(v T) bool {
+	if s.m == nil {
+		return Contains(s.s, v)
+	}
+	_, ok := s.m[v]
+	return ok
+}
+
+// Unique returns a new slice of the elements of s without duplicates,
+// where each element keeps the position of its first instance.
+func Unique[T comparable](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return UniqueInPlace(append([]T(nil), s...))
+}
+
+// UniqueInPlace is like Unique, but it writes the result to the front
+// of s and returns that prefix of s. It only allocates if s is longer
+// than a small threshold.
+func UniqueInPlace[T comparable](s []T) []T {
+	n := 0
+	if len(s) <= smallSet {
+		for _, v := range s {
+			if !Contains(s[:n], v) {
+				s[n] = v
+				n++
+			}
+		}
+	} else {
+		seen := make(map[T]struct{}, len(s))
+		for _, v := range s {
+			if _, ok := seen[v]; !ok {
+				seen[v] = struct{}{}
+				s[n] = v
+				n++
+			}
+		}
+	}
+	clear(s[n:])
+	return s[:n]
+}
+
+// UniqueSorted removes consecutive duplicates of s in place and
+// returns the prefix of s without them. If s is sorted, the result has
+// no duplicates. It never allocates.
+func UniqueSorted[T comparable](s []T) []T {
+	if len(s) < 2 {
+		return s
+	}
+	n := 1
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[n-1] {
+			s[n] = s[i]
+			n++
+		}
+	}
+	clear(s[n:])
+	return s[:n]
+}
+
+// Union returns the distinct elements that are in a or b, in the
+// order of their first instance in a followed by b.
+func Union[T comparable](a, b []T) []T {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	r := make([]T, 0, len(a)+len(b))
+	return UniqueInPlace(append(append(r, a...), b...))
+}
+
+// Intersect returns the distinct elements of a that are also in b, in
+// their order in a.
+func Intersect[T comparable](a, b []T) []T {
+	in := newSet(b)
+	return UniqueInPlace(Filter(a, in.has))
+}
+
+// Diff returns the distinct elements of a that are not in b, in their
+// order in a.
+func Diff[T comparable](a, b []T) []T {
+	in := newSet(b)
+	return UniqueInPlace(Filter(a, func(v T) bool { return !in.has(v) }))
+}
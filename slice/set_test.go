@@ -0,0 +1,123 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package slice_test
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+
+	"changkun.de/x/pkg/slice"
+)
+
+// naiveUnique returns the distinct elements of s in the order of their
+// first instance.
+func naiveUnique(s []int) []int {
+	var r []int
+	for _, v := range s {
+		if !slices.Contains(r, v) {
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+func randInts(r *rand.Rand, n, max int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = r.Intn(max)
+	}
+	return s
+}
+
+func TestUnique(t *testing.T) {
+	if got := slice.Unique([]int{3, 1, 3, 2, 1}); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Fatalf("Unique = %v, want [3 1 2]", got)
+	}
+	if got := slice.Unique([]int(nil)); got != nil {
+		t.Fatalf("Unique(nil) = %v, want nil", got)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s := randInts(r, r.Intn(64), 1+r.Intn(32))
+		in := slices.Clone(s)
+		want := naiveUnique(s)
+		if got := slice.Unique(s); !slices.Equal(got, want) {
+			t.Fatalf("Unique(%v) = %v, want %v", s, got, want)
+		}
+		if !slices.Equal(s, in) {
+			t.Fatalf("Unique modified its input")
+		}
+		if got := slice.UniqueInPlace(s); !slices.Equal(got, want) {
+			t.Fatalf("UniqueInPlace(%v) = %v, want %v", in, got, want)
+		}
+
+		slices.Sort(in)
+		want = naiveUnique(in)
+		if got := slice.UniqueSorted(in); !slices.Equal(got, want) {
+			t.Fatalf("UniqueSorted = %v, want %v", got, want)
+		}
+	}
+
+	small := []int{1, 2, 1, 3}
+	if n := testing.AllocsPerRun(100, func() { slice.UniqueInPlace(small) }); n != 0 {
+		t.Fatalf("UniqueInPlace allocates %v times, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { slice.UniqueSorted(small) }); n != 0 {
+		t.Fatalf("UniqueSorted allocates %v times, want 0", n)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a, b := []int{1, 2, 2, 3, 4}, []int{4, 3, 5, 5}
+	if got := slice.Union(a, b); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("Union = %v", got)
+	}
+	if got := slice.Intersect(a, b); !slices.Equal(got, []int{3, 4}) {
+		t.Fatalf("Intersect = %v", got)
+	}
+	if got := slice.Diff(a, b); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("Diff = %v", got)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a, b := randInts(r, r.Intn(64), 48), randInts(r, r.Intn(64), 48)
+		var inter, diff []int
+		for _, v := range naiveUnique(a) {
+			if slices.Contains(b, v) {
+				inter = append(inter, v)
+			} else {
+				diff = append(diff, v)
+			}
+		}
+		if got, want := slice.Union(a, b), naiveUnique(append(slices.Clone(a), b...)); !slices.Equal(got, want) {
+			t.Fatalf("Union(%v, %v) = %v, want %v", a, b, got, want)
+		}
+		if got := slice.Intersect(a, b); !slices.Equal(got, inter) {
+			t.Fatalf("Intersect(%v, %v) = %v, want %v", a, b, got, inter)
+		}
+		if got := slice.Diff(a, b); !slices.Equal(got, diff) {
+			t.Fatalf("Diff(%v, %v) = %v, want %v", a, b, got, diff)
+		}
+	}
+}
+
+func BenchmarkUnique(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{8, 1024} {
+		s := randInts(r, n, n/2)
+		buf := make([]int, n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				copy(buf, s)
+				slice.UniqueInPlace(buf)
+			}
+		})
+	}
+}
@@ -2,14 +2,163 @@
 // Use of this source code is governed by a MIT
 // license that can be found in the LICENSE file.
 
+// Package slice implements generic utilities for slices.
+//
+// Functions that return a new slice never modify their input. The
+// InPlace variants reuse the backing array of the input instead, and
+// do not allocate.
 package slice
 
 // IsStringsContains check if string slice contains specfied target string.
+//
+// Deprecated: Use Contains instead.
 func IsStringsContains(strings []string, target string) bool {
-	for _, v := range strings {
-		if v == target {
-			return true
+	return Contains(strings, target)
+}
+
+// Contains reports whether v is in s.
+func Contains[T comparable](s []T, v T) bool {
+	return Index(s, v) >= 0
+}
+
+// ContainsEq reports whether s contains an element that equals v
+// according to eq. For a predicate, use slices.ContainsFunc.
+func ContainsEq[T any](s []T, v T, eq func(a, b T) bool) bool {
+	return IndexEq(s, v, eq) >= 0
+}
+
+// Index returns the index of the first instance of v in s, or -1 if
+// v is not in s.
+func Index[T comparable](s []T, v T) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// IndexEq returns the index of the first element of s that equals v
+// according to eq, or -1 if there is no such element. For a predicate,
+// use slices.IndexFunc.
+func IndexEq[T any](s []T, v T, eq func(a, b T) bool) int {
+	for i := range s {
+		if eq(s[i], v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Filter returns a new slice of the elements of s for which keep
+// returns true, in their original order.
+func Filter[T any](s []T, keep func(T) bool) []T {
+	var r []T
+	for _, v := range s {
+		if keep(v) {
+			r = append(r, v)
 		}
 	}
-	return false
+	return r
+}
+
+// FilterInPlace is like Filter, but it writes the kept elements to the
+// front of s and returns that prefix of s. The elements after it are
+// zeroed, thus they can be garbage collected.
+func FilterInPlace[T any](s []T, keep func(T) bool) []T {
+	n := 0
+	for _, v := range s {
+		if keep(v) {
+			s[n] = v
+			n++
+		}
+	}
+	clear(s[n:])
+	return s[:n]
+}
+
+// Map returns a new slice of the results of f applied to each element
+// of s.
+func Map[T, U any](s []T, f func(T) U) []U {
+	if s == nil {
+		return nil
+	}
+	r := make([]U, len(s))
+	for i, v := range s {
+		r[i] = f(v)
+	}
+	return r
+}
+
+// Reduce folds the elements of s from left to right into an
+// accumulator, starting from init.
+func Reduce[T, A any](s []T, init A, f func(acc A, v T) A) A {
+	acc := init
+	for _, v := range s {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Chunk splits s into consecutive chunks of size elements, where the
+// last chunk may be shorter. The chunks share the backing array of s,
+// but their capacity is limited to their length, thus appending to a
+// chunk does not overwrite the next one. It panics if size < 1.
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		panic("slice: chunk size must be positive")
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	r := make([][]T, 0, (len(s)+size-1)/size)
+	for i := 0; i < len(s); i += size {
+		j := min(i+size, len(s))
+		r = append(r, s[i:j:j])
+	}
+	return r
+}
+
+// Partition returns the elements of s for which pred returns true and
+// the others, both in their original order.
+func Partition[T any](s []T, pred func(T) bool) (in, out []T) {
+	for _, v := range s {
+		if pred(v) {
+			in = append(in, v)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return in, out
+}
+
+// Pair is a pair of values of possibly different types.
+type Pair[T, U any] struct {
+	First  T
+	Second U
+}
+
+// Zip returns the pairs of elements at the same index of a and b. The
+// result has the length of the shorter slice.
+func Zip[T, U any](a []T, b []U) []Pair[T, U] {
+	n := min(len(a), len(b))
+	if n == 0 {
+		return nil
+	}
+	r := make([]Pair[T, U], n)
+	for i := range r {
+		r[i] = Pair[T, U]{a[i], b[i]}
+	}
+	return r
+}
+
+// GroupBy groups the elements of s by the key returned by key. The
+// elements of each group keep their original order.
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	r := make(map[K][]T)
+	for _, v := range s {
+		k := key(v)
+		r[k] = append(r[k], v)
+	}
+	return r
 }
@@ -5,6 +5,10 @@
 package slice_test
 
 import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	"changkun.de/x/pkg/slice"
@@ -16,3 +20,118 @@ func TestIsStringsContains(t *testing.T) {
 		t.Fatalf("want true, got false")
 	}
 }
+
+func TestContainsIndex(t *testing.T) {
+	s := []int{3, 1, 4, 1, 5}
+	if !slice.Contains(s, 4) || slice.Contains(s, 2) {
+		t.Fatalf("Contains reports wrong results")
+	}
+	if i := slice.Index(s, 1); i != 1 {
+		t.Fatalf("Index(1) = %d, want 1", i)
+	}
+	if i := slice.Index([]int(nil), 1); i != -1 {
+		t.Fatalf("Index(nil, 1) = %d, want -1", i)
+	}
+
+	fold := []string{"Go", "Rust", "C"}
+	eq := strings.EqualFold
+	if i := slice.IndexEq(fold, "rust", eq); i != 1 {
+		t.Fatalf("IndexEq(rust) = %d, want 1", i)
+	}
+	if !slice.ContainsEq(fold, "c", eq) || slice.ContainsEq(fold, "zig", eq) {
+		t.Fatalf("ContainsEq reports wrong results")
+	}
+	if n := testing.AllocsPerRun(100, func() { slice.Contains(fold, "C") }); n != 0 {
+		t.Fatalf("Contains allocates %v times, want 0", n)
+	}
+}
+
+func TestFilterMapReduce(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6}
+	even := func(v int) bool { return v%2 == 0 }
+
+	if got := slice.Filter(s, even); !slices.Equal(got, []int{2, 4, 6}) {
+		t.Fatalf("Filter = %v, want [2 4 6]", got)
+	}
+	if !slices.Equal(s, []int{1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("Filter modified its input: %v", s)
+	}
+	if got := slice.Filter(s, func(int) bool { return false }); got != nil {
+		t.Fatalf("Filter = %v, want nil", got)
+	}
+
+	in := slices.Clone(s)
+	got := slice.FilterInPlace(in, even)
+	if !slices.Equal(got, []int{2, 4, 6}) || !slices.Equal(in[3:], []int{0, 0, 0}) {
+		t.Fatalf("FilterInPlace = %v with tail %v", got, in[3:])
+	}
+	if n := testing.AllocsPerRun(100, func() { slice.FilterInPlace(in, even) }); n != 0 {
+		t.Fatalf("FilterInPlace allocates %v times, want 0", n)
+	}
+
+	strs := slice.Map(s, strconv.Itoa)
+	if !slices.Equal(strs, []string{"1", "2", "3", "4", "5", "6"}) {
+		t.Fatalf("Map = %v", strs)
+	}
+	if got := slice.Map([]int(nil), strconv.Itoa); got != nil {
+		t.Fatalf("Map(nil) = %v, want nil", got)
+	}
+
+	sum := slice.Reduce(s, 0, func(acc, v int) int { return acc + v })
+	if sum != 21 {
+		t.Fatalf("Reduce = %d, want 21", sum)
+	}
+	joined := slice.Reduce(strs, "", func(acc, v string) string { return acc + v })
+	if joined != "123456" {
+		t.Fatalf("Reduce = %q, want 123456", joined)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6, 7}
+	got := slice.Chunk(s, 3)
+	want := [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Chunk = %v, want %v", got, want)
+	}
+	_ = append(got[0], 100)
+	if s[3] != 4 {
+		t.Fatalf("appending to a chunk overwrote the next one")
+	}
+	if got := slice.Chunk([]int{}, 3); got != nil {
+		t.Fatalf("Chunk(empty) = %v, want nil", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Chunk(0) does not panic")
+		}
+	}()
+	slice.Chunk(s, 0)
+}
+
+func TestPartition(t *testing.T) {
+	in, out := slice.Partition([]int{5, 2, 8, 1, 9, 4}, func(v int) bool { return v > 4 })
+	if !slices.Equal(in, []int{5, 8, 9}) || !slices.Equal(out, []int{2, 1, 4}) {
+		t.Fatalf("Partition = %v, %v", in, out)
+	}
+}
+
+func TestZip(t *testing.T) {
+	got := slice.Zip([]int{1, 2, 3}, []string{"a", "b"})
+	want := []slice.Pair[int, string]{{1, "a"}, {2, "b"}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Zip = %v, want %v", got, want)
+	}
+	if got := slice.Zip([]int{1}, []string(nil)); got != nil {
+		t.Fatalf("Zip = %v, want nil", got)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	got := slice.GroupBy([]string{"go", "c", "rust", "js", "d"}, func(s string) int { return len(s) })
+	want := map[int][]string{1: {"c", "d"}, 2: {"go", "js"}, 4: {"rust"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GroupBy = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str
+
+import "sort"
+
+// AhoCorasick is an automaton that finds all instances of a set of
+// patterns in a text in one pass. It takes O(n+z) time for a text of
+// length n with z matches, regardless of the number of patterns.
+// Paper: Aho, Alfred V. and Corasick, Margaret J. (1975). "Efficient
+// string matching: an aid to bibliographic search". Communications of
+// the ACM 18 (6): 333–340
+type AhoCorasick struct {
+	patterns []string
+	nodes    []acNode // nodes[0] is the root
+}
+
+// acNode is a node of the trie of patterns.
+type acNode struct {
+	edges []acEdge // sorted by byte
+	fail  int32    // the node of the longest proper suffix in the trie
+	out   int32    // the pattern that ends at the node, or -1
+	dict  int32    // the nearest node on the fail chain with out, or -1
+}
+
+type acEdge struct {
+	c  byte
+	to int32
+}
+
+// Match is an instance of a pattern in a text, where
+// text[Start:End] equals the Pattern-th pattern.
+type Match struct {
+	Pattern    int
+	Start, End int
+}
+
+// NewAhoCorasick builds the automaton of patterns in O(m) time for a
+// total pattern length of m. Empty patterns never match, and
+// duplicated patterns are reported by the first index.
+func NewAhoCorasick(patterns []string) *AhoCorasick {
+	a := &AhoCorasick{
+		patterns: append([]string(nil), patterns...),
+		nodes:    []acNode{{out: -1, dict: -1}},
+	}
+	for i, p := range patterns {
+		if p == "" {
+			continue
+		}
+		n := int32(0)
+		for j := 0; j < len(p); j++ {
+			next := a.child(n, p[j])
+			if next < 0 {
+				next = int32(len(a.nodes))
+				a.nodes = append(a.nodes, acNode{out: -1, dict: -1})
+				edges := a.nodes[n].edges
+				k := sort.Search(len(edges), func(k int) bool { return edges[k].c >= p[j] })
+				edges = append(edges, acEdge{})
+				copy(edges[k+1:], edges[k:])
+				edges[k] = acEdge{p[j], next}
+				a.nodes[n].edges = edges
+			}
+			n = next
+		}
+		if a.nodes[n].out < 0 {
+			a.nodes[n].out = int32(i)
+		}
+	}
+
+	// compute fail and dictionary links in breadth-first order, so
+	// that the links of shallower nodes are ready.
+	queue := []int32{0}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range a.nodes[n].edges {
+			f := int32(0)
+			if n != 0 {
+				f = a.next(a.nodes[n].fail, e.c)
+			}
+			c := &a.nodes[e.to]
+			c.fail = f
+			if a.nodes[f].out >= 0 {
+				c.dict = f
+			} else {
+				c.dict = a.nodes[f].dict
+			}
+			queue = append(queue, e.to)
+		}
+	}
+	return a
+}
+
+// child returns the child of n by c in the trie, or -1.
+func (a *AhoCorasick) child(n int32, c byte) int32 {
+	edges := a.nodes[n].edges
+	if len(edges) <= 8 {
+		for _, e := range edges {
+			if e.c == c {
+				return e.to
+			}
+		}
+		return -1
+	}
+	k := sort.Search(len(edges), func(k int) bool { return edges[k].c >= c })
+	if k < len(edges) && edges[k].c == c {
+		return edges[k].to
+	}
+	return -1
+}
+
+// next returns the state after reading c from state n.
+func (a *AhoCorasick) next(n int32, c byte) int32 {
+	for {
+		if next := a.child(n, c); next >= 0 {
+			return next
+		}
+		if n == 0 {
+			return 0
+		}
+		n = a.nodes[n].fail
+	}
+}
+
+// Each calls op for every match in s in the order of their ends, and
+// matches of the same end from the longest. The iteration stops if op
+// returns false.
+func (a *AhoCorasick) Each(s string, op func(m Match) bool) {
+	n := int32(0)
+	for i := 0; i < len(s); i++ {
+		n = a.next(n, s[i])
+		d := n
+		if a.nodes[d].out < 0 {
+			d = a.nodes[d].dict
+		}
+		for ; d >= 0; d = a.nodes[d].dict {
+			p := int(a.nodes[d].out)
+			if !op(Match{p, i + 1 - len(a.patterns[p]), i + 1}) {
+				return
+			}
+		}
+	}
+}
+
+// FindAll returns all matches in s, including overlapping ones.
+func (a *AhoCorasick) FindAll(s string) []Match {
+	var ms []Match
+	a.Each(s, func(m Match) bool {
+		ms = append(ms, m)
+		return true
+	})
+	return ms
+}
+
+// Contains reports whether any pattern is in s.
+func (a *AhoCorasick) Contains(s string) bool {
+	found := false
+	a.Each(s, func(Match) bool {
+		found = true
+		return false
+	})
+	return found
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"changkun.de/x/pkg/str"
+)
+
+func compareMatch(a, b str.Match) int {
+	if a.End != b.End {
+		return a.End - b.End
+	}
+	if a.Start != b.Start {
+		return a.Start - b.Start
+	}
+	return a.Pattern - b.Pattern
+}
+
+// findAll returns all instances of patterns in s by searching each
+// pattern separately.
+func findAll(s string, patterns []string) []str.Match {
+	var all []str.Match
+	seen := map[string]bool{}
+	for p, pattern := range patterns {
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		for _, i := range indexAll(s, pattern) {
+			all = append(all, str.Match{Pattern: p, Start: i, End: i + len(pattern)})
+		}
+	}
+	slices.SortFunc(all, compareMatch)
+	return all
+}
+
+func TestAhoCorasick(t *testing.T) {
+	a := str.NewAhoCorasick([]string{"he", "she", "his", "hers", "", "he"})
+	want := []str.Match{
+		{Pattern: 1, Start: 1, End: 4},
+		{Pattern: 0, Start: 2, End: 4},
+		{Pattern: 3, Start: 2, End: 6},
+	}
+	got := a.FindAll("ushers")
+	slices.SortFunc(got, compareMatch)
+	if !slices.Equal(got, want) {
+		t.Fatalf("FindAll(ushers) = %v, want %v", got, want)
+	}
+	if !a.Contains("this") || a.Contains("xyz") {
+		t.Fatalf("Contains reports wrong results")
+	}
+
+	n := 0
+	a.Each("hehehe", func(m str.Match) bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Fatalf("Each did not stop: %d matches reported", n)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		patterns := make([]string, r.Intn(8))
+		for j := range patterns {
+			patterns[j] = randString(r, "abc", r.Intn(5))
+		}
+		s := randString(r, "abc", r.Intn(64))
+		got := str.NewAhoCorasick(patterns).FindAll(s)
+		slices.SortFunc(got, compareMatch)
+		if want := findAll(s, patterns); !slices.Equal(got, want) {
+			t.Fatalf("FindAll(%q, %q) = %v, want %v", s, patterns, got, want)
+		}
+	}
+}
+
+func BenchmarkAhoCorasick(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	s := randString(r, "abcdefghijklmnopqrstuvwxyz ", 1<<16)
+	patterns := make([]string, 1000)
+	for i := range patterns {
+		patterns[i] = randString(r, "abcdefghijklmnopqrstuvwxyz", 3+r.Intn(6))
+	}
+	a := str.NewAhoCorasick(patterns)
+	b.SetBytes(int64(len(s)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.Each(s, func(str.Match) bool { return true })
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str
+
+// BoyerMoore is a preprocessed pattern for Boyer-Moore search, which
+// compares the pattern from its end, and skips ahead by the bad
+// character and the good suffix rules. It is sublinear on average for
+// long patterns, and O(n+m) in the worst case with the Galil rule.
+// Paper: Boyer, R. S. and Moore, J. S. (1977). "A fast string
+// searching algorithm". Communications of the ACM 20 (10): 762–772
+type BoyerMoore struct {
+	pattern string
+	// bad[c] is the distance from the last occurrence of byte c in
+	// pattern[:m-1] to the end of the pattern, or m.
+	bad [256]int
+	// good[i] is the shift when pattern[i+1:] matched and
+	// pattern[i] mismatched.
+	good []int
+	// period is the shift after a full match, which is m minus the
+	// longest proper border.
+	period int
+}
+
+// NewBoyerMoore preprocesses the pattern in O(m) time.
+func NewBoyerMoore(pattern string) *BoyerMoore {
+	m := len(pattern)
+	p := &BoyerMoore{pattern: pattern, good: make([]int, m), period: 1}
+	for i := range p.bad {
+		p.bad[i] = m
+	}
+	for i := 0; i < m-1; i++ {
+		p.bad[pattern[i]] = m - 1 - i
+	}
+	if m == 0 {
+		return p
+	}
+
+	// suffix[i] is the length of the longest common suffix of
+	// pattern[:i+1] and the pattern.
+	// it reuses the previous match [g+1, f] like the Z-algorithm.
+	suffix := make([]int, m)
+	suffix[m-1] = m
+	g, f := m-1, 0
+	for i := m - 2; i >= 0; i-- {
+		if i > g && suffix[i+m-1-f] < i-g {
+			suffix[i] = suffix[i+m-1-f]
+			continue
+		}
+		g = min(g, i)
+		f = i
+		for g >= 0 && pattern[g] == pattern[g+m-1-f] {
+			g--
+		}
+		suffix[i] = f - g
+	}
+	// case 2: a prefix of the pattern matches a suffix of the
+	// matched part.
+	for i := range p.good {
+		p.good[i] = m
+	}
+	j := 0
+	for i := m - 1; i >= 0; i-- {
+		if suffix[i] == i+1 {
+			for ; j < m-1-i; j++ {
+				if p.good[j] == m {
+					p.good[j] = m - 1 - i
+				}
+			}
+		}
+	}
+	// case 1: the matched part occurs elsewhere in the pattern.
+	for i := 0; i < m-1; i++ {
+		p.good[m-1-suffix[i]] = m - 1 - i
+	}
+	p.period = p.good[0]
+	return p
+}
+
+// Index returns the index of the first instance of the pattern in s,
+// or -1 if the pattern is not present in s.
+func (p *BoyerMoore) Index(s string) int {
+	i := -1
+	p.each(s, func(j int) bool {
+		i = j
+		return false
+	})
+	return i
+}
+
+// IndexAll returns the indices of all instances of the pattern in s,
+// including overlapping ones.
+func (p *BoyerMoore) IndexAll(s string) []int {
+	var is []int
+	p.each(s, func(j int) bool {
+		is = append(is, j)
+		return true
+	})
+	return is
+}
+
+func (p *BoyerMoore) each(s string, op func(i int) bool) {
+	m := len(p.pattern)
+	if m == 0 {
+		for i := 0; i <= len(s); i++ {
+			if !op(i) {
+				return
+			}
+		}
+		return
+	}
+	// skip is the length of the prefix of the window that is known
+	// to match after a full match, by the Galil rule.
+	for i, skip := 0, 0; i <= len(s)-m; {
+		j := m - 1
+		for j >= skip && s[i+j] == p.pattern[j] {
+			j--
+		}
+		if j < skip {
+			if !op(i) {
+				return
+			}
+			i += p.period
+			skip = m - p.period
+			continue
+		}
+		skip = 0
+		i += max(p.good[j], p.bad[s[i+j]]-(m-1-j))
+	}
+}
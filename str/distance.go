@@ -0,0 +1,203 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str
+
+// trimCommon returns the runes of a and b without their common prefix
+// and suffix, which do not change edit distances.
+func trimCommon(a, b string) ([]rune, []rune) {
+	ra, rb := []rune(a), []rune(b)
+	for len(ra) > 0 && len(rb) > 0 && ra[0] == rb[0] {
+		ra, rb = ra[1:], rb[1:]
+	}
+	for len(ra) > 0 && len(rb) > 0 && ra[len(ra)-1] == rb[len(rb)-1] {
+		ra, rb = ra[:len(ra)-1], rb[:len(rb)-1]
+	}
+	return ra, rb
+}
+
+// Levenshtein returns the Levenshtein distance of a and b, i.e. the
+// minimum number of rune insertions, deletions and substitutions that
+// turn a into b. It takes O(mn) time and O(min(m, n)) memory.
+func Levenshtein(a, b string) int {
+	ra, rb := trimCommon(a, b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diag := row[0] // the cell of (i-1, j-1)
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			diag, row[j] = row[j], min(row[j]+1, row[j-1]+1, diag+cost)
+		}
+	}
+	return row[len(rb)]
+}
+
+// LevenshteinBounded returns the Levenshtein distance of a and b, and
+// whether it is at most k. If the distance exceeds k, it returns k+1
+// and false. It only computes the diagonal band of width 2k+1 and
+// stops early once every cell in a row exceeds k, which takes O(kn)
+// time, thus it is much faster than Levenshtein for finding close
+// matches.
+// Paper: Ukkonen, Esko (1985). "Algorithms for approximate string
+// matching". Information and Control 64 (1–3): 100–118
+func LevenshteinBounded(a, b string, k int) (int, bool) {
+	if k < 0 {
+		return 0, false
+	}
+	ra, rb := trimCommon(a, b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra)-len(rb) > k {
+		return k + 1, false
+	}
+	inf := k + 1
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = min(j, inf)
+	}
+	for i := 1; i <= len(ra); i++ {
+		lo, hi := max(1, i-k), min(len(rb), i+k)
+		diag := row[lo-1]
+		if lo == 1 {
+			row[0] = min(i, inf)
+		} else {
+			row[lo-1] = inf // outside the band
+		}
+		rowMin := row[lo-1]
+		for j := lo; j <= hi; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			up := row[j]
+			if j == i+k { // the cell above is outside the band
+				up = inf
+			}
+			diag, row[j] = row[j], min(up+1, row[j-1]+1, diag+cost, inf)
+			rowMin = min(rowMin, row[j])
+		}
+		if rowMin > k {
+			return k + 1, false
+		}
+	}
+	d := row[len(rb)]
+	return d, d <= k
+}
+
+// Damerau returns the Damerau-Levenshtein distance of a and b, which
+// also counts a transposition of two adjacent runes as one edit.
+// Unlike the optimal string alignment distance, a substring can be
+// edited after a transposition, thus it is a metric. It takes O(mn)
+// time and memory.
+// Paper: Lowrance, Roy and Wagner, Robert A. (1975). "An extension of
+// the string-to-string correction problem". Journal of the ACM 22 (2):
+// 177–183
+func Damerau(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	m, n := len(ra), len(rb)
+	inf := m + n
+	// d[i+1][j+1] is the distance of ra[:i] and rb[:j], the extra row
+	// and column are sentinels.
+	d := make([][]int, m+2)
+	for i := range d {
+		d[i] = make([]int, n+2)
+	}
+	d[0][0] = inf
+	for i := 0; i <= m; i++ {
+		d[i+1][0], d[i+1][1] = inf, i
+	}
+	for j := 0; j <= n; j++ {
+		d[0][j+1], d[1][j+1] = inf, j
+	}
+	last := map[rune]int{} // the last row where a rune occurs in ra
+	for i := 1; i <= m; i++ {
+		lastCol := 0 // the last column of a match in this row
+		for j := 1; j <= n; j++ {
+			i1, j1 := last[rb[j-1]], lastCol
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost, lastCol = 0, j
+			}
+			d[i+1][j+1] = min(
+				d[i][j]+cost,
+				d[i+1][j]+1,
+				d[i][j+1]+1,
+				d[i1][j1]+(i-i1-1)+1+(j-j1-1), // transposition
+			)
+		}
+		last[ra[i-1]] = i
+	}
+	return d[m+1][n+1]
+}
+
+// Jaro returns the Jaro similarity of a and b in [0, 1], where 1
+// means equal. It counts the runes that match within a window of
+// half the longer length, and the transpositions among them.
+func Jaro(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	window := max(max(len(ra), len(rb))/2-1, 0)
+	ma, mb := make([]bool, len(ra)), make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		for j := max(0, i-window); j < min(len(rb), i+window+1); j++ {
+			if !mb[j] && ra[i] == rb[j] {
+				ma[i], mb[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	half := 0 // the number of half transpositions
+	for i, j := 0, 0; i < len(ra); i++ {
+		if !ma[i] {
+			continue
+		}
+		for !mb[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			half++
+		}
+		j++
+	}
+	m := float64(matches)
+	return (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(half/2))/m) / 3
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of a and b in
+// [0, 1], which boosts the Jaro similarity above 0.7 by the length of
+// the common prefix up to 4 runes, for names that differ at the end.
+// Paper: Winkler, William E. (1990). "String comparator metrics and
+// enhanced decision rules in the Fellegi-Sunter model of record
+// linkage". Proceedings of the Section on Survey Research Methods:
+// 354–359
+func JaroWinkler(a, b string) float64 {
+	j := Jaro(a, b)
+	if j <= 0.7 {
+		return j
+	}
+	ra, rb := []rune(a), []rune(b)
+	l := 0
+	for l < min(4, len(ra), len(rb)) && ra[l] == rb[l] {
+		l++
+	}
+	return j + float64(l)*0.1*(1-j)
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"changkun.de/x/pkg/str"
+)
+
+func TestLevenshtein(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"intention", "execution", 5},
+		{"ca", "abc", 3},
+		{"日本語", "日本", 1},
+	} {
+		if got := str.Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Fatalf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		a, b := randString(r, "abc", r.Intn(12)), randString(r, "abc", r.Intn(12))
+		d := str.Levenshtein(a, b)
+		if d != str.Levenshtein(b, a) {
+			t.Fatalf("Levenshtein(%q, %q) is not symmetric", a, b)
+		}
+		if dd := str.Damerau(a, b); dd > d {
+			t.Fatalf("Damerau(%q, %q) = %d > Levenshtein = %d", a, b, dd, d)
+		}
+		k := r.Intn(8)
+		got, ok := str.LevenshteinBounded(a, b, k)
+		if ok != (d <= k) || (ok && got != d) || (!ok && got != k+1) {
+			t.Fatalf("LevenshteinBounded(%q, %q, %d) = %d, %v, want distance %d", a, b, k, got, ok, d)
+		}
+	}
+}
+
+func TestDamerau(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"ab", "ba", 1},
+		{"ca", "abc", 2},
+		{"abcdef", "abcfde", 2},
+		{"kitten", "sitting", 3},
+		{"a cat", "an act", 2},
+	} {
+		if got := str.Damerau(tt.a, tt.b); got != tt.want {
+			t.Fatalf("Damerau(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	for _, tt := range []struct {
+		a, b          string
+		jaro, winkler float64
+	}{
+		{"", "", 1, 1},
+		{"abc", "", 0, 0},
+		{"abc", "xyz", 0, 0},
+		{"MARTHA", "MARHTA", 0.944444, 0.961111},
+		{"DIXON", "DICKSONX", 0.766667, 0.813333},
+		{"DWAYNE", "DUANE", 0.822222, 0.840000},
+	} {
+		if got := str.Jaro(tt.a, tt.b); math.Abs(got-tt.jaro) > 1e-6 {
+			t.Fatalf("Jaro(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.jaro)
+		}
+		if got := str.JaroWinkler(tt.a, tt.b); math.Abs(got-tt.winkler) > 1e-6 {
+			t.Fatalf("JaroWinkler(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.winkler)
+		}
+	}
+}
+
+func BenchmarkLevenshtein(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	x, y := randString(r, "abcd", 256), randString(r, "abcd", 256)
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			str.Levenshtein(x, y)
+		}
+	})
+	b.Run("bounded", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			str.LevenshteinBounded(x, y, 8)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package str implements string algorithms: single and multi-pattern
+// search, suffix arrays, and edit distances for fuzzy matching.
+//
+// Searches work on bytes, thus indices are byte offsets, and edit
+// distances work on runes.
+package str
+
+// KMP is a preprocessed pattern for Knuth-Morris-Pratt search, which
+// finds the pattern in O(n) time for a text of length n, and never
+// moves backwards in the text.
+// Paper: Knuth, D. E., Morris, J. H. and Pratt, V. R. (1977). "Fast
+// pattern matching in strings". SIAM Journal on Computing 6 (2):
+// 323–350
+type KMP struct {
+	pattern string
+	// fail[i] is the length of the longest proper border of
+	// pattern[:i+1], i.e. the longest proper prefix that is also a
+	// suffix.
+	fail []int
+}
+
+// NewKMP preprocesses the pattern in O(m) time.
+func NewKMP(pattern string) *KMP {
+	fail := make([]int, len(pattern))
+	for i, k := 1, 0; i < len(pattern); i++ {
+		for k > 0 && pattern[i] != pattern[k] {
+			k = fail[k-1]
+		}
+		if pattern[i] == pattern[k] {
+			k++
+		}
+		fail[i] = k
+	}
+	return &KMP{pattern: pattern, fail: fail}
+}
+
+// Index returns the index of the first instance of the pattern in s,
+// or -1 if the pattern is not present in s.
+func (p *KMP) Index(s string) int {
+	i := -1
+	p.each(s, func(j int) bool {
+		i = j
+		return false
+	})
+	return i
+}
+
+// IndexAll returns the indices of all instances of the pattern in s,
+// including overlapping ones.
+func (p *KMP) IndexAll(s string) []int {
+	var is []int
+	p.each(s, func(j int) bool {
+		is = append(is, j)
+		return true
+	})
+	return is
+}
+
+func (p *KMP) each(s string, op func(i int) bool) {
+	m := len(p.pattern)
+	if m == 0 {
+		for i := 0; i <= len(s); i++ {
+			if !op(i) {
+				return
+			}
+		}
+		return
+	}
+	for i, k := 0, 0; i < len(s); i++ {
+		for k > 0 && s[i] != p.pattern[k] {
+			k = p.fail[k-1]
+		}
+		if s[i] == p.pattern[k] {
+			k++
+		}
+		if k == m {
+			if !op(i - m + 1) {
+				return
+			}
+			k = p.fail[k-1]
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str_test
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"changkun.de/x/pkg/str"
+)
+
+type matcher interface {
+	Index(s string) int
+	IndexAll(s string) []int
+}
+
+func randString(r *rand.Rand, alphabet string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// indexAll returns all overlapping instances of pattern in s.
+func indexAll(s, pattern string) []int {
+	var all []int
+	for i := 0; i+len(pattern) <= len(s); i++ {
+		if s[i:i+len(pattern)] == pattern {
+			all = append(all, i)
+		}
+	}
+	return all
+}
+
+func testMatcher(t *testing.T, newMatcher func(pattern string) matcher) {
+	for _, tt := range []struct {
+		s, pattern string
+		want       []int
+	}{
+		{"", "", []int{0}},
+		{"abc", "", []int{0, 1, 2, 3}},
+		{"", "a", nil},
+		{"aaaa", "aa", []int{0, 1, 2}},
+		{"abababab", "abab", []int{0, 2, 4}},
+		{"hello, world", "world", []int{7}},
+		{"hello, world", "word", nil},
+		{"你好世界你好", "你好", []int{0, 12}},
+	} {
+		m := newMatcher(tt.pattern)
+		if got := m.IndexAll(tt.s); !slices.Equal(got, tt.want) {
+			t.Fatalf("IndexAll(%q, %q) = %v, want %v", tt.s, tt.pattern, got, tt.want)
+		}
+		if got, want := m.Index(tt.s), strings.Index(tt.s, tt.pattern); got != want {
+			t.Fatalf("Index(%q, %q) = %d, want %d", tt.s, tt.pattern, got, want)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		alphabet := "ab"
+		if i%2 == 1 {
+			alphabet = "abcd"
+		}
+		s := randString(r, alphabet, r.Intn(64))
+		pattern := randString(r, alphabet, 1+r.Intn(6))
+		m := newMatcher(pattern)
+		if got, want := m.Index(s), strings.Index(s, pattern); got != want {
+			t.Fatalf("Index(%q, %q) = %d, want %d", s, pattern, got, want)
+		}
+		if got, want := m.IndexAll(s), indexAll(s, pattern); !slices.Equal(got, want) {
+			t.Fatalf("IndexAll(%q, %q) = %v, want %v", s, pattern, got, want)
+		}
+	}
+}
+
+func TestKMP(t *testing.T) {
+	testMatcher(t, func(pattern string) matcher { return str.NewKMP(pattern) })
+}
+
+func TestBoyerMoore(t *testing.T) {
+	testMatcher(t, func(pattern string) matcher { return str.NewBoyerMoore(pattern) })
+}
+
+func benchmarkMatcher(b *testing.B, newMatcher func(pattern string) matcher) {
+	r := rand.New(rand.NewSource(1))
+	for _, tt := range []struct {
+		name     string
+		alphabet string
+	}{
+		{"binary", "ab"},
+		{"text", "abcdefghijklmnopqrstuvwxyz "},
+	} {
+		s := randString(r, tt.alphabet, 1<<16)
+		pattern := randString(r, tt.alphabet, 32)
+		m := newMatcher(pattern)
+		b.Run(tt.name, func(b *testing.B) {
+			b.SetBytes(int64(len(s)))
+			for i := 0; i < b.N; i++ {
+				m.IndexAll(s)
+			}
+		})
+	}
+}
+
+func BenchmarkKMP(b *testing.B) {
+	benchmarkMatcher(b, func(pattern string) matcher { return str.NewKMP(pattern) })
+}
+
+func BenchmarkBoyerMoore(b *testing.B) {
+	benchmarkMatcher(b, func(pattern string) matcher { return str.NewBoyerMoore(pattern) })
+}
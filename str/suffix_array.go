@@ -0,0 +1,102 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str
+
+// SuffixArray returns the suffix array of s, i.e. the start indices
+// of all suffixes of s in lexical order. It sorts suffixes by their
+// first 2^k bytes for increasing k with radix sort, which takes
+// O(n log n) time.
+// Paper: Manber, Udi and Myers, Gene (1993). "Suffix arrays: a new
+// method for on-line string searches". SIAM Journal on Computing
+// 22 (5): 935–948
+func SuffixArray(s string) []int {
+	n := len(s)
+	sa := make([]int, n)
+	if n == 0 {
+		return sa
+	}
+	rank, tmp := make([]int, n), make([]int, n)
+	for i := range sa {
+		sa[i], rank[i] = i, int(s[i])
+	}
+	count := make([]int, max(256, n)+1)
+
+	// second returns the rank of the second half of suffix i, where
+	// an empty half goes first.
+	var k int
+	second := func(i int) int {
+		if i+k < n {
+			return rank[i+k] + 1
+		}
+		return 0
+	}
+	// countingSort sorts sa stably by key into tmp, and swaps them.
+	countingSort := func(key func(i int) int) {
+		clear(count)
+		for _, i := range sa {
+			count[key(i)]++
+		}
+		for r, sum := 0, 0; r < len(count); r++ {
+			count[r], sum = sum, sum+count[r]
+		}
+		for _, i := range sa {
+			tmp[count[key(i)]] = i
+			count[key(i)]++
+		}
+		sa, tmp = tmp, sa
+	}
+
+	for k = 1; ; k <<= 1 {
+		countingSort(second)
+		countingSort(func(i int) int { return rank[i] })
+		// re-rank by pairs of halves.
+		tmp[sa[0]] = 0
+		for j := 1; j < n; j++ {
+			a, b := sa[j-1], sa[j]
+			tmp[b] = tmp[a]
+			if rank[a] != rank[b] || second(a) != second(b) {
+				tmp[b]++
+			}
+		}
+		rank, tmp = tmp, rank
+		if rank[sa[n-1]] == n-1 || k >= n {
+			return sa
+		}
+	}
+}
+
+// LCPArray returns the longest common prefix array of s and its
+// suffix array sa, where lcp[i] is the length of the longest common
+// prefix of the suffixes sa[i-1] and sa[i], and lcp[0] is 0. It takes
+// O(n) time.
+// Paper: Kasai, T., Lee, G., Arimura, H., Arikawa, S. and Park, K.
+// (2001). "Linear-time longest-common-prefix computation in suffix
+// arrays and its applications". CPM 2001: 181–192
+func LCPArray(s string, sa []int) []int {
+	n := len(s)
+	rank := make([]int, n)
+	for i, p := range sa {
+		rank[p] = i
+	}
+	lcp := make([]int, n)
+	// the common prefix of the suffix of i+1 and its predecessor is
+	// at least h-1 if h is the one of the suffix of i.
+	h := 0
+	for i := 0; i < n; i++ {
+		if rank[i] == 0 {
+			h = 0
+			continue
+		}
+		j := sa[rank[i]-1]
+		for i+h < n && j+h < n && s[i+h] == s[j+h] {
+			h++
+		}
+		lcp[rank[i]] = h
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
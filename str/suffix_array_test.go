@@ -0,0 +1,76 @@
+// Copyright 2019 Changkun Ou. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package str_test
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"changkun.de/x/pkg/str"
+)
+
+func naiveSuffixArray(s string) []int {
+	sa := make([]int, len(s))
+	for i := range sa {
+		sa[i] = i
+	}
+	sort.Slice(sa, func(i, j int) bool { return s[sa[i]:] < s[sa[j]:] })
+	return sa
+}
+
+func naiveLCP(s string, sa []int) []int {
+	lcp := make([]int, len(sa))
+	for i := 1; i < len(sa); i++ {
+		a, b := s[sa[i-1]:], s[sa[i]:]
+		for lcp[i] < len(a) && lcp[i] < len(b) && a[lcp[i]] == b[lcp[i]] {
+			lcp[i]++
+		}
+	}
+	return lcp
+}
+
+func TestSuffixArray(t *testing.T) {
+	sa := str.SuffixArray("banana")
+	if want := []int{5, 3, 1, 0, 4, 2}; !slices.Equal(sa, want) {
+		t.Fatalf("SuffixArray(banana) = %v, want %v", sa, want)
+	}
+	if lcp, want := str.LCPArray("banana", sa), []int{0, 1, 3, 0, 0, 2}; !slices.Equal(lcp, want) {
+		t.Fatalf("LCPArray(banana) = %v, want %v", lcp, want)
+	}
+	if sa := str.SuffixArray(""); len(sa) != 0 {
+		t.Fatalf("SuffixArray() = %v, want empty", sa)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		var s string
+		switch i % 3 {
+		case 0:
+			s = randString(r, "ab", r.Intn(100))
+		case 1:
+			s = randString(r, "abcdefgh", r.Intn(100))
+		default:
+			s = strings.Repeat(randString(r, "ab", 1+r.Intn(3)), r.Intn(30))
+		}
+		sa, want := str.SuffixArray(s), naiveSuffixArray(s)
+		if !slices.Equal(sa, want) {
+			t.Fatalf("SuffixArray(%q) = %v, want %v", s, sa, want)
+		}
+		if lcp, want := str.LCPArray(s, sa), naiveLCP(s, sa); !slices.Equal(lcp, want) {
+			t.Fatalf("LCPArray(%q) = %v, want %v", s, lcp, want)
+		}
+	}
+}
+
+func BenchmarkSuffixArray(b *testing.B) {
+	s := randString(rand.New(rand.NewSource(1)), "acgt", 1<<16)
+	b.SetBytes(int64(len(s)))
+	for i := 0; i < b.N; i++ {
+		str.LCPArray(s, str.SuffixArray(s))
+	}
+}